@@ -42,6 +42,9 @@ type BatchSubmitter struct {
 func NewBatchSubmitter(cfg Config, l log.Logger, m metrics.Metricer) (*BatchSubmitter, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	state := NewChannelManager(l, m, cfg.Channel)
+	state.journal = cfg.Journal
+
 	return &BatchSubmitter{
 		Config: cfg,
 		done:   make(chan struct{}),
@@ -50,7 +53,7 @@ func NewBatchSubmitter(cfg Config, l log.Logger, m metrics.Metricer) (*BatchSubm
 		// if the tx manager is blocking forever due to e.g. insufficient balance.
 		ctx:    ctx,
 		cancel: cancel,
-		state:  NewChannelManager(l, m, cfg.Channel),
+		state:  state,
 	}, nil
 
 }
@@ -132,15 +135,38 @@ func (b *BatchSubmitter) calculateL2BlockRangeToStore(ctx context.Context) (eth.
 	return b.lastStoredBlock, syncStatus.UnsafeL2.ID(), nil
 }
 
-func (b *BatchSubmitter) CreateSubmitTx(data []byte) (*types.Transaction, error) {
-	ctx, cancel := context.WithTimeout(b.ctx, networkTimeout)
+// confirmedNonce returns the nonce following the transactions included in the latest L1 block.
+func (b *BatchSubmitter) confirmedNonce(ctx context.Context) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
 	nonce, err := b.L1Client.NonceAt(ctx, b.From, nil)
-	cancel()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
 	}
+	return nonce, nil
+}
 
-	ctx, cancel = context.WithTimeout(b.ctx, networkTimeout)
+// txCalldata returns the calldata of the batcher transaction carrying the given
+// data. Once the external DA fork is active at the L1 time, the data is stored
+// in the DA server and the transaction only carries its commitment.
+func (b *BatchSubmitter) txCalldata(ctx context.Context, data []byte, l1Time uint64) ([]byte, error) {
+	if b.DAClient == nil || !b.Rollup.IsExternalDAFork(l1Time) {
+		return data, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+	comm, err := b.DAClient.SetInput(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store data in DA server: %w", err)
+	}
+	b.log.Debug("Stored data in DA server", "commitment", comm, "size", len(data))
+	return comm.TxData(), nil
+}
+
+// CreateSubmitTx creates a batch submit transaction with the given nonce. The nonce is
+// assigned by the caller, so that multiple transactions can be in flight at once.
+func (b *BatchSubmitter) CreateSubmitTx(data []byte, nonce uint64) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(b.ctx, networkTimeout)
 	gasTipCap, gasFeeCap, err := utils.CalcGasTipAndFeeCap(ctx, b.L1Client)
 	cancel()
 	if err != nil {
@@ -183,18 +209,43 @@ func (b *BatchSubmitter) recordFailedTx(id txID, err error) {
 
 func (b *BatchSubmitter) recordConfirmedTx(id txID, receipt *types.Receipt) {
 	b.log.Info("Transaction confirmed", "tx_hash", receipt.TxHash, "status", receipt.Status, "block_hash", receipt.BlockHash, "block_number", receipt.BlockNumber)
+	b.recordBaseFeePaid(receipt)
 	l1block := eth.BlockID{Number: receipt.BlockNumber.Uint64(), Hash: receipt.BlockHash}
 	b.state.TxConfirmed(id, l1block)
 }
 
+// recordBaseFeePaid records the L1 base fee paid by the confirmed transaction,
+// which is comparable to the base fees avoided by the fee scheduler.
+func (b *BatchSubmitter) recordBaseFeePaid(receipt *types.Receipt) {
+	ctx, cancel := context.WithTimeout(b.ctx, networkTimeout)
+	defer cancel()
+	header, err := b.L1Client.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		b.log.Warn("Failed to get inclusion block of transaction", "tx_hash", receipt.TxHash, "err", err)
+		return
+	}
+	if header.BaseFee != nil {
+		b.metr.RecordL1BaseFeePaid(new(big.Int).Mul(header.BaseFee, new(big.Int).SetUint64(receipt.GasUsed)))
+	}
+}
+
 // l1Tip gets the current L1 tip as a L1BlockRef. The passed context is assumed
 // to be a lifetime context, so it is internally wrapped with a network timeout.
 func (b *BatchSubmitter) l1Tip(ctx context.Context) (eth.L1BlockRef, error) {
+	head, err := b.l1Head(ctx)
+	if err != nil {
+		return eth.L1BlockRef{}, err
+	}
+	return eth.InfoToL1BlockRef(eth.HeaderBlockInfo(head)), nil
+}
+
+// l1Head gets the header of the current L1 tip, like l1Tip.
+func (b *BatchSubmitter) l1Head(ctx context.Context) (*types.Header, error) {
 	tctx, cancel := context.WithTimeout(ctx, networkTimeout)
 	defer cancel()
 	head, err := b.L1Client.HeaderByNumber(tctx, nil)
 	if err != nil {
-		return eth.L1BlockRef{}, fmt.Errorf("getting latest L1 block: %w", err)
+		return nil, fmt.Errorf("getting latest L1 block: %w", err)
 	}
-	return eth.InfoToL1BlockRef(eth.HeaderBlockInfo(head)), nil
+	return head, nil
 }
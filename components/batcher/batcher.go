@@ -2,6 +2,7 @@ package batcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
@@ -9,9 +10,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli"
 
 	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	brpc "github.com/wemixkanvas/kanvas/components/batcher/rpc"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 	"github.com/wemixkanvas/kanvas/utils"
 	"github.com/wemixkanvas/kanvas/utils/monitoring"
 	klog "github.com/wemixkanvas/kanvas/utils/service/log"
@@ -40,7 +45,20 @@ func Main(version string, cliCtx *cli.Context) error {
 
 	monitoring.MaybeStartPprof(ctx, cliCfg.PprofConfig, l)
 	monitoring.MaybeStartMetrics(ctx, cliCfg.MetricsConfig, l, batcherCfg.L1Client, batcherCfg.From)
-	server, err := monitoring.StartRPC(cliCfg.RPCConfig.ToServiceCLIConfig(), version, krpc.WithLogger(l))
+
+	batcher, err := NewBatcher(ctx, *batcherCfg, l, m)
+	if err != nil {
+		return err
+	}
+
+	var apis []rpc.API
+	if cliCfg.RPCConfig.EnableAdmin {
+		apis = append(apis, rpc.API{
+			Namespace: brpc.NamespaceAdmin,
+			Service:   brpc.NewAdminAPI(batcher),
+		})
+	}
+	server, err := monitoring.StartRPC(cliCfg.RPCConfig.ToServiceCLIConfig(), version, krpc.WithLogger(l), krpc.WithAPIs(apis))
 	if err != nil {
 		return err
 	}
@@ -49,28 +67,55 @@ func Main(version string, cliCtx *cli.Context) error {
 	m.RecordInfo(version)
 	m.RecordUp()
 
-	batcher, err := NewBatcher(ctx, *batcherCfg, l, m)
-	if err != nil {
+	if err := batcher.Start(); err != nil {
 		return err
 	}
-
-	batcher.Start()
 	<-utils.WaitInterrupt()
-	batcher.Stop()
+	if err := batcher.Stop(); err != nil {
+		l.Warn("failed to stop batcher", "err", err)
+	}
+	if err := batcherCfg.Journal.Close(); err != nil {
+		l.Error("failed to close journal", "err", err)
+	}
 
 	return nil
 }
 
 type Batcher struct {
-	ctx    context.Context
-	cancel context.CancelFunc
+	// parentCtx is canceled when the batcher is closed for good.
+	parentCtx context.Context
+
+	// shutdownCtx is canceled to stop the submission loop gracefully, finishing the in-flight transaction.
+	shutdownCtx       context.Context
+	cancelShutdownCtx context.CancelFunc
+	// killCtx is canceled to abort the in-flight transaction.
+	killCtx       context.Context
+	cancelKillCtx context.CancelFunc
 
 	cfg            Config
 	l              log.Logger
 	batchSubmitter *BatchSubmitter
 	txMgr          txmgr.TxManager
 
-	wg sync.WaitGroup
+	// mu serializes starting and stopping the batcher, which are also done by the admin API.
+	mu      sync.Mutex
+	running bool
+	wg      sync.WaitGroup
+
+	// scheduler holds back the frames which are not urgent while the L1 base fee is high.
+	scheduler *feeScheduler
+
+	// pendingTxs limits the number of frame transactions in flight. It's nil if not limited.
+	pendingTxs chan struct{}
+	// inFlight tracks the frame transactions being sent.
+	inFlight sync.WaitGroup
+	// nonce is the nonce of the next frame transaction. It's nil if it has to be fetched from L1,
+	// which is the case on start and after a transaction failed.
+	nonce *uint64
+	// nonceGen is incremented each time the nonce is fetched, so that a failed transaction
+	// only resets the nonce it was assigned from.
+	nonceGen uint64
+	nonceMu  sync.Mutex
 }
 
 func NewBatcher(parentCtx context.Context, cfg Config, l log.Logger, m metrics.Metricer) (*Batcher, error) {
@@ -79,45 +124,100 @@ func NewBatcher(parentCtx context.Context, cfg Config, l log.Logger, m metrics.M
 		return nil, err
 	}
 
-	ctx, cancel := context.WithCancel(parentCtx)
-
 	batchSubmitter, err := NewBatchSubmitter(cfg, l, m)
 	if err != nil {
-		cancel()
 		return nil, fmt.Errorf("failed to init batch submitter: %w", err)
 	}
 
-	balance, err := cfg.L1Client.BalanceAt(ctx, cfg.From, nil)
+	balance, err := cfg.L1Client.BalanceAt(parentCtx, cfg.From, nil)
 	if err != nil {
-		cancel()
 		return nil, err
 	}
 
-	l.Info("creating batcher", "batcher_addr", cfg.From, "batcher_bal", balance)
+	if err := batchSubmitter.restoreState(parentCtx); err != nil {
+		return nil, fmt.Errorf("failed to restore batcher state from journal: %w", err)
+	}
+
+	l.Info("creating batcher", "batcher_addr", cfg.From, "batcher_bal", balance, "max_pending_txs", cfg.MaxPendingTxs, "max_l1_base_fee", cfg.MaxL1BaseFee)
+
+	var pendingTxs chan struct{}
+	if cfg.MaxPendingTxs > 0 {
+		pendingTxs = make(chan struct{}, cfg.MaxPendingTxs)
+	}
 
 	return &Batcher{
-		ctx:            ctx,
-		cancel:         cancel,
+		parentCtx:      parentCtx,
 		cfg:            cfg,
 		l:              l,
 		batchSubmitter: batchSubmitter,
+		scheduler:      newFeeScheduler(l, m, cfg.MaxL1BaseFee),
 		txMgr:          txmgr.NewSimpleTxManager("batcher", l, cfg.TxManagerConfig, cfg.L1Client),
+		pendingTxs:     pendingTxs,
 	}, nil
 }
 
-func (b *Batcher) Start() {
+// Start starts the submission loop. It can be called again after Stop to restart the batcher.
+func (b *Batcher) Start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return errors.New("batcher is already running")
+	}
 	b.l.Info("starting Batch Submitter")
+
+	b.shutdownCtx, b.cancelShutdownCtx = context.WithCancel(context.Background())
+	b.killCtx, b.cancelKillCtx = context.WithCancel(b.parentCtx)
+	b.running = true
+	b.nonceMu.Lock()
+	b.nonce = nil
+	b.nonceMu.Unlock()
+
 	b.wg.Add(1)
 	go b.loop()
+	return nil
 }
 
-func (b *Batcher) Stop() {
-	b.cancel()
+// Stop stops the submission loop after the in-flight transactions are confirmed or failed,
+// so that the channel state stays consistent for a restart.
+func (b *Batcher) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.running {
+		return errors.New("batcher is not running")
+	}
+	b.l.Info("stopping Batch Submitter")
+
+	b.cancelShutdownCtx()
 	b.wg.Wait()
+	b.cancelKillCtx()
+	b.running = false
+
+	b.l.Info("Batch Submitter stopped")
+	return nil
+}
+
+// PendingState returns the state of the blocks and the channels which are not fully submitted yet.
+func (b *Batcher) PendingState() *brpc.PendingState {
+	return b.batchSubmitter.state.PendingState()
+}
+
+// ForceCloseChannel closes the current channel, so that its remaining frames are submitted
+// without waiting for more blocks.
+func (b *Batcher) ForceCloseChannel(ctx context.Context) (derive.ChannelID, error) {
+	l1tip, err := b.batchSubmitter.l1Tip(ctx)
+	if err != nil {
+		return derive.ChannelID{}, fmt.Errorf("failed to query L1 tip: %w", err)
+	}
+	return b.batchSubmitter.state.ForceCloseChannel(l1tip)
 }
 
 func (b *Batcher) loop() {
 	defer b.wg.Done()
+	// Wait for the in-flight transactions before returning, so that their frames are
+	// either confirmed or re-queued.
+	defer b.inFlight.Wait()
 
 	ticker := time.NewTicker(b.cfg.PollInterval)
 	defer ticker.Stop()
@@ -128,7 +228,9 @@ func (b *Batcher) loop() {
 			if err := b.submitBatch(); err != nil {
 				b.l.Error("failed to submit batch channel frame", "err", err)
 			}
-		case <-b.ctx.Done():
+		case <-b.shutdownCtx.Done():
+			return
+		case <-b.killCtx.Done():
 			return
 		}
 	}
@@ -146,55 +248,146 @@ func (b *Batcher) loop() {
 // Missed L2 block somehow.
 
 func (b *Batcher) submitBatch() error {
-	b.batchSubmitter.LoadBlocksIntoState(b.ctx)
+	b.batchSubmitter.LoadBlocksIntoState(b.killCtx)
 
-blockLoop:
-	for {
-		l1tip, err := b.batchSubmitter.l1Tip(b.ctx)
+	for b.acquireTxSlot() {
+		l1head, err := b.batchSubmitter.l1Head(b.killCtx)
 		if err != nil {
+			b.releaseTxSlot()
 			b.l.Error("Failed to query L1 tip", "error", err)
 			break
 		}
+		l1tip := eth.InfoToL1BlockRef(eth.HeaderBlockInfo(l1head))
 		b.batchSubmitter.recordL1Tip(l1tip)
 
 		// Collect next transaction data
-		txdata, err := b.batchSubmitter.state.TxData(l1tip.ID())
+		txdata, err := b.scheduler.TxData(b.batchSubmitter.state, l1tip, l1head.BaseFee)
 		if err == io.EOF {
+			b.releaseTxSlot()
 			b.l.Trace("no transaction data available")
 			break
 		} else if err != nil {
+			b.releaseTxSlot()
 			b.l.Error("unable to get tx data", "err", err)
 			break
 		}
 
-		tx, err := b.batchSubmitter.CreateSubmitTx(txdata.Bytes())
+		calldata, err := b.batchSubmitter.txCalldata(b.killCtx, txdata.Bytes(), l1tip.Time)
 		if err != nil {
-			// record it as a failed TX to resubmit the transaction.
+			b.releaseTxSlot()
 			b.batchSubmitter.recordFailedTx(txdata.ID(), err)
-			return fmt.Errorf("failed to create batch submit transaction: %w", err)
+			return err
 		}
-		b.l.Info("creating batch submit tx", "to", tx.To, "from", b.cfg.From)
-		// Record TX Status
-		receipt, err := b.SendTransaction(b.ctx, tx)
+		nonce, gen, err := b.nextNonce(b.killCtx)
 		if err != nil {
+			b.releaseTxSlot()
 			b.batchSubmitter.recordFailedTx(txdata.ID(), err)
-			return fmt.Errorf("failed to send batch transaction: %w", err)
+			return err
 		}
-		b.batchSubmitter.recordConfirmedTx(txdata.ID(), receipt)
-
-		// hack to exit this loop. Proper fix is to do request another send tx or parallel tx sending
-		// from the channel manager rather than sending the channel in a loop. This stalls b/c if the
-		// context is cancelled while sending, it will never fully clearing the pending txns.
-		select {
-		case <-b.ctx.Done():
-			break blockLoop
-		default:
+		tx, err := b.batchSubmitter.CreateSubmitTx(calldata, nonce)
+		if err != nil {
+			b.releaseTxSlot()
+			// the nonce is not used, so the following transactions must not skip it.
+			b.resetNonce(gen)
+			// record it as a failed TX to resubmit the transaction.
+			b.batchSubmitter.recordFailedTx(txdata.ID(), err)
+			return fmt.Errorf("failed to create batch submit transaction: %w", err)
 		}
+		b.l.Info("creating batch submit tx", "to", tx.To(), "from", b.cfg.From, "nonce", nonce)
+		b.scheduler.TxCreated(tx, l1head.BaseFee)
+		b.batchSubmitter.state.TxSent(txdata.ID(), tx.Hash())
+
+		b.inFlight.Add(1)
+		go b.sendTxData(txdata, tx, gen)
 	}
 
 	return nil
 }
 
+// sendTxData sends the frame transaction and records its result in the channel manager.
+// If it failed, its frame is re-queued and the nonce of the given generation is fetched again
+// for the next transaction.
+func (b *Batcher) sendTxData(txdata txData, tx *types.Transaction, nonceGen uint64) {
+	defer b.inFlight.Done()
+	defer b.releaseTxSlot()
+
+	// The transaction is sent with the kill context, so that it's confirmed or failed on
+	// a graceful stop instead of being left in the pending txns.
+	receipt, err := b.SendTransaction(b.killCtx, tx)
+	if err != nil {
+		b.resetNonce(nonceGen)
+		b.batchSubmitter.recordFailedTx(txdata.ID(), err)
+		return
+	}
+	b.batchSubmitter.recordConfirmedTx(txdata.ID(), receipt)
+}
+
+// acquireTxSlot blocks until another frame transaction can be in flight. It returns false
+// if the batcher is stopped in the meantime.
+func (b *Batcher) acquireTxSlot() bool {
+	select {
+	case <-b.shutdownCtx.Done():
+		return false
+	case <-b.killCtx.Done():
+		return false
+	default:
+	}
+	if b.pendingTxs == nil {
+		return true
+	}
+	select {
+	case b.pendingTxs <- struct{}{}:
+		return true
+	case <-b.shutdownCtx.Done():
+		return false
+	case <-b.killCtx.Done():
+		return false
+	}
+}
+
+func (b *Batcher) releaseTxSlot() {
+	if b.pendingTxs != nil {
+		<-b.pendingTxs
+	}
+}
+
+// nextNonce returns the nonce for the next frame transaction and its generation. If the nonce
+// has to be fetched from L1, it first waits for the in-flight transactions, as the ones following
+// a failed transaction may be stuck until they time out as well.
+func (b *Batcher) nextNonce(ctx context.Context) (uint64, uint64, error) {
+	b.nonceMu.Lock()
+	defer b.nonceMu.Unlock()
+
+	if b.nonce == nil {
+		// The lock is released while waiting, as the in-flight transactions reset the nonce
+		// if they fail. Only this goroutine sends transactions, so none is in flight after
+		// the wait and the nonce can't be reset before it's assigned.
+		b.nonceMu.Unlock()
+		b.inFlight.Wait()
+		nonce, err := b.batchSubmitter.confirmedNonce(ctx)
+		b.nonceMu.Lock()
+		if err != nil {
+			return 0, 0, err
+		}
+		b.nonce = &nonce
+		b.nonceGen++
+	}
+
+	nonce := *b.nonce
+	*b.nonce++
+	return nonce, b.nonceGen, nil
+}
+
+// resetNonce makes the next frame transaction fetch the nonce from L1, unless it was
+// fetched again since the given generation was assigned.
+func (b *Batcher) resetNonce(gen uint64) {
+	b.nonceMu.Lock()
+	defer b.nonceMu.Unlock()
+	if gen == b.nonceGen {
+		b.nonce = nil
+	}
+}
+
 // SendTransaction sends a transaction through the transaction manager which handles automatic
 // price bumping, and returns transaction receipt.
 // It also hardcodes a timeout of 100s.
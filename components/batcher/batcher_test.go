@@ -0,0 +1,256 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	"github.com/wemixkanvas/kanvas/components/node/client"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
+	derivetest "github.com/wemixkanvas/kanvas/components/node/rollup/derive/test"
+	"github.com/wemixkanvas/kanvas/components/node/sources"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/utils/service/txmgr"
+)
+
+// testL1 serves the L1 RPC methods used to create the frame transactions.
+type testL1 struct {
+	mu    sync.Mutex
+	nonce uint64
+}
+
+func (l *testL1) setNonce(nonce uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nonce = nonce
+}
+
+func (l *testL1) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return hexutil.Uint64(l.nonce)
+}
+
+func (l *testL1) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (l *testL1) GetBlockByNumber(number string, fullTx bool) *types.Header {
+	return &types.Header{Number: big.NewInt(100), Difficulty: common.Big0, BaseFee: big.NewInt(10)}
+}
+
+func (l *testL1) GetBlockByHash(hash common.Hash, fullTx bool) *types.Header {
+	return l.GetBlockByNumber("", fullTx)
+}
+
+type testSend struct {
+	tx     *types.Transaction
+	result chan error
+}
+
+// testTxMgr hands the sent transactions to the test, which decides their result.
+type testTxMgr struct {
+	sent chan *testSend
+}
+
+func (m *testTxMgr) Send(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	s := &testSend{tx: tx, result: make(chan error, 1)}
+	m.sent <- s
+	select {
+	case err := <-s.result:
+		if err != nil {
+			return nil, err
+		}
+		return &types.Receipt{
+			TxHash:      tx.Hash(),
+			Status:      types.ReceiptStatusSuccessful,
+			BlockHash:   common.Hash{0x01},
+			BlockNumber: big.NewInt(100),
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newTestBatcher creates a batcher whose channel manager holds a closed channel
+// with multiple frames to submit.
+func newTestBatcher(t *testing.T, maxPendingTxs uint64) (*Batcher, *testL1, *testTxMgr) {
+	l1 := &testL1{}
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("eth", l1))
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	log := testlog.Logger(t, log.LvlCrit)
+	cfg := Config{
+		log:           log,
+		metr:          metrics.NoopMetrics,
+		L1Client:      ethclient.NewClient(rpcClient),
+		RollupClient:  sources.NewRollupClient(client.NewBaseRPCClient(rpcClient)),
+		PollInterval:  time.Hour,
+		MaxPendingTxs: maxPendingTxs,
+		TxManagerConfig: txmgr.Config{
+			Signer: func(ctx context.Context, addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			},
+		},
+		Rollup: &rollup.Config{L1ChainID: big.NewInt(900), BatchInboxAddress: common.Address{0xff}},
+		Channel: ChannelConfig{
+			ChannelTimeout:   40,
+			MaxFrameSize:     1_000,
+			TargetFrameSize:  1_000,
+			TargetNumFrames:  100,
+			ApproxComprRatio: 1.0,
+		},
+	}
+	bs, err := NewBatchSubmitter(cfg, log, metrics.NoopMetrics)
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1234))
+	parent := common.Hash{}
+	for i := 0; i < 3; i++ {
+		block, _ := derivetest.RandomL2Block(rng, 10)
+		block = block.WithSeal(&types.Header{Number: big.NewInt(int64(i)), ParentHash: parent, Difficulty: common.Big0})
+		require.NoError(t, bs.state.AddL2Block(block))
+		parent = block.Hash()
+	}
+	_, err = bs.state.ForceCloseChannel(eth.L1BlockRef{Number: 100})
+	require.NoError(t, err)
+
+	var pendingTxs chan struct{}
+	if maxPendingTxs > 0 {
+		pendingTxs = make(chan struct{}, maxPendingTxs)
+	}
+	txMgr := &testTxMgr{sent: make(chan *testSend, 100)}
+	b := &Batcher{
+		parentCtx:      context.Background(),
+		cfg:            cfg,
+		l:              log,
+		batchSubmitter: bs,
+		scheduler:      newFeeScheduler(log, metrics.NoopMetrics, nil),
+		txMgr:          txMgr,
+		pendingTxs:     pendingTxs,
+	}
+	b.shutdownCtx, b.cancelShutdownCtx = context.WithCancel(context.Background())
+	b.killCtx, b.cancelKillCtx = context.WithCancel(context.Background())
+	t.Cleanup(b.cancelShutdownCtx)
+	t.Cleanup(b.cancelKillCtx)
+	return b, l1, txMgr
+}
+
+func submitBatchAsync(b *Batcher) chan error {
+	done := make(chan error, 1)
+	go func() { done <- b.submitBatch() }()
+	return done
+}
+
+func nextSend(t *testing.T, txMgr *testTxMgr) *testSend {
+	select {
+	case s := <-txMgr.sent:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a frame transaction")
+		return nil
+	}
+}
+
+// TestBatcherMaxPendingTxs checks that no more than the maximum number of frame
+// transactions are in flight, and that they are assigned sequential nonces.
+func TestBatcherMaxPendingTxs(t *testing.T) {
+	require := require.New(t)
+	b, l1, txMgr := newTestBatcher(t, 2)
+	l1.setNonce(5)
+	numFrames := b.batchSubmitter.state.currentChannel.builder.NumFrames()
+	require.Greater(numFrames, 3)
+
+	done := submitBatchAsync(b)
+	first := nextSend(t, txMgr)
+	second := nextSend(t, txMgr)
+	require.Equal(uint64(5), first.tx.Nonce())
+	require.Equal(uint64(6), second.tx.Nonce())
+	require.Never(func() bool { return len(txMgr.sent) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	// A confirmed transaction frees a slot for the next one
+	first.result <- nil
+	third := nextSend(t, txMgr)
+	require.Equal(uint64(7), third.tx.Nonce())
+	second.result <- nil
+	third.result <- nil
+
+	for i := 3; i < numFrames; i++ {
+		s := nextSend(t, txMgr)
+		require.Equal(uint64(5+i), s.tx.Nonce())
+		s.result <- nil
+	}
+	require.NoError(<-done)
+	b.inFlight.Wait()
+	require.False(b.batchSubmitter.state.HasFrame())
+	require.Empty(b.batchSubmitter.state.PendingState().Channels)
+}
+
+// TestBatcherTxFailedMidPipeline checks that the frames of the transactions which
+// failed in the middle of the pipeline are re-queued and resubmitted with the nonce
+// fetched again from L1.
+func TestBatcherTxFailedMidPipeline(t *testing.T) {
+	require := require.New(t)
+	b, l1, txMgr := newTestBatcher(t, 0)
+	l1.setNonce(5)
+	numFrames := b.batchSubmitter.state.currentChannel.builder.NumFrames()
+	require.Greater(numFrames, 3)
+
+	require.NoError(<-submitBatchAsync(b))
+	var sends []*testSend
+	for i := 0; i < numFrames; i++ {
+		s := nextSend(t, txMgr)
+		require.Equal(uint64(5+i), s.tx.Nonce())
+		sends = append(sends, s)
+	}
+	_, gen, err := b.nextNonce(context.Background())
+	require.NoError(err)
+
+	// The first transaction is confirmed, the second one fails and the following
+	// ones are stuck behind it until they fail as well.
+	sends[0].result <- nil
+	var failed [][]byte
+	for _, s := range sends[1:] {
+		s.result <- errors.New("failed")
+		failed = append(failed, s.tx.Data())
+	}
+	b.inFlight.Wait()
+	require.True(b.batchSubmitter.state.HasFrame())
+	require.Len(b.batchSubmitter.state.PendingState().Channels, 1)
+
+	l1.setNonce(6)
+	require.NoError(<-submitBatchAsync(b))
+	var resent [][]byte
+	for i := 0; i < len(failed); i++ {
+		s := nextSend(t, txMgr)
+		require.Equal(uint64(6+i), s.tx.Nonce())
+		resent = append(resent, s.tx.Data())
+		s.result <- nil
+	}
+	require.ElementsMatch(failed, resent)
+	b.inFlight.Wait()
+	require.Empty(b.batchSubmitter.state.PendingState().Channels)
+
+	// A late failure of a transaction with a stale nonce doesn't reset the re-fetched nonce
+	b.resetNonce(gen)
+	nonce, _, err := b.nextNonce(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(6+len(failed)), nonce)
+}
@@ -0,0 +1,163 @@
+package batcher
+
+import (
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+// channel wraps a channelBuilder and tracks the transactions of its frames, so
+// that the timeout and the submission of each channel are handled independently.
+type channel struct {
+	log  log.Logger
+	metr metrics.Metricer
+	cfg  ChannelConfig
+
+	// channel builder of this channel
+	builder *channelBuilder
+	// Set of unconfirmed txID -> frame data. For tx resubmission
+	pendingTransactions map[txID]txData
+	// Set of confirmed txID -> inclusion block. For determining if the channel is timed out
+	confirmedTransactions map[txID]eth.BlockID
+	// Set of sent txID -> tx hash. For reconciling the journal with L1 after a restart
+	txHashes map[txID]common.Hash
+	// whether the channel is written to the journal, which is done once it's closed
+	journaled bool
+}
+
+func newChannel(log log.Logger, metr metrics.Metricer, cfg ChannelConfig, l1Time uint64) (*channel, error) {
+	cb, err := newChannelBuilder(cfg, l1Time)
+	if err != nil {
+		return nil, err
+	}
+	return &channel{
+		log:                   log,
+		metr:                  metr,
+		cfg:                   cfg,
+		builder:               cb,
+		pendingTransactions:   make(map[txID]txData),
+		confirmedTransactions: make(map[txID]eth.BlockID),
+		txHashes:              make(map[txID]common.Hash),
+	}, nil
+}
+
+// restoreChannel creates a closed channel from the journal, with the frames left
+// to be submitted and the inclusion blocks of the confirmed frames.
+func restoreChannel(log log.Logger, metr metrics.Metricer, cfg ChannelConfig, id derive.ChannelID, blocks []*types.Block, frames []frameData, confirmed map[txID]eth.BlockID) (*channel, error) {
+	cb, err := restoreChannelBuilder(cfg, id, blocks, frames)
+	if err != nil {
+		return nil, err
+	}
+	return &channel{
+		log:                   log,
+		metr:                  metr,
+		cfg:                   cfg,
+		builder:               cb,
+		pendingTransactions:   make(map[txID]txData),
+		confirmedTransactions: confirmed,
+		txHashes:              make(map[txID]common.Hash),
+		journaled:             true,
+	}, nil
+}
+
+func (c *channel) ID() derive.ChannelID {
+	return c.builder.ID()
+}
+
+// TxFailed re-queues the frame of the failed transaction.
+func (c *channel) TxFailed(id txID) {
+	if data, ok := c.pendingTransactions[id]; ok {
+		c.log.Trace("marked transaction as failed", "id", id)
+		// Note: when the batcher is changed to send multiple frames per tx,
+		// this needs to be changed to iterate over all frames of the tx data
+		// and re-queue them.
+		c.builder.PushFrame(data.Frame())
+		delete(c.pendingTransactions, id)
+		delete(c.txHashes, id)
+	} else {
+		c.log.Warn("unknown transaction marked as failed", "id", id)
+	}
+}
+
+// TxConfirmed marks the transaction as confirmed at the given inclusion block.
+// It returns whether the channel timed out with this inclusion, in which case
+// its blocks must be submitted again in another channel.
+func (c *channel) TxConfirmed(id txID, inclusionBlock eth.BlockID) bool {
+	if _, ok := c.pendingTransactions[id]; !ok {
+		c.log.Warn("unknown transaction marked as confirmed", "id", id, "block", inclusionBlock)
+		return false
+	}
+	delete(c.pendingTransactions, id)
+	c.confirmedTransactions[id] = inclusionBlock
+	c.builder.FramePublished(inclusionBlock.Number)
+
+	return c.isTimedOut()
+}
+
+// TxSent records the hash of the transaction sent for the frame.
+func (c *channel) TxSent(id txID, txHash common.Hash) {
+	c.txHashes[id] = txHash
+}
+
+// isTimedOut returns true if the submitted channel has timed out.
+// A channel has timed out if the difference in L1 Inclusion blocks between
+// the first & last included block is greater than or equal to the channel timeout.
+func (c *channel) isTimedOut() bool {
+	// No confirmed transactions => not timed out
+	if len(c.confirmedTransactions) == 0 {
+		return false
+	}
+	// If there are confirmed transactions, find the first + last confirmed block numbers
+	min := uint64(math.MaxUint64)
+	max := uint64(0)
+	for _, inclusionBlock := range c.confirmedTransactions {
+		if inclusionBlock.Number < min {
+			min = inclusionBlock.Number
+		}
+		if inclusionBlock.Number > max {
+			max = inclusionBlock.Number
+		}
+	}
+	return max-min >= c.cfg.ChannelTimeout
+}
+
+// isUrgent returns whether the frames of the channel must be submitted at the
+// given L1 block regardless of the L1 fees. This is the case once the channel
+// is partially submitted, as its frames are only valid together, or once the
+// end of the proposing window or the channel timeout gets close.
+func (c *channel) isUrgent(l1BlockNum uint64) bool {
+	return len(c.pendingTransactions) > 0 || len(c.confirmedTransactions) > 0 || c.builder.PastDeadline(l1BlockNum)
+}
+
+// isFullySubmitted returns true if the channel has been fully submitted.
+func (c *channel) isFullySubmitted() bool {
+	return c.builder.IsFull() && len(c.pendingTransactions)+c.builder.NumFrames() == 0
+}
+
+// HasFrame returns whether there's a frame ready to be submitted.
+func (c *channel) HasFrame() bool {
+	return c.builder.HasFrame()
+}
+
+// NextTxData pops the next frame off the channel builder and tracks it as
+// pending. HasFrame must be called prior to check if there's a next frame.
+func (c *channel) NextTxData() txData {
+	frame := c.builder.NextFrame()
+	txdata := txData{frame}
+	id := txdata.ID()
+
+	c.log.Trace("returning next tx data", "id", id)
+	c.pendingTransactions[id] = txdata
+	return txdata
+}
+
+// Blocks returns the blocks added to the channel.
+func (c *channel) Blocks() []*types.Block {
+	return c.builder.Blocks()
+}
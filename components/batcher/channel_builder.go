@@ -16,11 +16,15 @@ var (
 	ErrZeroMaxFrameSize      = errors.New("max frame size cannot be zero")
 	ErrSmallMaxFrameSize     = errors.New("max frame size cannot be less than 23")
 	ErrInvalidChannelTimeout = errors.New("channel timeout is less than the safety margin")
+	ErrZeroBlockTime         = errors.New("block time cannot be zero with span batches")
 	ErrInputTargetReached    = errors.New("target amount of input data reached")
 	ErrMaxFrameIndex         = errors.New("max frame index reached (uint16)")
 	ErrMaxDurationReached    = errors.New("max channel duration reached")
 	ErrChannelTimeoutClose   = errors.New("close to channel timeout")
 	ErrProposerWindowClose   = errors.New("close to proposer window timeout")
+	ErrForceClosed           = errors.New("channel force closed")
+	ErrRestored              = errors.New("channel restored from journal")
+	ErrRequeuedBlocks        = errors.New("blocks of a timed out channel requeued")
 )
 
 type ChannelFullError struct {
@@ -42,6 +46,12 @@ type ChannelConfig struct {
 	// The maximum number of L1 blocks that the inclusion transactions of a
 	// channel's frames can span.
 	ChannelTimeout uint64
+	// The activation time of the versioned channel format. Not active if nil.
+	ComprForkTime *uint64
+	// The activation time of the span batches. Not active if nil.
+	SpanForkTime *uint64
+	// The L2 block time, by which the blocks of a span batch must be apart.
+	BlockTime uint64
 
 	// Builder Config
 
@@ -70,6 +80,13 @@ type ChannelConfig struct {
 	// average from experiments to avoid the chances of creating a small
 	// additional leftover frame.
 	ApproxComprRatio float64
+	// Whether to estimate the compression ratio from the realized compression
+	// ratios of the recent channels, starting at ApproxComprRatio, so that the
+	// channels fill the TargetNumFrames as the compression ratio drifts.
+	AdaptiveComprRatio bool
+	// The algorithm to compress the channels with once the compression fork
+	// is active. Before, the channels are always compressed with zlib.
+	CompressionAlgo derive.CompressionAlgo
 }
 
 // Check validates the [ChannelConfig] parameters.
@@ -95,6 +112,16 @@ func (cc *ChannelConfig) Check() error {
 		return ErrSmallMaxFrameSize
 	}
 
+	// The blocks of a span batch are only contiguous with the L2 block time.
+	if cc.SpanForkTime != nil && cc.BlockTime == 0 {
+		return ErrZeroBlockTime
+	}
+
+	// The [CompressionAlgo] must be decodable by the derivation.
+	if !cc.CompressionAlgo.IsValid() {
+		return derive.ErrUnknownCompressionAlgo
+	}
+
 	return nil
 }
 
@@ -127,10 +154,18 @@ type channelBuilder struct {
 	timeout uint64
 	// reason for currently set timeout
 	timeoutReason error
+	// L1 block number deadline of combined
+	// - consensus channel timeout,
+	// - proposing window timeout,
+	// from which on the frames must be submitted regardless of the L1 fees.
+	// 0 if no deadline set yet.
+	deadline uint64
 
 	// Reason for the channel being full. Set by setFullErr so it's always
 	// guaranteed to be a ChannelFullError wrapping the specific reason.
 	fullErr error
+	// id of the channel, which is kept when restored from the journal
+	id derive.ChannelID
 	// current channel
 	co *derive.ChannelOut
 	// list of blocks in the channel. Saved in case the channel must be rebuilt
@@ -141,22 +176,82 @@ type channelBuilder struct {
 	outputBytes int
 }
 
-// newChannelBuilder creates a new channel builder or returns an error if the
-// channel out could not be created.
-func newChannelBuilder(cfg ChannelConfig) (*channelBuilder, error) {
-	co, err := derive.NewChannelOut()
+// newChannelOut creates the channel out of a channel opened at the given L1
+// time. The channels opened before the compression fork are in the legacy zlib
+// format, which stays valid after the fork, so they can be included at any time.
+// Once the span batch fork is active, the blocks are added to a single span batch.
+func (c ChannelConfig) newChannelOut(l1Time uint64) (*derive.ChannelOut, error) {
+	var (
+		co  *derive.ChannelOut
+		err error
+	)
+	if c.ComprForkTime != nil && l1Time >= *c.ComprForkTime {
+		co, err = derive.NewVersionedChannelOut(c.CompressionAlgo)
+	} else {
+		co, err = derive.NewChannelOut()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.SpanForkTime != nil && l1Time >= *c.SpanForkTime {
+		if err := co.UseSpanBatch(c.BlockTime); err != nil {
+			return nil, err
+		}
+	}
+	return co, nil
+}
+
+// newChannelBuilder creates a new channel builder for a channel opened at the
+// given L1 time or returns an error if the channel out could not be created.
+func newChannelBuilder(cfg ChannelConfig, l1Time uint64) (*channelBuilder, error) {
+	co, err := cfg.newChannelOut(l1Time)
 	if err != nil {
 		return nil, err
 	}
 
 	return &channelBuilder{
 		cfg: cfg,
+		id:  co.ID(),
 		co:  co,
 	}, nil
 }
 
+// restoreChannelBuilder creates a closed channel builder with the given blocks
+// and the frames left to be submitted. The channel out of a restored channel is
+// not used, as all of its frames are already output.
+func restoreChannelBuilder(cfg ChannelConfig, id derive.ChannelID, blocks []*types.Block, frames []frameData) (*channelBuilder, error) {
+	co, err := derive.NewChannelOut()
+	if err != nil {
+		return nil, err
+	}
+	if err := co.Close(); err != nil {
+		return nil, err
+	}
+
+	c := &channelBuilder{
+		cfg:    cfg,
+		id:     id,
+		co:     co,
+		blocks: blocks,
+		frames: frames,
+	}
+	for _, frame := range frames {
+		c.outputBytes += len(frame.data)
+	}
+	for _, block := range blocks {
+		batch, _, err := derive.BlockToBatch(block)
+		if err != nil {
+			return nil, fmt.Errorf("converting block to batch: %w", err)
+		}
+		c.updatePwTimeout(batch)
+	}
+	c.setFullErr(ErrRestored)
+	return c, nil
+}
+
 func (c *channelBuilder) ID() derive.ChannelID {
-	return c.co.ID()
+	return c.id
 }
 
 // InputBytes returns the total amount of input bytes added to the channel.
@@ -186,8 +281,13 @@ func (c *channelBuilder) Reset() error {
 	c.blocks = c.blocks[:0]
 	c.frames = c.frames[:0]
 	c.timeout = 0
+	c.deadline = 0
 	c.fullErr = nil
-	return c.co.Reset()
+	if err := c.co.Reset(); err != nil {
+		return err
+	}
+	c.id = c.co.ID()
+	return nil
 }
 
 // AddBlock adds a block to the channel compression pipeline. IsFull should be
@@ -195,7 +295,8 @@ func (c *channelBuilder) Reset() error {
 // must be started.
 //
 // AddBlock returns a ChannelFullError if called even though the channel is
-// already full. See description of FullErr for details.
+// already full, or if the block doesn't extend the span batch of the channel,
+// which closes the channel. See description of FullErr for details.
 //
 // AddBlock also returns the L1BlockInfo that got extracted from the block's
 // first transaction for subsequent use by the caller.
@@ -211,7 +312,9 @@ func (c *channelBuilder) AddBlock(block *types.Block) (derive.L1BlockInfo, error
 		return l1info, fmt.Errorf("converting block to batch: %w", err)
 	}
 
-	if _, err = c.co.AddBatch(batch); errors.Is(err, derive.ErrTooManyRLPBytes) {
+	// A block which doesn't extend the span batch of the channel, e.g. a block of a
+	// timed out channel, is added to the next channel instead.
+	if _, err = c.co.AddBlockBatch(batch, block.Hash()); errors.Is(err, derive.ErrTooManyRLPBytes) || errors.Is(err, derive.ErrSpanBatchNotContiguous) {
 		c.setFullErr(err)
 		return l1info, c.FullErr()
 	} else if err != nil {
@@ -245,6 +348,7 @@ func (c *channelBuilder) RegisterL1Block(l1BlockNum uint64) {
 func (c *channelBuilder) FramePublished(l1BlockNum uint64) {
 	timeout := l1BlockNum + c.cfg.ChannelTimeout - c.cfg.SubSafetyMargin
 	c.updateTimeout(timeout, ErrChannelTimeoutClose)
+	c.updateDeadline(timeout)
 }
 
 // updateDurationTimeout updates the block timeout with the channel duration
@@ -267,6 +371,7 @@ func (c *channelBuilder) updateDurationTimeout(l1BlockNum uint64) {
 func (c *channelBuilder) updatePwTimeout(batch *derive.BatchData) {
 	timeout := uint64(batch.EpochNum) + c.cfg.ProposerWindowSize - c.cfg.SubSafetyMargin
 	c.updateTimeout(timeout, ErrProposerWindowClose)
+	c.updateDeadline(timeout)
 }
 
 // updateTimeout updates the timeout block to the given block number if it is
@@ -281,6 +386,14 @@ func (c *channelBuilder) updateTimeout(timeoutBlockNum uint64, reason error) {
 	}
 }
 
+// updateDeadline updates the deadline block to the given block number if it is
+// earlier than the current deadline, or if it still unset.
+func (c *channelBuilder) updateDeadline(deadlineBlockNum uint64) {
+	if c.deadline == 0 || c.deadline > deadlineBlockNum {
+		c.deadline = deadlineBlockNum
+	}
+}
+
 // checkTimeout checks if the channel is timed out at the given block number and
 // in this case marks the channel as full, if it wasn't full already.
 func (c *channelBuilder) checkTimeout(blockNum uint64) {
@@ -295,6 +408,13 @@ func (c *channelBuilder) TimedOut(blockNum uint64) bool {
 	return c.timeout != 0 && blockNum >= c.timeout
 }
 
+// PastDeadline returns whether the passed block number is after the deadline
+// block number, so that the frames must be submitted without delay. If no
+// deadline is set yet, it returns false.
+func (c *channelBuilder) PastDeadline(blockNum uint64) bool {
+	return c.deadline != 0 && blockNum >= c.deadline
+}
+
 // inputTargetReached says whether the target amount of input data has been
 // reached in this channel builder. No more blocks can be added afterwards.
 func (c *channelBuilder) inputTargetReached() bool {
@@ -310,7 +430,7 @@ func (c *channelBuilder) IsFull() bool {
 // FullErr returns the reason why the channel is full. If not full yet, it
 // returns nil.
 //
-// It returns a ChannelFullError wrapping one of six possible reasons for the
+// It returns a ChannelFullError wrapping one of ten possible reasons for the
 // channel being full:
 //   - ErrInputTargetReached if the target amount of input data has been reached,
 //   - derive.MaxRLPBytesPerChannel if the general maximum amount of input data
@@ -320,6 +440,12 @@ func (c *channelBuilder) IsFull() bool {
 //   - ErrMaxDurationReached if the max channel duration got reached.
 //   - ErrChannelTimeoutClose if the consensus channel timeout got too close.
 //   - ErrProposerWindowClose if the end of the proposer window got too close.
+//   - ErrForceClosed if the channel got closed through the admin API.
+//   - ErrRestored if the channel got restored from the journal after a restart.
+//   - derive.ErrSpanBatchNotContiguous if the latest AddBlock call got a block
+//     which doesn't extend the span batch of the channel.
+//   - ErrRequeuedBlocks if the blocks of a timed out channel got requeued,
+//     which must not follow the blocks of this channel.
 func (c *channelBuilder) FullErr() error {
 	return c.fullErr
 }
@@ -3,6 +3,7 @@ package batcher
 import (
 	"bytes"
 	"errors"
+	"io"
 	"math"
 	"math/big"
 	"math/rand"
@@ -100,6 +101,24 @@ func newMiniL2BlockWithNumberParent(numTx int, number *big.Int, parent common.Ha
 	}, txs, nil, nil, trie.NewStackTrie(nil))
 }
 
+// newMiniL2Chain returns a chain of minimal L2 blocks, see newMiniL2Block, which are
+// the given block time apart, as required by span batches.
+func newMiniL2Chain(n int, blockTime uint64) []*types.Block {
+	var (
+		blocks []*types.Block
+		parent common.Hash
+	)
+	for i := 0; i < n; i++ {
+		block := newMiniL2BlockWithNumberParent(1, big.NewInt(int64(i)), parent)
+		header := block.Header()
+		header.Time = uint64(i) * blockTime
+		block = types.NewBlockWithHeader(header).WithBody(block.Transactions(), nil)
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	return blocks
+}
+
 // addTooManyBlocks adds blocks to the channel until it hits an error,
 // which is presumably ErrTooManyRLPBytes.
 func addTooManyBlocks(cb *channelBuilder) error {
@@ -123,7 +142,7 @@ func FuzzDurationTimeoutZeroMaxChannelDuration(f *testing.F) {
 	f.Fuzz(func(t *testing.T, l1BlockNum uint64) {
 		channelConfig := defaultTestChannelConfig
 		channelConfig.MaxChannelDuration = 0
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 		cb.timeout = 0
 		cb.updateDurationTimeout(l1BlockNum)
@@ -146,7 +165,7 @@ func FuzzDurationZero(f *testing.F) {
 		// Create the channel builder
 		channelConfig := defaultTestChannelConfig
 		channelConfig.MaxChannelDuration = maxChannelDuration
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 
 		// Whenever the timeout is set to 0, the channel builder should have a duration timeout
@@ -173,7 +192,7 @@ func FuzzDurationTimeoutMaxChannelDuration(f *testing.F) {
 		// Create the channel builder
 		channelConfig := defaultTestChannelConfig
 		channelConfig.MaxChannelDuration = maxChannelDuration
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 
 		// Whenever the timeout is greater than the l1BlockNum,
@@ -207,7 +226,7 @@ func FuzzChannelCloseTimeout(f *testing.F) {
 		channelConfig := defaultTestChannelConfig
 		channelConfig.ChannelTimeout = channelTimeout
 		channelConfig.SubSafetyMargin = subSafetyMargin
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 
 		// Check the timeout
@@ -235,7 +254,7 @@ func FuzzChannelZeroCloseTimeout(f *testing.F) {
 		channelConfig := defaultTestChannelConfig
 		channelConfig.ChannelTimeout = channelTimeout
 		channelConfig.SubSafetyMargin = subSafetyMargin
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 
 		// Check the timeout
@@ -262,7 +281,7 @@ func FuzzProposerWindowClose(f *testing.F) {
 		channelConfig := defaultTestChannelConfig
 		channelConfig.ProposerWindowSize = proposerWindowSize
 		channelConfig.SubSafetyMargin = subSafetyMargin
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 
 		// Check the timeout
@@ -294,7 +313,7 @@ func FuzzProposerWindowZeroTimeoutClose(f *testing.F) {
 		channelConfig := defaultTestChannelConfig
 		channelConfig.ProposerWindowSize = proposerWindowSize
 		channelConfig.SubSafetyMargin = subSafetyMargin
-		cb, err := newChannelBuilder(channelConfig)
+		cb, err := newChannelBuilder(channelConfig, 0)
 		require.NoError(t, err)
 
 		// Check the timeout
@@ -317,7 +336,7 @@ func TestBuilderNextFrame(t *testing.T) {
 	channelConfig := defaultTestChannelConfig
 
 	// Create a new channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Mock the internals of `channelBuilder.outputFrame`
@@ -357,7 +376,7 @@ func TestBuilderWrongFramePanic(t *testing.T) {
 	channelConfig := defaultTestChannelConfig
 
 	// Construct a channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Mock the internals of `channelBuilder.outputFrame`
@@ -388,7 +407,7 @@ func TestOutputFrames(t *testing.T) {
 	channelConfig.MaxFrameSize = 2
 
 	// Construct the channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	require.False(t, cb.IsFull())
@@ -440,7 +459,7 @@ func TestMaxRLPBytesPerChannel(t *testing.T) {
 	channelConfig.ApproxComprRatio = 1
 
 	// Construct the channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Add a block that overflows the [ChannelOut]
@@ -460,7 +479,7 @@ func TestOutputFramesMaxFrameIndex(t *testing.T) {
 	// Continuously add blocks until the max frame index is reached
 	// This should cause the [channelBuilder.OutputFrames] function
 	// to error
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 	require.False(t, cb.IsFull())
 	require.Equal(t, 0, cb.NumFrames())
@@ -503,7 +522,7 @@ func TestBuilderAddBlock(t *testing.T) {
 	channelConfig.ApproxComprRatio = 1
 
 	// Construct the channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Add a nonsense block to the channel builder
@@ -529,7 +548,7 @@ func TestBuilderReset(t *testing.T) {
 	// Lower the max frame size so that we can batch
 	channelConfig.MaxFrameSize = 2
 
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Add a nonsense block to the channel builder
@@ -576,7 +595,7 @@ func TestBuilderRegisterL1Block(t *testing.T) {
 	channelConfig := defaultTestChannelConfig
 
 	// Construct the channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Assert params modified in RegisterL1Block
@@ -599,7 +618,7 @@ func TestBuilderRegisterL1BlockZeroMaxChannelDuration(t *testing.T) {
 	channelConfig.MaxChannelDuration = 0
 
 	// Construct the channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Assert params modified in RegisterL1Block
@@ -620,7 +639,7 @@ func TestFramePublished(t *testing.T) {
 	channelConfig := defaultTestChannelConfig
 
 	// Construct the channel builder
-	cb, err := newChannelBuilder(channelConfig)
+	cb, err := newChannelBuilder(channelConfig, 0)
 	require.NoError(t, err)
 
 	// Let's say the block number is fed in as 100
@@ -636,6 +655,33 @@ func TestFramePublished(t *testing.T) {
 	require.Equal(t, uint64(1000), cb.timeout)
 }
 
+// TestBuilderDeadline tests that only the channel and proposing window timeouts
+// set the deadline of the channel.
+func TestBuilderDeadline(t *testing.T) {
+	channelConfig := defaultTestChannelConfig
+	channelConfig.MaxChannelDuration = 5
+	channelConfig.ChannelTimeout = 1000
+	channelConfig.SubSafetyMargin = 100
+
+	cb, err := newChannelBuilder(channelConfig, 0)
+	require.NoError(t, err)
+
+	// The max channel duration is no deadline
+	cb.RegisterL1Block(100)
+	require.Equal(t, uint64(105), cb.timeout)
+	require.False(t, cb.PastDeadline(105))
+
+	cb.FramePublished(100)
+	require.Equal(t, uint64(1000), cb.deadline)
+	require.False(t, cb.PastDeadline(999))
+	require.True(t, cb.PastDeadline(1000))
+
+	// An earlier proposing window timeout moves the deadline forward,
+	// the epoch of the mini block is 100.
+	require.NoError(t, addMiniBlock(cb))
+	require.Equal(t, 100+channelConfig.ProposerWindowSize-channelConfig.SubSafetyMargin, cb.deadline)
+}
+
 func TestChannelBuilder_InputBytes(t *testing.T) {
 	require := require.New(t)
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -662,7 +708,7 @@ func TestChannelBuilder_OutputBytes(t *testing.T) {
 	cfg.MaxFrameSize = 1000
 	cfg.TargetNumFrames = 16
 	cfg.ApproxComprRatio = 1.0
-	cb, err := newChannelBuilder(cfg)
+	cb, err := newChannelBuilder(cfg, 0)
 	require.NoError(err, "newChannelBuilder")
 
 	require.Zero(cb.OutputBytes())
@@ -689,10 +735,119 @@ func TestChannelBuilder_OutputBytes(t *testing.T) {
 	require.Equal(cb.OutputBytes(), flen)
 }
 
+// TestChannelBuilder_CompressionFork tests that the channels opened once the
+// compression fork is active are in the versioned format.
+func TestChannelBuilder_CompressionFork(t *testing.T) {
+	forkTime := uint64(100)
+	cfg := defaultTestChannelConfig
+	cfg.ComprForkTime = &forkTime
+	cfg.CompressionAlgo = derive.Zstd
+
+	for _, l1Time := range []uint64{forkTime - 1, forkTime} {
+		cb, err := newChannelBuilder(cfg, l1Time)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, addMiniBlock(cb))
+		}
+		cb.setFullErr(ErrForceClosed)
+		require.NoError(t, cb.OutputFrames())
+
+		var data []byte
+		for cb.HasFrame() {
+			var frame derive.Frame
+			require.NoError(t, frame.UnmarshalBinary(bytes.NewReader(cb.NextFrame().data)))
+			data = append(data, frame.Data...)
+		}
+		if l1Time < forkTime {
+			// zlib header of the legacy format
+			require.Equal(t, byte(0x78), data[0])
+		} else {
+			require.Equal(t, byte(derive.Zstd), data[0])
+		}
+
+		br, err := derive.BatchReader(bytes.NewReader(data), eth.L1BlockRef{Time: l1Time}, true)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err := br()
+			require.NoError(t, err)
+		}
+	}
+}
+
+// TestChannelBuilder_SpanBatchFork tests that the channels opened once the
+// span batch fork is active contain a single span batch.
+func TestChannelBuilder_SpanBatchFork(t *testing.T) {
+	forkTime := uint64(100)
+	cfg := defaultTestChannelConfig
+	cfg.ComprForkTime = new(uint64)
+	cfg.SpanForkTime = &forkTime
+	cfg.BlockTime = 2
+
+	for _, l1Time := range []uint64{forkTime - 1, forkTime} {
+		cb, err := newChannelBuilder(cfg, l1Time)
+		require.NoError(t, err)
+		for _, block := range newMiniL2Chain(3, cfg.BlockTime) {
+			_, err := cb.AddBlock(block)
+			require.NoError(t, err)
+		}
+		cb.setFullErr(ErrForceClosed)
+		require.NoError(t, cb.OutputFrames())
+
+		var data []byte
+		for cb.HasFrame() {
+			var frame derive.Frame
+			require.NoError(t, frame.UnmarshalBinary(bytes.NewReader(cb.NextFrame().data)))
+			data = append(data, frame.Data...)
+		}
+
+		br, err := derive.BatchReader(bytes.NewReader(data), eth.L1BlockRef{Time: l1Time}, true)
+		require.NoError(t, err)
+		if l1Time < forkTime {
+			for i := 0; i < 3; i++ {
+				batch, err := br()
+				require.NoError(t, err)
+				require.False(t, batch.Batch.IsSpan())
+			}
+		} else {
+			batch, err := br()
+			require.NoError(t, err)
+			require.True(t, batch.Batch.IsSpan())
+			require.Len(t, batch.Batch.Span.Blocks, 3)
+		}
+		_, err = br()
+		require.ErrorIs(t, err, io.EOF)
+	}
+}
+
+// TestChannelBuilder_SpanBatchNotContiguous tests that a block which doesn't
+// extend the span batch closes the channel, so that it's added to the next one.
+func TestChannelBuilder_SpanBatchNotContiguous(t *testing.T) {
+	cfg := defaultTestChannelConfig
+	cfg.SpanForkTime = new(uint64)
+	cfg.BlockTime = 2
+	require.NoError(t, cfg.Check())
+	blocks := newMiniL2Chain(4, cfg.BlockTime)
+
+	for _, next := range []*types.Block{blocks[0], blocks[3]} {
+		cb, err := newChannelBuilder(cfg, 0)
+		require.NoError(t, err)
+		_, err = cb.AddBlock(blocks[1])
+		require.NoError(t, err)
+
+		_, err = cb.AddBlock(next)
+		require.ErrorIs(t, err, derive.ErrSpanBatchNotContiguous)
+		require.True(t, cb.IsFull())
+		require.Len(t, cb.Blocks(), 1)
+	}
+
+	cfg.BlockTime = 0
+	require.ErrorIs(t, cfg.Check(), ErrZeroBlockTime)
+}
+
 func defaultChannelBuilderSetup(t *testing.T) (*channelBuilder, ChannelConfig) {
 	t.Helper()
 	cfg := defaultTestChannelConfig
-	cb, err := newChannelBuilder(cfg)
+	cb, err := newChannelBuilder(cfg, 0)
 	require.NoError(t, err, "newChannelBuilder")
 	return cb, cfg
 }
@@ -4,27 +4,36 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
+	"sort"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/wemixkanvas/kanvas/components/batcher/journal"
 	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	"github.com/wemixkanvas/kanvas/components/batcher/rpc"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 )
 
-var ErrReorg = errors.New("block does not extend existing chain")
+var (
+	ErrReorg          = errors.New("block does not extend existing chain")
+	ErrNothingToClose = errors.New("no open channel or pending blocks to close")
+)
 
 // channelManager stores a contiguous set of blocks & turns them into channels.
 // Upon receiving tx confirmation (or a tx failure), it does channel error handling.
 //
-// For simplicity, it only creates a single pending channel at a time & waits for
-// the channel to either successfully be submitted or timeout before creating a new
-// channel.
-// Functions on channelManager are not safe for concurrent access.
+// Multiple channels can be pending at a time: a new channel is opened as soon as
+// the current one is full, while frames of the previous channels are still being
+// confirmed. Each channel times out independently.
+// Exported functions on channelManager are safe for concurrent access, so that
+// the admin API can inspect and close the current channel while submitting.
 type channelManager struct {
+	mu   sync.Mutex
 	log  log.Logger
 	metr metrics.Metricer
 	cfg  ChannelConfig
@@ -34,46 +43,69 @@ type channelManager struct {
 	// last block hash - for reorg detection
 	tip common.Hash
 
-	// Pending data returned by TxData waiting on Tx Confirmed/Failed
-
-	// pending channel builder
-	pendingChannel *channelBuilder
-	// Set of unconfirmed txID -> frame data. For tx resubmission
-	pendingTransactions map[txID]txData
-	// Set of confirmed txID -> inclusion block. For determining if the channel is timed out
-	confirmedTransactions map[txID]eth.BlockID
+	// channel to add new blocks to, it's also the last channel of channelQueue
+	currentChannel *channel
+	// channels that are not fully submitted yet, in the order of creation. A fully submitted
+	// channel stays queued until the channels before it are fully submitted as well, so that
+	// its blocks are requeued if one of them times out.
+	channelQueue []*channel
+	// Set of unconfirmed txID -> channel of the frame data, to look up the channel on Tx Confirmed/Failed
+	txChannels map[txID]*channel
+
+	// journal persists the closed channels and their frame transactions. It may be nil.
+	journal *journal.Journal
+
+	// estimator of the compression ratio of the next channel, nil if the
+	// static ApproxComprRatio is used.
+	comprEstimator *comprRatioEstimator
 }
 
 func NewChannelManager(log log.Logger, metr metrics.Metricer, cfg ChannelConfig) *channelManager {
-	return &channelManager{
+	s := &channelManager{
 		log:  log,
 		metr: metr,
 		cfg:  cfg,
 
-		pendingTransactions:   make(map[txID]txData),
-		confirmedTransactions: make(map[txID]eth.BlockID),
+		txChannels: make(map[txID]*channel),
+	}
+	if cfg.AdaptiveComprRatio {
+		s.comprEstimator = newComprRatioEstimator(cfg.ApproxComprRatio)
+		metr.RecordComprRatioEstimate(s.comprEstimator.Ratio())
 	}
+	return s
 }
 
 // Clear clears the entire state of the channel manager.
 // It is intended to be used after an L2 reorg.
 func (s *channelManager) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.log.Trace("clearing channel manager state")
 	s.blocks = s.blocks[:0]
 	s.tip = common.Hash{}
-	s.clearPendingChannel()
+	s.currentChannel = nil
+	s.channelQueue = nil
+	s.txChannels = make(map[txID]*channel)
+	if s.journal != nil {
+		if err := s.journal.Clear(); err != nil {
+			s.log.Error("failed to clear journal", "err", err)
+		}
+	}
 }
 
 // TxFailed records a transaction as failed. It will attempt to resubmit the data
 // in the failed transaction.
 func (s *channelManager) TxFailed(id txID) {
-	if data, ok := s.pendingTransactions[id]; ok {
-		s.log.Trace("marked transaction as failed", "id", id)
-		// Note: when the batcher is changed to send multiple frames per tx,
-		// this needs to be changed to iterate over all frames of the tx data
-		// and re-queue them.
-		s.pendingChannel.PushFrame(data.Frame())
-		delete(s.pendingTransactions, id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.txChannels[id]; ok {
+		delete(s.txChannels, id)
+		ch.TxFailed(id)
+		if ch.journaled {
+			if err := s.journal.DeleteTx(id.chID, id.frameNumber); err != nil {
+				s.log.Error("failed to journal failed tx", "id", id, "err", err)
+			}
+		}
 	} else {
 		s.log.Warn("unknown transaction marked as failed", "id", id)
 	}
@@ -84,106 +116,226 @@ func (s *channelManager) TxFailed(id txID) {
 // TxConfirmed marks a transaction as confirmed on L1. Unfortunately even if all frames in
 // a channel have been marked as confirmed on L1 the channel may be invalid & need to be
 // resubmitted.
-// This function may reset the pending channel if the pending channel has timed out.
+// This function may remove the channel of the transaction if it has timed out or
+// has been fully submitted.
 func (s *channelManager) TxConfirmed(id txID, inclusionBlock eth.BlockID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.metr.RecordBatchTxSubmitted()
 	s.log.Debug("marked transaction as confirmed", "id", id, "block", inclusionBlock)
-	if _, ok := s.pendingTransactions[id]; !ok {
+	ch, ok := s.txChannels[id]
+	if !ok {
 		s.log.Warn("unknown transaction marked as confirmed", "id", id, "block", inclusionBlock)
 		// TODO: This can occur if we clear the channel while there are still pending transactions
 		// We need to keep track of stale transactions instead
 		return
 	}
-	delete(s.pendingTransactions, id)
-	s.confirmedTransactions[id] = inclusionBlock
-	s.pendingChannel.FramePublished(inclusionBlock.Number)
-
-	// If this channel timed out, put the pending blocks back into the local saved blocks
-	// and then reset this state so it can try to build a new channel.
-	if s.pendingChannelIsTimedOut() {
-		s.metr.RecordChannelTimedOut(s.pendingChannel.ID())
-		s.log.Warn("Channel timed out", "id", s.pendingChannel.ID())
-		s.blocks = append(s.pendingChannel.Blocks(), s.blocks...)
-		s.clearPendingChannel()
+	delete(s.txChannels, id)
+
+	// If this channel timed out, put the blocks of it and of all the later channels back into the
+	// local saved blocks, in order, and remove the channels so that the blocks are submitted in new
+	// channels. The batches of the later channels would not extend the derived chain otherwise,
+	// and the requeued blocks would follow them. The current channel is one of the later channels,
+	// so it's closed as well.
+	timedOut := ch.TxConfirmed(id, inclusionBlock)
+	if ch.journaled {
+		record := &journal.TxRecord{Hash: ch.txHashes[id], InclusionBlock: &inclusionBlock}
+		if err := s.journal.PutTx(id.chID, id.frameNumber, record); err != nil {
+			s.log.Error("failed to journal confirmed tx", "id", id, "err", err)
+		}
+	}
+	if timedOut {
+		s.metr.RecordChannelTimedOut(ch.ID())
+		s.log.Warn("Channel timed out", "id", ch.ID())
+		s.requeueChannels(ch)
+		return
 	}
 	// If we are done with this channel, record that.
-	if s.pendingChannelIsFullySubmitted() {
-		s.metr.RecordChannelFullySubmitted(s.pendingChannel.ID())
-		s.log.Info("Channel is fully submitted", "id", s.pendingChannel.ID())
-		s.clearPendingChannel()
+	if ch.isFullySubmitted() {
+		s.metr.RecordChannelFullySubmitted(ch.ID())
+		s.log.Info("Channel is fully submitted", "id", ch.ID())
+		s.pruneSubmittedChannels()
 	}
 }
 
-// clearPendingChannel resets all pending state back to an initialized but empty state.
-// TODO: Create separate "pending" state
-func (s *channelManager) clearPendingChannel() {
-	s.pendingChannel = nil
-	s.pendingTransactions = make(map[txID]txData)
-	s.confirmedTransactions = make(map[txID]eth.BlockID)
+// requeueChannels removes the timed out channel and all the channels after it, and puts
+// their blocks back in front of the local saved blocks.
+func (s *channelManager) requeueChannels(timedOut *channel) {
+	var requeued []*channel
+	for i, ch := range s.channelQueue {
+		if ch == timedOut {
+			requeued = append(requeued, s.channelQueue[i:]...)
+			break
+		}
+	}
+
+	var blocks []*types.Block
+	for _, ch := range requeued {
+		if ch != timedOut {
+			s.log.Warn("Requeueing channel after timed out channel", "id", ch.ID(), "timed_out", timedOut.ID())
+		}
+		if ch == s.currentChannel && !ch.builder.IsFull() {
+			ch.builder.setFullErr(ErrRequeuedBlocks)
+		}
+		blocks = append(blocks, ch.Blocks()...)
+		s.removeChannel(ch)
+	}
+	s.blocks = append(blocks, s.blocks...)
+}
+
+// pruneSubmittedChannels removes the fully submitted channels at the front of the queue.
+func (s *channelManager) pruneSubmittedChannels() {
+	for len(s.channelQueue) > 0 && s.channelQueue[0].isFullySubmitted() {
+		s.removeChannel(s.channelQueue[0])
+	}
 }
 
-// pendingChannelIsTimedOut returns true if submitted channel has timed out.
-// A channel has timed out if the difference in L1 Inclusion blocks between
-// the first & last included block is greater than or equal to the channel timeout.
-func (s *channelManager) pendingChannelIsTimedOut() bool {
-	if s.pendingChannel == nil {
-		return false // no channel to be timed out
-	}
-	// No confirmed transactions => not timed out
-	if len(s.confirmedTransactions) == 0 {
-		return false
-	}
-	// If there are confirmed transactions, find the first + last confirmed block numbers
-	min := uint64(math.MaxUint64)
-	max := uint64(0)
-	for _, inclusionBlock := range s.confirmedTransactions {
-		if inclusionBlock.Number < min {
-			min = inclusionBlock.Number
+// removeChannel removes the channel and its pending transactions from the state.
+func (s *channelManager) removeChannel(ch *channel) {
+	for i, c := range s.channelQueue {
+		if c == ch {
+			s.channelQueue = append(s.channelQueue[:i], s.channelQueue[i+1:]...)
+			break
 		}
-		if inclusionBlock.Number > max {
-			max = inclusionBlock.Number
+	}
+	for id := range ch.pendingTransactions {
+		delete(s.txChannels, id)
+	}
+	if s.currentChannel == ch {
+		s.currentChannel = nil
+	}
+	if ch.journaled {
+		if err := s.journal.DeleteChannel(ch.ID()); err != nil {
+			s.log.Error("failed to delete channel from journal", "id", ch.ID(), "err", err)
 		}
 	}
-	return max-min >= s.cfg.ChannelTimeout
 }
 
-// pendingChannelIsFullySubmitted returns true if the channel has been fully submitted.
-func (s *channelManager) pendingChannelIsFullySubmitted() bool {
-	if s.pendingChannel == nil {
-		return false // todo: can decide either way here. Nonsensical answer though
+// TxSent records the hash of the transaction sent for the frame, so that it can
+// be looked up on L1 after a restart.
+func (s *channelManager) TxSent(id txID, txHash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.txChannels[id]
+	if !ok {
+		s.log.Warn("unknown transaction marked as sent", "id", id, "tx_hash", txHash)
+		return
+	}
+	ch.TxSent(id, txHash)
+	if ch.journaled {
+		if err := s.journal.PutTx(id.chID, id.frameNumber, &journal.TxRecord{Hash: txHash}); err != nil {
+			s.log.Error("failed to journal sent tx", "id", id, "err", err)
+		}
 	}
-	return s.pendingChannel.IsFull() && len(s.pendingTransactions)+s.pendingChannel.NumFrames() == 0
 }
 
-// nextTxData pops off s.datas & handles updating the internal state
-func (s *channelManager) nextTxData() (txData, error) {
-	if s.pendingChannel == nil || !s.pendingChannel.HasFrame() {
-		s.log.Trace("no next tx data")
-		return txData{}, io.EOF // TODO: not enough data error instead
+// journalChannel writes the closed channel, with all of its frames, and the
+// transactions of the frames sent so far to the journal.
+func (s *channelManager) journalChannel(ch *channel) {
+	blocks := ch.Blocks()
+	if s.journal == nil || len(blocks) == 0 {
+		return
+	}
+
+	record := &journal.ChannelRecord{
+		ID:         ch.ID(),
+		FirstBlock: eth.ToBlockID(blocks[0]),
+		LastBlock:  eth.ToBlockID(blocks[len(blocks)-1]),
+	}
+	for _, frame := range ch.builder.frames {
+		record.Frames = append(record.Frames, &journal.FrameRecord{Number: hexutil.Uint64(frame.id.frameNumber), Data: frame.data})
+	}
+	for id, data := range ch.pendingTransactions {
+		record.Frames = append(record.Frames, &journal.FrameRecord{Number: hexutil.Uint64(id.frameNumber), Data: data.Frame().data})
+	}
+	for id := range ch.confirmedTransactions {
+		record.Frames = append(record.Frames, &journal.FrameRecord{Number: hexutil.Uint64(id.frameNumber)})
+	}
+	sort.Slice(record.Frames, func(i, j int) bool {
+		return record.Frames[i].Number < record.Frames[j].Number
+	})
+	if err := s.journal.PutChannel(record); err != nil {
+		s.log.Error("failed to journal channel", "id", ch.ID(), "err", err)
+		return
+	}
+
+	txRecords := make(map[txID]*journal.TxRecord)
+	for id, txHash := range ch.txHashes {
+		txRecords[id] = &journal.TxRecord{Hash: txHash}
 	}
+	for id, inclusionBlock := range ch.confirmedTransactions {
+		inclusionBlock := inclusionBlock
+		if txRecords[id] == nil {
+			txRecords[id] = &journal.TxRecord{}
+		}
+		txRecords[id].InclusionBlock = &inclusionBlock
+	}
+	for id, txRecord := range txRecords {
+		if err := s.journal.PutTx(id.chID, id.frameNumber, txRecord); err != nil {
+			s.log.Error("failed to journal tx", "id", id, "err", err)
+		}
+	}
+	ch.journaled = true
+}
 
-	frame := s.pendingChannel.NextFrame()
-	txdata := txData{frame}
-	id := txdata.ID()
+// restore queues the channels restored from the journal. The blocks added
+// afterwards must extend the given tip, which is the last block of the channels.
+func (s *channelManager) restore(channels []*channel, tip common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channelQueue = append(channels, s.channelQueue...)
+	s.tip = tip
+}
 
-	s.log.Trace("returning next tx data", "id", id)
-	s.pendingTransactions[id] = txdata
-	return txdata, nil
+// nextTxData pops off the next frame of the channel & tracks it as pending.
+func (s *channelManager) nextTxData(ch *channel) txData {
+	txdata := ch.NextTxData()
+	s.txChannels[txdata.ID()] = ch
+	return txdata
 }
 
 // TxData returns the next tx data that should be submitted to L1.
 //
-// It currently only uses one frame per transaction. If the pending channel is
-// full, it only returns the remaining frames of this channel until it got
-// successfully fully sent to L1. It returns io.EOF if there's no pending frame.
-func (s *channelManager) TxData(l1Head eth.BlockID) (txData, error) {
-	dataPending := s.pendingChannel != nil && s.pendingChannel.HasFrame()
-	s.log.Debug("Requested tx data", "l1Head", l1Head, "data_pending", dataPending, "blocks_pending", len(s.blocks))
+// It currently only uses one frame per transaction. The frames of the older
+// channels are returned first. If there are none, new blocks are added to the
+// current channel, opening a new one if it is full. It returns io.EOF if
+// there's no pending frame.
+func (s *channelManager) TxData(l1Head eth.L1BlockRef) (txData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.txData(l1Head, false)
+}
+
+// UrgentTxData is like TxData, but only returns the frames of the channels which
+// must be submitted without delay at the given L1 head. The frames of the other
+// channels are held back, while new blocks are still added to the channels.
+func (s *channelManager) UrgentTxData(l1Head eth.L1BlockRef) (txData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.txData(l1Head, true)
+}
+
+// HasFrame returns whether there's a pending frame of any channel.
+func (s *channelManager) HasFrame() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.channelQueue {
+		if ch.HasFrame() {
+			return true
+		}
+	}
+	return false
+}
 
+func (s *channelManager) txData(l1Head eth.L1BlockRef, urgentOnly bool) (txData, error) {
 	// Short circuit if there is a pending frame.
-	if dataPending {
-		return s.nextTxData()
+	for _, ch := range s.channelQueue {
+		if ch.HasFrame() && (!urgentOnly || ch.isUrgent(l1Head.Number)) {
+			s.log.Debug("Requested tx data", "l1Head", l1Head, "data_pending", true, "blocks_pending", len(s.blocks))
+			return s.nextTxData(ch), nil
+		}
 	}
+	s.log.Debug("Requested tx data", "l1Head", l1Head, "data_pending", false, "blocks_pending", len(s.blocks))
 
 	// No pending frame, so we have to add new blocks to the channel
 
@@ -192,7 +344,7 @@ func (s *channelManager) TxData(l1Head eth.BlockID) (txData, error) {
 		return txData{}, io.EOF
 	}
 
-	if err := s.ensurePendingChannel(l1Head); err != nil {
+	if err := s.ensureChannelWithSpace(l1Head); err != nil {
 		return txData{}, err
 	}
 
@@ -209,48 +361,60 @@ func (s *channelManager) TxData(l1Head eth.BlockID) (txData, error) {
 		return txData{}, err
 	}
 
-	return s.nextTxData()
+	if !s.currentChannel.HasFrame() || (urgentOnly && !s.currentChannel.isUrgent(l1Head.Number)) {
+		s.log.Trace("no next tx data")
+		return txData{}, io.EOF // TODO: not enough data error instead
+	}
+	return s.nextTxData(s.currentChannel), nil
 }
 
-func (s *channelManager) ensurePendingChannel(l1Head eth.BlockID) error {
-	if s.pendingChannel != nil {
+// ensureChannelWithSpace opens a new channel if there's no current channel or it is full.
+func (s *channelManager) ensureChannelWithSpace(l1Head eth.L1BlockRef) error {
+	if s.currentChannel != nil && !s.currentChannel.builder.IsFull() {
 		return nil
 	}
 
-	cb, err := newChannelBuilder(s.cfg)
+	cfg := s.cfg
+	if s.comprEstimator != nil {
+		cfg.ApproxComprRatio = s.comprEstimator.Ratio()
+	}
+	ch, err := newChannel(s.log, s.metr, cfg, l1Head.Time)
 	if err != nil {
 		return fmt.Errorf("creating new channel: %w", err)
 	}
-	s.pendingChannel = cb
+	s.currentChannel = ch
+	s.channelQueue = append(s.channelQueue, ch)
 	s.log.Info("Created channel",
-		"id", cb.ID(),
+		"id", ch.ID(),
 		"l1Head", l1Head,
-		"blocks_pending", len(s.blocks))
-	s.metr.RecordChannelOpened(cb.ID(), len(s.blocks))
+		"blocks_pending", len(s.blocks),
+		"channels_pending", len(s.channelQueue))
+	s.metr.RecordChannelOpened(ch.ID(), len(s.blocks))
 
 	return nil
 }
 
-// registerL1Block registers the given block at the pending channel.
-func (s *channelManager) registerL1Block(l1Head eth.BlockID) {
-	s.pendingChannel.RegisterL1Block(l1Head.Number)
+// registerL1Block registers the given block at the current channel.
+func (s *channelManager) registerL1Block(l1Head eth.L1BlockRef) {
+	s.currentChannel.builder.RegisterL1Block(l1Head.Number)
 	s.log.Debug("new L1-block registered at channel builder",
 		"l1Head", l1Head,
-		"channel_full", s.pendingChannel.IsFull(),
-		"full_reason", s.pendingChannel.FullErr(),
+		"channel_full", s.currentChannel.builder.IsFull(),
+		"full_reason", s.currentChannel.builder.FullErr(),
 	)
 }
 
-// processBlocks adds blocks from the blocks queue to the pending channel until
+// processBlocks adds blocks from the blocks queue to the current channel until
 // either the queue got exhausted or the channel is full.
 func (s *channelManager) processBlocks() error {
+	cb := s.currentChannel.builder
 	var (
 		blocksAdded int
 		_chFullErr  *ChannelFullError // throw away, just for type checking
 		latestL2ref eth.L2BlockRef
 	)
 	for i, block := range s.blocks {
-		l1info, err := s.pendingChannel.AddBlock(block)
+		l1info, err := cb.AddBlock(block)
 		if errors.As(err, &_chFullErr) {
 			// current block didn't get added because channel is already full
 			break
@@ -260,7 +424,7 @@ func (s *channelManager) processBlocks() error {
 		blocksAdded += 1
 		latestL2ref = l2BlockRefFromBlockAndL1Info(block, l1info)
 		// current block got added but channel is now full
-		if s.pendingChannel.IsFull() {
+		if cb.IsFull() {
 			break
 		}
 	}
@@ -276,34 +440,35 @@ func (s *channelManager) processBlocks() error {
 	s.metr.RecordL2BlocksAdded(latestL2ref,
 		blocksAdded,
 		len(s.blocks),
-		s.pendingChannel.InputBytes(),
-		s.pendingChannel.ReadyBytes())
+		cb.InputBytes(),
+		cb.ReadyBytes())
 	s.log.Debug("Added blocks to channel",
 		"blocks_added", blocksAdded,
 		"blocks_pending", len(s.blocks),
-		"channel_full", s.pendingChannel.IsFull(),
-		"input_bytes", s.pendingChannel.InputBytes(),
-		"ready_bytes", s.pendingChannel.ReadyBytes(),
+		"channel_full", cb.IsFull(),
+		"input_bytes", cb.InputBytes(),
+		"ready_bytes", cb.ReadyBytes(),
 	)
 	return nil
 }
 
 func (s *channelManager) outputFrames() error {
-	if err := s.pendingChannel.OutputFrames(); err != nil {
+	cb := s.currentChannel.builder
+	if err := cb.OutputFrames(); err != nil {
 		return fmt.Errorf("creating frames with channel builder: %w", err)
 	}
-	if !s.pendingChannel.IsFull() {
+	if !cb.IsFull() {
 		return nil
 	}
 
-	inBytes, outBytes := s.pendingChannel.InputBytes(), s.pendingChannel.OutputBytes()
+	inBytes, outBytes := cb.InputBytes(), cb.OutputBytes()
 	s.metr.RecordChannelClosed(
-		s.pendingChannel.ID(),
+		cb.ID(),
 		len(s.blocks),
-		s.pendingChannel.NumFrames(),
+		cb.NumFrames(),
 		inBytes,
 		outBytes,
-		s.pendingChannel.FullErr(),
+		cb.FullErr(),
 	)
 
 	var comprRatio float64
@@ -311,14 +476,19 @@ func (s *channelManager) outputFrames() error {
 		comprRatio = float64(outBytes) / float64(inBytes)
 	}
 	s.log.Info("Channel closed",
-		"id", s.pendingChannel.ID(),
+		"id", cb.ID(),
 		"blocks_pending", len(s.blocks),
-		"num_frames", s.pendingChannel.NumFrames(),
+		"num_frames", cb.NumFrames(),
 		"input_bytes", inBytes,
 		"output_bytes", outBytes,
-		"full_reason", s.pendingChannel.FullErr(),
+		"full_reason", cb.FullErr(),
 		"compr_ratio", comprRatio,
 	)
+	if s.comprEstimator != nil && s.comprEstimator.ChannelClosed(cb.FullErr(), inBytes, outBytes) {
+		s.metr.RecordComprRatioEstimate(s.comprEstimator.Ratio())
+		s.log.Debug("Updated compression ratio estimate", "compr_ratio_estimate", s.comprEstimator.Ratio())
+	}
+	s.journalChannel(s.currentChannel)
 	return nil
 }
 
@@ -326,6 +496,8 @@ func (s *channelManager) outputFrames() error {
 // if the block does not extend the last block loaded into the state. If no
 // blocks were added yet, the parent hash check is skipped.
 func (s *channelManager) AddL2Block(block *types.Block) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.tip != (common.Hash{}) && s.tip != block.ParentHash() {
 		return ErrReorg
 	}
@@ -345,3 +517,64 @@ func l2BlockRefFromBlockAndL1Info(block *types.Block, l1info derive.L1BlockInfo)
 		SequenceNumber: l1info.SequenceNumber,
 	}
 }
+
+// PendingState returns the number of blocks waiting to be added to a channel
+// and the state of the channels which are not fully submitted yet.
+func (s *channelManager) PendingState() *rpc.PendingState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := &rpc.PendingState{
+		BlocksPending: len(s.blocks),
+		Channels:      make([]*rpc.ChannelState, 0, len(s.channelQueue)),
+	}
+	for _, ch := range s.channelQueue {
+		cs := &rpc.ChannelState{
+			ID:              ch.ID(),
+			Full:            ch.builder.IsFull(),
+			Blocks:          len(ch.Blocks()),
+			InputBytes:      ch.builder.InputBytes(),
+			OutputBytes:     ch.builder.OutputBytes(),
+			FramesPending:   ch.builder.NumFrames(),
+			FramesInFlight:  len(ch.pendingTransactions),
+			FramesConfirmed: len(ch.confirmedTransactions),
+		}
+		if err := ch.builder.FullErr(); err != nil {
+			cs.FullReason = err.Error()
+		}
+		state.Channels = append(state.Channels, cs)
+	}
+	return state
+}
+
+// ForceCloseChannel closes the current channel, so that all of its remaining
+// frames are output and no more blocks are added to it. If there is no open
+// channel, a new one is opened with the pending blocks and closed right away.
+// It returns the ID of the closed channel, or ErrNothingToClose if there are
+// neither an open channel nor pending blocks.
+func (s *channelManager) ForceCloseChannel(l1Head eth.L1BlockRef) (derive.ChannelID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentChannel == nil || s.currentChannel.builder.IsFull() {
+		if len(s.blocks) == 0 {
+			return derive.ChannelID{}, ErrNothingToClose
+		}
+		if err := s.ensureChannelWithSpace(l1Head); err != nil {
+			return derive.ChannelID{}, err
+		}
+		if err := s.processBlocks(); err != nil {
+			return derive.ChannelID{}, err
+		}
+	}
+
+	cb := s.currentChannel.builder
+	if !cb.IsFull() {
+		cb.setFullErr(ErrForceClosed)
+	}
+	if err := s.outputFrames(); err != nil {
+		return derive.ChannelID{}, err
+	}
+	s.log.Info("Channel force closed", "id", cb.ID())
+	return cb.ID(), nil
+}
@@ -1,6 +1,7 @@
 package batcher
 
 import (
+	"fmt"
 	"io"
 	"math/big"
 	"math/rand"
@@ -12,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wemixkanvas/kanvas/components/batcher/journal"
 	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
@@ -19,51 +21,6 @@ import (
 	"github.com/wemixkanvas/kanvas/components/node/testlog"
 )
 
-// TestPendingChannelTimeout tests that the channel manager
-// correctly identifies when a pending channel is timed out.
-func TestPendingChannelTimeout(t *testing.T) {
-	// Create a new channel manager with a ChannelTimeout
-	log := testlog.Logger(t, log.LvlCrit)
-	m := NewChannelManager(log, metrics.NoopMetrics, ChannelConfig{
-		ChannelTimeout: 100,
-	})
-
-	// Pending channel is nil so is cannot be timed out
-	timeout := m.pendingChannelIsTimedOut()
-	require.False(t, timeout)
-
-	// Set the pending channel
-	err := m.ensurePendingChannel(eth.BlockID{})
-	require.NoError(t, err)
-
-	// There are no confirmed transactions so
-	// the pending channel cannot be timed out
-	timeout = m.pendingChannelIsTimedOut()
-	require.False(t, timeout)
-
-	// Manually set a confirmed transactions
-	// To avoid other methods clearing state
-	m.confirmedTransactions[frameID{frameNumber: 0}] = eth.BlockID{Number: 0}
-	m.confirmedTransactions[frameID{frameNumber: 1}] = eth.BlockID{Number: 99}
-
-	// Since the ChannelTimeout is 100, the
-	// pending channel should not be timed out
-	timeout = m.pendingChannelIsTimedOut()
-	require.False(t, timeout)
-
-	// Add a confirmed transaction with a higher number
-	// than the ChannelTimeout
-	m.confirmedTransactions[frameID{
-		frameNumber: 2,
-	}] = eth.BlockID{
-		Number: 101,
-	}
-
-	// Now the pending channel should be timed out
-	timeout = m.pendingChannelIsTimedOut()
-	require.True(t, timeout)
-}
-
 // TestChannelManagerReturnsErrReorg ensures that the channel manager
 // detects a reorg when it has cached L1 blocks.
 func TestChannelManagerReturnsErrReorg(t *testing.T) {
@@ -115,56 +72,15 @@ func TestChannelManagerReturnsErrReorgWhenDrained(t *testing.T) {
 	err := m.AddL2Block(a)
 	require.NoError(t, err)
 
-	_, err = m.TxData(eth.BlockID{})
+	_, err = m.TxData(eth.L1BlockRef{})
 	require.NoError(t, err)
-	_, err = m.TxData(eth.BlockID{})
+	_, err = m.TxData(eth.L1BlockRef{})
 	require.ErrorIs(t, err, io.EOF)
 
 	err = m.AddL2Block(x)
 	require.ErrorIs(t, err, ErrReorg)
 }
 
-// TestChannelManagerNextTxData checks the nextTxData function.
-func TestChannelManagerNextTxData(t *testing.T) {
-	log := testlog.Logger(t, log.LvlCrit)
-	m := NewChannelManager(log, metrics.NoopMetrics, ChannelConfig{})
-
-	// Nil pending channel should return EOF
-	returnedTxData, err := m.nextTxData()
-	require.ErrorIs(t, err, io.EOF)
-	require.Equal(t, txData{}, returnedTxData)
-
-	// Set the pending channel
-	// The nextTxData function should still return EOF
-	// since the pending channel has no frames
-	err = m.ensurePendingChannel(eth.BlockID{})
-	require.NoError(t, err)
-	returnedTxData, err = m.nextTxData()
-	require.ErrorIs(t, err, io.EOF)
-	require.Equal(t, txData{}, returnedTxData)
-
-	// Manually push a frame into the pending channel
-	channelID := m.pendingChannel.ID()
-	frame := frameData{
-		data: []byte{},
-		id: frameID{
-			chID:        channelID,
-			frameNumber: uint16(0),
-		},
-	}
-	m.pendingChannel.PushFrame(frame)
-	require.Equal(t, 1, m.pendingChannel.NumFrames())
-
-	// Now the nextTxData function should return the frame
-	returnedTxData, err = m.nextTxData()
-	expectedTxData := txData{frame}
-	expectedChannelID := expectedTxData.ID()
-	require.NoError(t, err)
-	require.Equal(t, expectedTxData, returnedTxData)
-	require.Equal(t, 0, m.pendingChannel.NumFrames())
-	require.Equal(t, expectedTxData, m.pendingTransactions[expectedChannelID])
-}
-
 // TestClearChannelManager tests clearing the channel manager.
 func TestClearChannelManager(t *testing.T) {
 	// Create a channel manager
@@ -183,25 +99,25 @@ func TestClearChannelManager(t *testing.T) {
 	// Channel Manager state should be empty by default
 	require.Empty(t, m.blocks)
 	require.Equal(t, common.Hash{}, m.tip)
-	require.Nil(t, m.pendingChannel)
-	require.Empty(t, m.pendingTransactions)
-	require.Empty(t, m.confirmedTransactions)
+	require.Nil(t, m.currentChannel)
+	require.Empty(t, m.channelQueue)
+	require.Empty(t, m.txChannels)
 
 	// Add a block to the channel manager
 	a, _ := derivetest.RandomL2Block(rng, 4)
 	newL1Tip := a.Hash()
-	l1BlockID := eth.BlockID{
+	l1BlockRef := eth.L1BlockRef{
 		Hash:   a.Hash(),
 		Number: a.NumberU64(),
 	}
 	err := m.AddL2Block(a)
 	require.NoError(t, err)
 
-	// Make sure there is a channel builder
-	err = m.ensurePendingChannel(l1BlockID)
+	// Make sure there is a channel
+	err = m.ensureChannelWithSpace(l1BlockRef)
 	require.NoError(t, err)
-	require.NotNil(t, m.pendingChannel)
-	require.Equal(t, 0, len(m.confirmedTransactions))
+	require.NotNil(t, m.currentChannel)
+	require.Equal(t, 0, len(m.currentChannel.confirmedTransactions))
 
 	// Process the blocks
 	// We should have a pending channel with 1 frame
@@ -209,13 +125,14 @@ func TestClearChannelManager(t *testing.T) {
 	// the list
 	err = m.processBlocks()
 	require.NoError(t, err)
-	err = m.pendingChannel.OutputFrames()
-	require.NoError(t, err)
-	_, err = m.nextTxData()
+	err = m.currentChannel.builder.OutputFrames()
 	require.NoError(t, err)
+	require.True(t, m.currentChannel.HasFrame())
+	m.nextTxData(m.currentChannel)
 	require.Equal(t, 0, len(m.blocks))
 	require.Equal(t, newL1Tip, m.tip)
-	require.Equal(t, 1, len(m.pendingTransactions))
+	require.Equal(t, 1, len(m.currentChannel.pendingTransactions))
+	require.Equal(t, 1, len(m.txChannels))
 
 	// Add a new block so we can test clearing
 	// the channel manager with a full state
@@ -234,9 +151,9 @@ func TestClearChannelManager(t *testing.T) {
 	// Check that the entire channel manager state cleared
 	require.Empty(t, m.blocks)
 	require.Equal(t, common.Hash{}, m.tip)
-	require.Nil(t, m.pendingChannel)
-	require.Empty(t, m.pendingTransactions)
-	require.Empty(t, m.confirmedTransactions)
+	require.Nil(t, m.currentChannel)
+	require.Empty(t, m.channelQueue)
+	require.Empty(t, m.txChannels)
 }
 
 // TestChannelManagerTxConfirmed checks the [ChannelManager.TxConfirmed] function.
@@ -252,9 +169,10 @@ func TestChannelManagerTxConfirmed(t *testing.T) {
 
 	// Let's add a valid pending transaction to the channel manager
 	// So we can demonstrate that TxConfirmed's correctness
-	err := m.ensurePendingChannel(eth.BlockID{})
+	err := m.ensureChannelWithSpace(eth.L1BlockRef{})
 	require.NoError(t, err)
-	channelID := m.pendingChannel.ID()
+	ch := m.currentChannel
+	channelID := ch.ID()
 	frame := frameData{
 		data: []byte{},
 		id: frameID{
@@ -262,35 +180,36 @@ func TestChannelManagerTxConfirmed(t *testing.T) {
 			frameNumber: uint16(0),
 		},
 	}
-	m.pendingChannel.PushFrame(frame)
-	require.Equal(t, 1, m.pendingChannel.NumFrames())
-	returnedTxData, err := m.nextTxData()
+	ch.builder.PushFrame(frame)
+	require.Equal(t, 1, ch.builder.NumFrames())
+	returnedTxData := m.nextTxData(ch)
 	expectedTxData := txData{frame}
 	expectedChannelID := expectedTxData.ID()
-	require.NoError(t, err)
 	require.Equal(t, expectedTxData, returnedTxData)
-	require.Equal(t, 0, m.pendingChannel.NumFrames())
-	require.Equal(t, expectedTxData, m.pendingTransactions[expectedChannelID])
-	require.Equal(t, 1, len(m.pendingTransactions))
+	require.Equal(t, 0, ch.builder.NumFrames())
+	require.Equal(t, expectedTxData, ch.pendingTransactions[expectedChannelID])
+	require.Equal(t, 1, len(ch.pendingTransactions))
+	require.Equal(t, ch, m.txChannels[expectedChannelID])
 
 	// An unknown pending transaction should not be marked as confirmed
 	// and should not be removed from the pending transactions map
-	actualChannelID := m.pendingChannel.ID()
+	actualChannelID := ch.ID()
 	unknownChannelID := derive.ChannelID([derive.ChannelIDLength]byte{0x69})
 	require.NotEqual(t, actualChannelID, unknownChannelID)
 	unknownTxID := frameID{chID: unknownChannelID, frameNumber: 0}
 	blockID := eth.BlockID{Number: 0, Hash: common.Hash{0x69}}
 	m.TxConfirmed(unknownTxID, blockID)
-	require.Empty(t, m.confirmedTransactions)
-	require.Equal(t, 1, len(m.pendingTransactions))
+	require.Empty(t, ch.confirmedTransactions)
+	require.Equal(t, 1, len(ch.pendingTransactions))
 
 	// Now let's mark the pending transaction as confirmed
 	// and check that it is removed from the pending transactions map
 	// and added to the confirmed transactions map
 	m.TxConfirmed(expectedChannelID, blockID)
-	require.Empty(t, m.pendingTransactions)
-	require.Equal(t, 1, len(m.confirmedTransactions))
-	require.Equal(t, blockID, m.confirmedTransactions[expectedChannelID])
+	require.Empty(t, ch.pendingTransactions)
+	require.Empty(t, m.txChannels)
+	require.Equal(t, 1, len(ch.confirmedTransactions))
+	require.Equal(t, blockID, ch.confirmedTransactions[expectedChannelID])
 }
 
 // TestChannelManagerTxFailed checks the [ChannelManager.TxFailed] function.
@@ -301,9 +220,10 @@ func TestChannelManagerTxFailed(t *testing.T) {
 
 	// Let's add a valid pending transaction to the channel
 	// manager so we can demonstrate correctness
-	err := m.ensurePendingChannel(eth.BlockID{})
+	err := m.ensureChannelWithSpace(eth.L1BlockRef{})
 	require.NoError(t, err)
-	channelID := m.pendingChannel.ID()
+	ch := m.currentChannel
+	channelID := ch.ID()
 	frame := frameData{
 		data: []byte{},
 		id: frameID{
@@ -311,29 +231,30 @@ func TestChannelManagerTxFailed(t *testing.T) {
 			frameNumber: uint16(0),
 		},
 	}
-	m.pendingChannel.PushFrame(frame)
-	require.Equal(t, 1, m.pendingChannel.NumFrames())
-	returnedTxData, err := m.nextTxData()
+	ch.builder.PushFrame(frame)
+	require.Equal(t, 1, ch.builder.NumFrames())
+	returnedTxData := m.nextTxData(ch)
 	expectedTxData := txData{frame}
 	expectedChannelID := expectedTxData.ID()
-	require.NoError(t, err)
 	require.Equal(t, expectedTxData, returnedTxData)
-	require.Equal(t, 0, m.pendingChannel.NumFrames())
-	require.Equal(t, expectedTxData, m.pendingTransactions[expectedChannelID])
-	require.Equal(t, 1, len(m.pendingTransactions))
+	require.Equal(t, 0, ch.builder.NumFrames())
+	require.Equal(t, expectedTxData, ch.pendingTransactions[expectedChannelID])
+	require.Equal(t, 1, len(ch.pendingTransactions))
+	require.Equal(t, ch, m.txChannels[expectedChannelID])
 
 	// Trying to mark an unknown pending transaction as failed
 	// shouldn't modify state
 	m.TxFailed(frameID{})
-	require.Equal(t, 0, m.pendingChannel.NumFrames())
-	require.Equal(t, expectedTxData, m.pendingTransactions[expectedChannelID])
+	require.Equal(t, 0, ch.builder.NumFrames())
+	require.Equal(t, expectedTxData, ch.pendingTransactions[expectedChannelID])
 
 	// Now we still have a pending transaction
 	// Let's mark it as failed
 	m.TxFailed(expectedChannelID)
-	require.Empty(t, m.pendingTransactions)
+	require.Empty(t, ch.pendingTransactions)
+	require.Empty(t, m.txChannels)
 	// There should be a frame in the pending channel now
-	require.Equal(t, 1, m.pendingChannel.NumFrames())
+	require.Equal(t, 1, ch.builder.NumFrames())
 }
 
 func TestChannelManager_TxResend(t *testing.T) {
@@ -352,7 +273,7 @@ func TestChannelManager_TxResend(t *testing.T) {
 	err := m.AddL2Block(a)
 	require.NoError(err)
 
-	txdata0, err := m.TxData(eth.BlockID{})
+	txdata0, err := m.TxData(eth.L1BlockRef{})
 	require.NoError(err)
 	txdata0bytes := txdata0.Bytes()
 	data0 := make([]byte, len(txdata0bytes))
@@ -360,13 +281,13 @@ func TestChannelManager_TxResend(t *testing.T) {
 	copy(data0, txdata0bytes)
 
 	// ensure channel is drained
-	_, err = m.TxData(eth.BlockID{})
+	_, err = m.TxData(eth.L1BlockRef{})
 	require.ErrorIs(err, io.EOF)
 
 	// requeue frame
 	m.TxFailed(txdata0.ID())
 
-	txdata1, err := m.TxData(eth.BlockID{})
+	txdata1, err := m.TxData(eth.L1BlockRef{})
 	require.NoError(err)
 
 	data1 := txdata1.Bytes()
@@ -375,3 +296,309 @@ func TestChannelManager_TxResend(t *testing.T) {
 	require.NoError(err)
 	require.Len(fs, 1)
 }
+
+// TestChannelManagerForceCloseChannel checks that [ChannelManager.ForceCloseChannel]
+// closes the current channel and outputs all of its frames.
+func TestChannelManagerForceCloseChannel(t *testing.T) {
+	require := require.New(t)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	log := testlog.Logger(t, log.LvlCrit)
+	m := NewChannelManager(log, metrics.NoopMetrics,
+		ChannelConfig{
+			MaxFrameSize:     120_000,
+			TargetFrameSize:  120_000,
+			TargetNumFrames:  1,
+			ApproxComprRatio: 1.0,
+		})
+
+	// Nothing to close without blocks
+	_, err := m.ForceCloseChannel(eth.L1BlockRef{})
+	require.ErrorIs(err, ErrNothingToClose)
+
+	a, _ := derivetest.RandomL2Block(rng, 4)
+	require.NoError(m.AddL2Block(a))
+	state := m.PendingState()
+	require.Equal(1, state.BlocksPending)
+	require.Empty(state.Channels)
+
+	// The block is too small to fill the channel, so no frame is output yet
+	_, err = m.TxData(eth.L1BlockRef{})
+	require.ErrorIs(err, io.EOF)
+	state = m.PendingState()
+	require.Equal(0, state.BlocksPending)
+	require.Len(state.Channels, 1)
+	require.False(state.Channels[0].Full)
+	require.Equal(1, state.Channels[0].Blocks)
+	require.Equal(0, state.Channels[0].FramesPending)
+
+	id, err := m.ForceCloseChannel(eth.L1BlockRef{})
+	require.NoError(err)
+	require.Equal(m.currentChannel.ID(), id)
+	state = m.PendingState()
+	require.True(state.Channels[0].Full)
+	require.ErrorIs(m.currentChannel.builder.FullErr(), ErrForceClosed)
+	require.Equal(1, state.Channels[0].FramesPending)
+
+	// Nothing left to close
+	_, err = m.ForceCloseChannel(eth.L1BlockRef{})
+	require.ErrorIs(err, ErrNothingToClose)
+	require.Equal(1, m.PendingState().Channels[0].FramesPending)
+
+	txdata, err := m.TxData(eth.L1BlockRef{})
+	require.NoError(err)
+	require.Equal(id, txdata.ID().chID)
+	state = m.PendingState()
+	require.Equal(0, state.Channels[0].FramesPending)
+	require.Equal(1, state.Channels[0].FramesInFlight)
+}
+
+// TestChannelManagerMultipleChannels checks that a new channel is opened while
+// the frames of a full channel are still pending, that a fully submitted channel
+// is removed once the channels before it are, and that a timed out channel requeues
+// the blocks of the later channels too.
+func TestChannelManagerMultipleChannels(t *testing.T) {
+	require := require.New(t)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	log := testlog.Logger(t, log.LvlCrit)
+	m := NewChannelManager(log, metrics.NoopMetrics,
+		ChannelConfig{
+			// A target of 0 fills a channel with a single block
+			TargetFrameSize:  0,
+			MaxFrameSize:     120_000,
+			ApproxComprRatio: 1.0,
+			ChannelTimeout:   10,
+		})
+
+	a, _ := derivetest.RandomL2Block(rng, 4)
+	require.NoError(m.AddL2Block(a))
+
+	// The first channel is full with two frames in flight
+	require.NoError(m.ensureChannelWithSpace(eth.L1BlockRef{}))
+	require.NoError(m.processBlocks())
+	first := m.currentChannel
+	require.True(first.builder.IsFull())
+	for i := 0; i < 2; i++ {
+		first.builder.PushFrame(frameData{data: []byte{}, id: frameID{chID: first.ID(), frameNumber: uint16(i)}})
+	}
+	tx0 := m.nextTxData(first)
+	tx1 := m.nextTxData(first)
+
+	// The next block goes into a new channel without waiting for the first one
+	b := newMiniL2BlockWithNumberParent(0, big.NewInt(1), a.Hash())
+	require.NoError(m.AddL2Block(b))
+	tx2, err := m.TxData(eth.L1BlockRef{Number: 1})
+	require.NoError(err)
+	second := m.currentChannel
+	require.NotEqual(first.ID(), second.ID())
+	require.Equal(second.ID(), tx2.ID().chID)
+	require.Equal([]*channel{first, second}, m.channelQueue)
+	require.Len(m.PendingState().Channels, 2)
+
+	// The second channel is fully submitted, but stays queued behind the first one
+	m.TxConfirmed(tx2.ID(), eth.BlockID{Number: 2})
+	require.True(second.isFullySubmitted())
+	require.Equal([]*channel{first, second}, m.channelQueue)
+
+	// The first channel times out, so its block and the block of the second channel are queued again
+	m.TxConfirmed(tx0.ID(), eth.BlockID{Number: 2})
+	require.Equal([]*channel{first, second}, m.channelQueue)
+	m.TxConfirmed(tx1.ID(), eth.BlockID{Number: 12})
+	require.Empty(m.channelQueue)
+	require.Empty(m.txChannels)
+	require.Nil(m.currentChannel)
+	require.Equal([]*types.Block{a, b}, m.blocks)
+}
+
+// TestChannelManagerSubmittedInOrder checks that the fully submitted channels are
+// removed once the channels before them are fully submitted.
+func TestChannelManagerSubmittedInOrder(t *testing.T) {
+	require := require.New(t)
+	log := testlog.Logger(t, log.LvlCrit)
+	m := NewChannelManager(log, metrics.NoopMetrics,
+		ChannelConfig{
+			TargetFrameSize:  0,
+			MaxFrameSize:     120_000,
+			ApproxComprRatio: 1.0,
+			ChannelTimeout:   10,
+		})
+	blocks := newMiniL2Chain(2, 2)
+
+	var txs []txData
+	for _, block := range blocks {
+		require.NoError(m.AddL2Block(block))
+		txdata, err := m.TxData(eth.L1BlockRef{Number: 1})
+		require.NoError(err)
+		txs = append(txs, txdata)
+	}
+	require.Len(m.channelQueue, 2)
+
+	m.TxConfirmed(txs[1].ID(), eth.BlockID{Number: 2})
+	require.Len(m.channelQueue, 2)
+	m.TxConfirmed(txs[0].ID(), eth.BlockID{Number: 3})
+	require.Empty(m.channelQueue)
+	require.Empty(m.blocks)
+}
+
+// TestChannelManagerTimeoutRequeue checks that the blocks of a timed out channel
+// are not added to the current channel, which already holds later blocks, but
+// start a new channel.
+func TestChannelManagerTimeoutRequeue(t *testing.T) {
+	for _, useSpan := range []bool{false, true} {
+		useSpan := useSpan
+		t.Run(fmt.Sprintf("span=%t", useSpan), func(t *testing.T) {
+			require := require.New(t)
+			log := testlog.Logger(t, log.LvlCrit)
+			cfg := ChannelConfig{
+				MaxFrameSize:     120_000,
+				TargetFrameSize:  120_000,
+				TargetNumFrames:  1,
+				ApproxComprRatio: 1.0,
+				ChannelTimeout:   10,
+				BlockTime:        2,
+			}
+			if useSpan {
+				cfg.SpanForkTime = new(uint64)
+			}
+			m := NewChannelManager(log, metrics.NoopMetrics, cfg)
+			blocks := newMiniL2Chain(4, cfg.BlockTime)
+
+			// The first channel is closed with two frames in flight
+			require.NoError(m.AddL2Block(blocks[0]))
+			require.NoError(m.ensureChannelWithSpace(eth.L1BlockRef{}))
+			require.NoError(m.processBlocks())
+			first := m.currentChannel
+			first.builder.setFullErr(ErrForceClosed)
+			for i := 0; i < 2; i++ {
+				first.builder.PushFrame(frameData{data: []byte{}, id: frameID{chID: first.ID(), frameNumber: uint16(i)}})
+			}
+			tx0 := m.nextTxData(first)
+			tx1 := m.nextTxData(first)
+
+			// The later blocks are added to the open current channel
+			require.NoError(m.AddL2Block(blocks[1]))
+			require.NoError(m.AddL2Block(blocks[2]))
+			_, err := m.TxData(eth.L1BlockRef{Number: 1})
+			require.ErrorIs(err, io.EOF)
+			second := m.currentChannel
+			require.NotEqual(first, second)
+			require.False(second.builder.IsFull())
+
+			// The first channel times out, which closes the current channel and requeues its blocks
+			m.TxConfirmed(tx0.ID(), eth.BlockID{Number: 2})
+			m.TxConfirmed(tx1.ID(), eth.BlockID{Number: 12})
+			require.Equal(blocks[:3], m.blocks)
+			for i := 1; i < len(m.blocks); i++ {
+				require.Equal(m.blocks[i-1].Hash(), m.blocks[i].ParentHash(), "requeued blocks must be contiguous")
+			}
+			require.ErrorIs(second.builder.FullErr(), ErrRequeuedBlocks)
+			require.Empty(m.channelQueue)
+			require.Nil(m.currentChannel)
+
+			// The requeued blocks start a new channel, followed by the next block
+			require.NoError(m.AddL2Block(blocks[3]))
+			for {
+				_, err := m.TxData(eth.L1BlockRef{Number: 13})
+				if err == io.EOF {
+					break
+				}
+				require.NoError(err)
+			}
+			var channelBlocks [][]*types.Block
+			for _, ch := range m.channelQueue {
+				channelBlocks = append(channelBlocks, ch.Blocks())
+			}
+			require.Equal([][]*types.Block{blocks}, channelBlocks)
+		})
+	}
+}
+
+// TestChannelManagerAdaptiveComprRatio checks that the channels are opened with
+// the compression ratio estimated from the previously closed channels.
+func TestChannelManagerAdaptiveComprRatio(t *testing.T) {
+	require := require.New(t)
+	log := testlog.Logger(t, log.LvlCrit)
+	m := NewChannelManager(log, metrics.NoopMetrics,
+		ChannelConfig{
+			TargetFrameSize:    1000,
+			TargetNumFrames:    1,
+			MaxFrameSize:       120_000,
+			ApproxComprRatio:   1.0,
+			AdaptiveComprRatio: true,
+			ChannelTimeout:     10,
+		})
+
+	parent := common.Hash{}
+	for i := 0; i < 100; i++ {
+		block := newMiniL2BlockWithNumberParent(10, big.NewInt(int64(i)), parent)
+		require.NoError(m.AddL2Block(block))
+		parent = block.Hash()
+	}
+
+	_, err := m.TxData(eth.L1BlockRef{})
+	require.NoError(err)
+	first := m.currentChannel
+	require.ErrorIs(first.builder.FullErr(), ErrInputTargetReached)
+	require.Equal(1.0, first.cfg.ApproxComprRatio)
+
+	// The mini blocks compress well, so the next channel takes more input
+	ratio := m.comprEstimator.Ratio()
+	require.Less(ratio, 1.0)
+	require.NoError(m.ensureChannelWithSpace(eth.L1BlockRef{}))
+	second := m.currentChannel
+	require.NotEqual(first.ID(), second.ID())
+	require.Equal(ratio, second.cfg.ApproxComprRatio)
+	require.Greater(second.cfg.InputThreshold(), first.cfg.InputThreshold())
+}
+
+// TestChannelManagerJournal checks that closed channels are journaled with
+// their frame transactions, and that a channel restored from the journal
+// submits the same frames.
+func TestChannelManagerJournal(t *testing.T) {
+	require := require.New(t)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	log := testlog.Logger(t, log.LvlCrit)
+	cfg := ChannelConfig{
+		TargetFrameSize:  0,
+		MaxFrameSize:     120_000,
+		ApproxComprRatio: 1.0,
+	}
+	j := journal.NewMemoryJournal()
+	m := NewChannelManager(log, metrics.NoopMetrics, cfg)
+	m.journal = j
+
+	a, _ := derivetest.RandomL2Block(rng, 4)
+	require.NoError(m.AddL2Block(a))
+	txdata, err := m.TxData(eth.L1BlockRef{})
+	require.NoError(err)
+	m.TxSent(txdata.ID(), common.Hash{1})
+
+	records, err := j.Channels()
+	require.NoError(err)
+	require.Len(records, 1)
+	record := records[0]
+	require.Equal(txdata.ID().chID, record.ID)
+	require.Equal(eth.ToBlockID(a), record.FirstBlock)
+	require.Equal(eth.ToBlockID(a), record.LastBlock)
+	require.Len(record.Frames, 1)
+	txs, err := j.Txs(record.ID)
+	require.NoError(err)
+	require.Equal(common.Hash{1}, txs[0].Hash)
+
+	// A restarted channel manager submits the same frame again
+	frames := []frameData{{id: txdata.ID(), data: record.Frames[0].Data}}
+	ch, err := restoreChannel(log, metrics.NoopMetrics, cfg, record.ID, []*types.Block{a}, frames, make(map[txID]eth.BlockID))
+	require.NoError(err)
+	restored := NewChannelManager(log, metrics.NoopMetrics, cfg)
+	restored.journal = j
+	restored.restore([]*channel{ch}, a.Hash())
+	resent, err := restored.TxData(eth.L1BlockRef{})
+	require.NoError(err)
+	require.Equal(txdata.Bytes(), resent.Bytes())
+	require.ErrorIs(restored.AddL2Block(newMiniL2Block(0)), ErrReorg)
+
+	// The channel is deleted from the journal once fully submitted
+	restored.TxConfirmed(resent.ID(), eth.BlockID{Number: 1})
+	records, err = j.Channels()
+	require.NoError(err)
+	require.Empty(records)
+}
@@ -0,0 +1,75 @@
+package batcher
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+)
+
+// TestChannelTimeout tests that a channel correctly identifies when it is timed out.
+func TestChannelTimeout(t *testing.T) {
+	// Create a new channel with a ChannelTimeout
+	log := testlog.Logger(t, log.LvlCrit)
+	ch, err := newChannel(log, metrics.NoopMetrics, ChannelConfig{
+		ChannelTimeout: 100,
+	}, 0)
+	require.NoError(t, err)
+
+	// There are no confirmed transactions so
+	// the channel cannot be timed out
+	require.False(t, ch.isTimedOut())
+
+	// Manually set a confirmed transactions
+	// To avoid other methods clearing state
+	ch.confirmedTransactions[frameID{frameNumber: 0}] = eth.BlockID{Number: 0}
+	ch.confirmedTransactions[frameID{frameNumber: 1}] = eth.BlockID{Number: 99}
+
+	// Since the ChannelTimeout is 100, the
+	// channel should not be timed out
+	require.False(t, ch.isTimedOut())
+
+	// Add a confirmed transaction with a higher number
+	// than the ChannelTimeout
+	ch.confirmedTransactions[frameID{
+		frameNumber: 2,
+	}] = eth.BlockID{
+		Number: 101,
+	}
+
+	// Now the channel should be timed out
+	require.True(t, ch.isTimedOut())
+}
+
+// TestChannelNextTxData checks the NextTxData function.
+func TestChannelNextTxData(t *testing.T) {
+	log := testlog.Logger(t, log.LvlCrit)
+	ch, err := newChannel(log, metrics.NoopMetrics, ChannelConfig{}, 0)
+	require.NoError(t, err)
+
+	// The channel has no frames yet
+	require.False(t, ch.HasFrame())
+
+	// Manually push a frame into the channel
+	frame := frameData{
+		data: []byte{},
+		id: frameID{
+			chID:        ch.ID(),
+			frameNumber: uint16(0),
+		},
+	}
+	ch.builder.PushFrame(frame)
+	require.True(t, ch.HasFrame())
+
+	// Now the NextTxData function should return the frame
+	returnedTxData := ch.NextTxData()
+	expectedTxData := txData{frame}
+	expectedChannelID := expectedTxData.ID()
+	require.Equal(t, expectedTxData, returnedTxData)
+	require.False(t, ch.HasFrame())
+	require.Equal(t, expectedTxData, ch.pendingTransactions[expectedChannelID])
+}
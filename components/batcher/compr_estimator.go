@@ -0,0 +1,47 @@
+package batcher
+
+import (
+	"errors"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+// comprRatioSmoothing is the weight of the latest realized compression ratio in
+// the estimate. The estimate follows changes of the ratio within a few channels.
+const comprRatioSmoothing = 0.3
+
+// comprRatioEstimator estimates the compression ratio of the next channel from
+// the realized compression ratios of the recently closed channels, as an
+// exponential moving average starting at the configured approximate ratio.
+//
+// Only the channels which got full because of their amount of input data are
+// taken into account. The channels closed early, e.g. by a timeout, are
+// smaller and usually compress worse than full ones.
+type comprRatioEstimator struct {
+	ratio float64
+}
+
+func newComprRatioEstimator(approxComprRatio float64) *comprRatioEstimator {
+	return &comprRatioEstimator{ratio: approxComprRatio}
+}
+
+// Ratio returns the estimated compression ratio.
+func (e *comprRatioEstimator) Ratio() float64 {
+	return e.ratio
+}
+
+// ChannelClosed updates the estimate with the realized compression ratio of a
+// closed channel. The output bytes include the frame overhead, so that the
+// input threshold derived from the estimate targets the number of frames. It
+// returns whether the estimate got updated.
+func (e *comprRatioEstimator) ChannelClosed(fullErr error, inputBytes, outputBytes int) bool {
+	if inputBytes == 0 || outputBytes == 0 {
+		return false
+	}
+	if !errors.Is(fullErr, ErrInputTargetReached) && !errors.Is(fullErr, derive.ErrTooManyRLPBytes) {
+		return false
+	}
+	ratio := float64(outputBytes) / float64(inputBytes)
+	e.ratio = comprRatioSmoothing*ratio + (1-comprRatioSmoothing)*e.ratio
+	return true
+}
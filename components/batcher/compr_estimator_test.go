@@ -0,0 +1,30 @@
+package batcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+func TestComprRatioEstimator(t *testing.T) {
+	e := newComprRatioEstimator(0.5)
+	require.Equal(t, 0.5, e.Ratio())
+
+	// Channels closed early and empty channels are ignored
+	require.False(t, e.ChannelClosed(&ChannelFullError{Err: ErrMaxDurationReached}, 1000, 900))
+	require.False(t, e.ChannelClosed(&ChannelFullError{Err: ErrInputTargetReached}, 0, 0))
+	require.Equal(t, 0.5, e.Ratio())
+
+	require.True(t, e.ChannelClosed(&ChannelFullError{Err: ErrInputTargetReached}, 1000, 400))
+	require.InDelta(t, 0.47, e.Ratio(), 1e-9)
+	require.True(t, e.ChannelClosed(&ChannelFullError{Err: derive.ErrTooManyRLPBytes}, 1000, 400))
+	require.InDelta(t, 0.449, e.Ratio(), 1e-9)
+
+	// The estimate converges to the realized ratio
+	for i := 0; i < 100; i++ {
+		e.ChannelClosed(&ChannelFullError{Err: ErrInputTargetReached}, 1000, 400)
+	}
+	require.InDelta(t, 0.4, e.Ratio(), 1e-6)
+}
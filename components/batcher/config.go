@@ -3,17 +3,22 @@ package batcher
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/urfave/cli"
 
 	"github.com/wemixkanvas/kanvas/components/batcher/flags"
+	"github.com/wemixkanvas/kanvas/components/batcher/journal"
 	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
 	"github.com/wemixkanvas/kanvas/components/batcher/rpc"
+	"github.com/wemixkanvas/kanvas/components/node/da"
 	"github.com/wemixkanvas/kanvas/components/node/rollup"
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 	"github.com/wemixkanvas/kanvas/components/node/sources"
 	"github.com/wemixkanvas/kanvas/utils"
 	kcrypto "github.com/wemixkanvas/kanvas/utils/service/crypto"
@@ -34,8 +39,23 @@ type Config struct {
 	PollInterval time.Duration
 	From         common.Address
 
+	// MaxPendingTxs is the maximum number of frame transactions in flight at once.
+	// If 0, the number is not limited.
+	MaxPendingTxs uint64
+
+	// MaxL1BaseFee is the L1 base fee above which the frames are held back,
+	// unless they are urgent. If nil, the frames are never held back.
+	MaxL1BaseFee *big.Int
+
 	TxManagerConfig txmgr.Config
 
+	// DAClient stores the batch data in the external DA server once the external
+	// DA fork is active. If nil, the batch data is always posted to L1.
+	DAClient *da.Client
+
+	// Journal persists the channels and frame transactions across restarts.
+	Journal *journal.Journal
+
 	// RollupConfig is queried at startup
 	Rollup *rollup.Config
 
@@ -126,6 +146,31 @@ type CLIConfig struct {
 	// compression algorithm.
 	ApproxComprRatio float64
 
+	// AdaptiveComprRatio is whether to estimate the compression ratio from
+	// the recent channels, starting at ApproxComprRatio.
+	AdaptiveComprRatio bool
+
+	// CompressionAlgo is the name of the algorithm to compress the channels
+	// with once the compression fork is active.
+	CompressionAlgo string
+
+	// MaxPendingTxs is the maximum number of frame transactions to keep in
+	// flight at once. Their nonces are assigned sequentially.
+	// If 0, the number is not limited.
+	MaxPendingTxs uint64
+
+	// MaxL1BaseFeeGwei is the L1 base fee in gwei above which the frames are
+	// held back, unless they are close to the channel timeout or the end of
+	// the proposing window. If 0, the frames are never held back.
+	MaxL1BaseFeeGwei uint64
+
+	// DARpc is the HTTP endpoint of the external DA server. If empty, the batch
+	// data is always posted to L1.
+	DARpc string
+
+	// DBPath is the path of the database to journal the channels and frame transactions.
+	DBPath string
+
 	LogConfig klog.CLIConfig
 
 	MetricsConfig kmetrics.CLIConfig
@@ -152,6 +197,9 @@ func (c CLIConfig) Check() error {
 	if err := c.SignerConfig.Check(); err != nil {
 		return err
 	}
+	if _, err := derive.ParseCompressionAlgo(c.CompressionAlgo); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -174,6 +222,12 @@ func NewCLIConfig(ctx *cli.Context) CLIConfig {
 		TargetL1TxSize:     ctx.GlobalUint64(flags.TargetL1TxSizeBytesFlag.Name),
 		TargetNumFrames:    ctx.GlobalInt(flags.TargetNumFramesFlag.Name),
 		ApproxComprRatio:   ctx.GlobalFloat64(flags.ApproxComprRatioFlag.Name),
+		AdaptiveComprRatio: ctx.GlobalBool(flags.AdaptiveComprRatioFlag.Name),
+		CompressionAlgo:    ctx.GlobalString(flags.CompressionAlgoFlag.Name),
+		MaxPendingTxs:      ctx.GlobalUint64(flags.MaxPendingTxsFlag.Name),
+		MaxL1BaseFeeGwei:   ctx.GlobalUint64(flags.MaxL1BaseFeeGweiFlag.Name),
+		DARpc:              ctx.GlobalString(flags.DARpcFlag.Name),
+		DBPath:             ctx.GlobalString(flags.DBPathFlag.Name),
 		Mnemonic:           ctx.GlobalString(flags.MnemonicFlag.Name),
 		HDPath:             ctx.GlobalString(flags.HDPathFlag.Name),
 		PrivateKey:         ctx.GlobalString(flags.PrivateKeyFlag.Name),
@@ -192,6 +246,16 @@ func NewBatcherConfig(cfg CLIConfig, l log.Logger, m metrics.Metricer) (*Config,
 		return nil, err
 	}
 
+	compressionAlgo, err := derive.ParseCompressionAlgo(cfg.CompressionAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := journal.Open(cfg.DBPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Connect to L1 and L2 providers. Perform these last since they are the most expensive.
 	ctx := context.Background()
 	l1Client, err := utils.DialEthClientWithTimeout(ctx, cfg.L1EthRpc)
@@ -223,6 +287,16 @@ func NewBatcherConfig(cfg CLIConfig, l log.Logger, m metrics.Metricer) (*Config,
 		Signer:                    signer(rcfg.L1ChainID),
 	}
 
+	var daClient *da.Client
+	if cfg.DARpc != "" {
+		daClient = da.NewClient(cfg.DARpc)
+	}
+
+	var maxL1BaseFee *big.Int
+	if cfg.MaxL1BaseFeeGwei > 0 {
+		maxL1BaseFee = new(big.Int).Mul(new(big.Int).SetUint64(cfg.MaxL1BaseFeeGwei), big.NewInt(params.GWei))
+	}
+
 	return &Config{
 		log:             l,
 		metr:            m,
@@ -230,18 +304,27 @@ func NewBatcherConfig(cfg CLIConfig, l log.Logger, m metrics.Metricer) (*Config,
 		L2Client:        l2Client,
 		RollupClient:    rollupClient,
 		PollInterval:    cfg.PollInterval,
+		MaxPendingTxs:   cfg.MaxPendingTxs,
+		MaxL1BaseFee:    maxL1BaseFee,
 		TxManagerConfig: txMgrCfg,
+		DAClient:        daClient,
+		Journal:         j,
 		From:            fromAddress,
 		Rollup:          rcfg,
 		Channel: ChannelConfig{
 			ProposerWindowSize: rcfg.ProposerWindowSize,
 			ChannelTimeout:     rcfg.ChannelTimeout,
+			ComprForkTime:      rcfg.CompressionForkTime,
+			SpanForkTime:       rcfg.SpanBatchForkTime,
+			BlockTime:          rcfg.BlockTime,
 			MaxChannelDuration: cfg.MaxChannelDuration,
 			SubSafetyMargin:    cfg.SubSafetyMargin,
 			MaxFrameSize:       cfg.MaxL1TxSize - 1,    // subtract 1 byte for version
 			TargetFrameSize:    cfg.TargetL1TxSize - 1, // subtract 1 byte for version
 			TargetNumFrames:    cfg.TargetNumFrames,
 			ApproxComprRatio:   cfg.ApproxComprRatio,
+			AdaptiveComprRatio: cfg.AdaptiveComprRatio,
+			CompressionAlgo:    compressionAlgo,
 		},
 	}, nil
 }
@@ -0,0 +1,78 @@
+package batcher
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+)
+
+// feeScheduler holds back the frames which are not urgent while the L1 base fee
+// is above the configured ceiling. The frames of the channels which are partially
+// submitted or close to their proposing window or channel timeout are always
+// submitted, see channel.isUrgent.
+//
+// It's only used by the submission loop, so it's not safe for concurrent access.
+type feeScheduler struct {
+	log  log.Logger
+	metr metrics.Metricer
+
+	// base fee ceiling above which frames are held back, nil if disabled
+	maxBaseFee *big.Int
+	// whether frames are held back at the latest base fee
+	holding bool
+	// base fee at which frames got held back first, nil if the held back
+	// frames are submitted already. It's used to estimate the fees avoided.
+	heldBaseFee *big.Int
+}
+
+func newFeeScheduler(log log.Logger, metr metrics.Metricer, maxBaseFee *big.Int) *feeScheduler {
+	return &feeScheduler{
+		log:        log,
+		metr:       metr,
+		maxBaseFee: maxBaseFee,
+	}
+}
+
+// TxData returns the next tx data of the channel manager to submit at the given
+// L1 head with the given base fee. It returns io.EOF if there's no pending frame
+// or if the pending frames are held back.
+func (s *feeScheduler) TxData(state *channelManager, l1Head eth.L1BlockRef, baseFee *big.Int) (txData, error) {
+	s.holding = s.maxBaseFee != nil && baseFee != nil && baseFee.Cmp(s.maxBaseFee) > 0
+	if !s.holding {
+		txdata, err := state.TxData(l1Head)
+		if err == io.EOF && s.heldBaseFee != nil {
+			s.log.Info("Submitted frames held back", "base_fee", baseFee, "held_base_fee", s.heldBaseFee)
+			s.heldBaseFee = nil
+		}
+		return txdata, err
+	}
+
+	txdata, err := state.UrgentTxData(l1Head)
+	if err == io.EOF && state.HasFrame() {
+		if s.heldBaseFee == nil {
+			s.log.Info("Holding back frames", "base_fee", baseFee, "max_base_fee", s.maxBaseFee)
+			s.heldBaseFee = baseFee
+		}
+		s.metr.RecordBatchTxHeld()
+	} else if err == nil {
+		s.log.Info("Submitting urgent frame despite base fee", "id", txdata.ID(), "base_fee", baseFee, "max_base_fee", s.maxBaseFee)
+	}
+	return txdata, err
+}
+
+// TxCreated records the base fee avoided by the frame transaction, which is
+// the difference to the base fee at which frames got held back first, if the
+// frame is submitted after frames got held back.
+func (s *feeScheduler) TxCreated(tx *types.Transaction, baseFee *big.Int) {
+	if s.holding || s.heldBaseFee == nil || baseFee == nil || baseFee.Cmp(s.heldBaseFee) >= 0 {
+		return
+	}
+	avoided := new(big.Int).Sub(s.heldBaseFee, baseFee)
+	avoided.Mul(avoided, new(big.Int).SetUint64(tx.Gas()))
+	s.metr.RecordL1BaseFeeAvoided(avoided)
+}
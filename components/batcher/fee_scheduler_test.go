@@ -0,0 +1,77 @@
+package batcher
+
+import (
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/batcher/metrics"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+)
+
+// TestFeeSchedulerHoldsBackFrames checks that the frames are held back while the
+// base fee is above the ceiling, unless their channel is close to its deadline.
+func TestFeeSchedulerHoldsBackFrames(t *testing.T) {
+	require := require.New(t)
+	log := testlog.Logger(t, log.LvlCrit)
+	m := NewChannelManager(log, metrics.NoopMetrics, defaultTestChannelConfig)
+	s := newFeeScheduler(log, metrics.NoopMetrics, big.NewInt(100))
+
+	parent := common.Hash{}
+	for i := 0; i < 2; i++ {
+		block := newMiniL2BlockWithNumberParent(0, big.NewInt(int64(i)), parent)
+		require.NoError(m.AddL2Block(block))
+		parent = block.Hash()
+	}
+	_, err := m.ForceCloseChannel(eth.L1BlockRef{Number: 100})
+	require.NoError(err)
+	deadline := m.currentChannel.builder.deadline
+
+	// Held back above the ceiling
+	_, err = s.TxData(m, eth.L1BlockRef{Number: 100}, big.NewInt(101))
+	require.ErrorIs(err, io.EOF)
+	require.True(m.HasFrame())
+	require.Equal(big.NewInt(101), s.heldBaseFee)
+
+	// Submitted at the deadline
+	txdata, err := s.TxData(m, eth.L1BlockRef{Number: deadline}, big.NewInt(101))
+	require.NoError(err)
+	require.Equal(m.currentChannel.ID(), txdata.ID().chID)
+	m.TxFailed(txdata.ID())
+
+	// Submitted at the ceiling
+	_, err = s.TxData(m, eth.L1BlockRef{Number: 100}, big.NewInt(100))
+	require.NoError(err)
+	require.False(m.HasFrame())
+	_, err = s.TxData(m, eth.L1BlockRef{Number: 100}, big.NewInt(100))
+	require.ErrorIs(err, io.EOF)
+	require.Nil(s.heldBaseFee)
+}
+
+// TestFeeSchedulerPartiallySubmitted checks that the remaining frames of a
+// partially submitted channel are not held back.
+func TestFeeSchedulerPartiallySubmitted(t *testing.T) {
+	require := require.New(t)
+	log := testlog.Logger(t, log.LvlCrit)
+	m := NewChannelManager(log, metrics.NoopMetrics, defaultTestChannelConfig)
+	s := newFeeScheduler(log, metrics.NoopMetrics, big.NewInt(100))
+
+	block := newMiniL2Block(0)
+	require.NoError(m.AddL2Block(block))
+	_, err := m.ForceCloseChannel(eth.L1BlockRef{Number: 100})
+	require.NoError(err)
+	ch := m.currentChannel
+	ch.builder.PushFrame(frameData{data: []byte{}, id: frameID{chID: ch.ID(), frameNumber: 1}})
+
+	txdata, err := s.TxData(m, eth.L1BlockRef{Number: 100}, big.NewInt(100))
+	require.NoError(err)
+	m.TxConfirmed(txdata.ID(), eth.BlockID{Number: 101})
+
+	_, err = s.TxData(m, eth.L1BlockRef{Number: 101}, big.NewInt(1000))
+	require.NoError(err)
+}
@@ -111,6 +111,39 @@ var (
 		Value:  1.0,
 		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "APPROX_COMPR_RATIO"),
 	}
+	AdaptiveComprRatioFlag = cli.BoolFlag{
+		Name:   "adaptive-compr-ratio",
+		Usage:  "Estimate the compression ratio from the recent channels, starting at the approximate compression ratio",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "ADAPTIVE_COMPR_RATIO"),
+	}
+	CompressionAlgoFlag = cli.StringFlag{
+		Name:   "compression-algo",
+		Usage:  "The algorithm to compress the channels with once the compression fork is active. Options: zlib, zstd",
+		Value:  "zlib",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "COMPRESSION_ALGO"),
+	}
+	MaxPendingTxsFlag = cli.Uint64Flag{
+		Name:   "max-pending-tx",
+		Usage:  "The maximum number of frame transactions to keep in flight at once. 0 for no limit.",
+		Value:  1,
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "MAX_PENDING_TX"),
+	}
+	MaxL1BaseFeeGweiFlag = cli.Uint64Flag{
+		Name:   "max-l1-base-fee-gwei",
+		Usage:  "The L1 base fee in gwei above which frames are held back unless close to their channel or proposing window timeout. 0 to disable.",
+		Value:  0,
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "MAX_L1_BASE_FEE_GWEI"),
+	}
+	DARpcFlag = cli.StringFlag{
+		Name:   "da-rpc",
+		Usage:  "HTTP endpoint of the external DA server to store the batch data in once the external DA fork is active. If empty, the batch data is always posted to L1.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "DA_RPC"),
+	}
+	DBPathFlag = cli.StringFlag{
+		Name:   "db.path",
+		Usage:  "Path of the LevelDB to journal the channels and frame transactions. If empty, the journal is kept in memory only.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "DB_PATH"),
+	}
 	MnemonicFlag = cli.StringFlag{
 		Name:   "mnemonic",
 		Usage:  "The mnemonic used to derive the wallets for the batcher",
@@ -147,6 +180,12 @@ var optionalFlags = []cli.Flag{
 	TargetL1TxSizeBytesFlag,
 	TargetNumFramesFlag,
 	ApproxComprRatioFlag,
+	AdaptiveComprRatioFlag,
+	CompressionAlgoFlag,
+	MaxPendingTxsFlag,
+	MaxL1BaseFeeGweiFlag,
+	DARpcFlag,
+	DBPathFlag,
 	MnemonicFlag,
 	HDPathFlag,
 	PrivateKeyFlag,
@@ -0,0 +1,227 @@
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+const (
+	dbCache   = 16 // MiB
+	dbHandles = 16
+)
+
+var (
+	channelPrefix = []byte("channel-")
+	txPrefix      = []byte("tx-")
+)
+
+// ChannelRecord is a closed channel, of which all frames are output.
+// Channels still open are not journaled, as their compression state can't be restored.
+type ChannelRecord struct {
+	ID derive.ChannelID `json:"id"`
+	// FirstBlock and LastBlock are the range of the L2 blocks added to the channel.
+	FirstBlock eth.BlockID    `json:"firstBlock"`
+	LastBlock  eth.BlockID    `json:"lastBlock"`
+	Frames     []*FrameRecord `json:"frames"`
+}
+
+// FrameRecord is a single frame of a channel. The data of the frames confirmed
+// before the channel got closed is omitted.
+type FrameRecord struct {
+	Number hexutil.Uint64 `json:"number"`
+	Data   hexutil.Bytes  `json:"data,omitempty"`
+}
+
+// TxRecord is the transaction of a single frame. The inclusion block is set once
+// the transaction is confirmed.
+type TxRecord struct {
+	Hash           common.Hash  `json:"hash"`
+	InclusionBlock *eth.BlockID `json:"inclusionBlock,omitempty"`
+}
+
+// Journal persists the channels and the frame transactions of the batcher, so that
+// a restarted batcher can continue submitting the channels instead of orphaning them.
+type Journal struct {
+	mu sync.Mutex
+	db ethdb.KeyValueStore
+}
+
+// Open opens a LevelDB backed journal at the given path.
+// If the path is empty, the journal is kept in memory only.
+func Open(path string) (*Journal, error) {
+	if path == "" {
+		return NewMemoryJournal(), nil
+	}
+
+	db, err := leveldb.New(path, dbCache, dbHandles, "batcher/db/", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batcher journal at %s: %w", path, err)
+	}
+
+	return NewJournal(db), nil
+}
+
+// NewMemoryJournal creates a journal which is not persisted to disk.
+func NewMemoryJournal() *Journal {
+	return NewJournal(memorydb.New())
+}
+
+func NewJournal(db ethdb.KeyValueStore) *Journal {
+	return &Journal{db: db}
+}
+
+func (j *Journal) Close() error {
+	return j.db.Close()
+}
+
+func (j *Journal) PutChannel(record *ChannelRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.putJSON(channelKey(record.ID), record)
+}
+
+// Channels returns all the journaled channels, ordered by their first L2 block.
+func (j *Journal) Channels() ([]*ChannelRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var records []*ChannelRecord
+	err := j.iterate(channelPrefix, func(_, value []byte) error {
+		var record ChannelRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		records = append(records, &record)
+		return nil
+	})
+	sort.Slice(records, func(i, k int) bool {
+		return records[i].FirstBlock.Number < records[k].FirstBlock.Number
+	})
+
+	return records, err
+}
+
+// DeleteChannel deletes the channel and the transactions of its frames.
+func (j *Journal) DeleteChannel(id derive.ChannelID) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.deleteChannel(id)
+}
+
+// PutTx records the transaction of the given frame, replacing the previous one.
+func (j *Journal) PutTx(id derive.ChannelID, frameNumber uint16, record *TxRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.putJSON(txKey(id, frameNumber), record)
+}
+
+func (j *Journal) DeleteTx(id derive.ChannelID, frameNumber uint16) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.db.Delete(txKey(id, frameNumber))
+}
+
+// Txs returns the transactions of the frames of the given channel, keyed by frame number.
+func (j *Journal) Txs(id derive.ChannelID) (map[uint16]*TxRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	prefix := append(common.CopyBytes(txPrefix), id[:]...)
+	records := make(map[uint16]*TxRecord)
+	err := j.iterate(prefix, func(key, value []byte) error {
+		var record TxRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		records[binary.BigEndian.Uint16(key[len(prefix):])] = &record
+		return nil
+	})
+
+	return records, err
+}
+
+// Clear deletes all the channels and transactions.
+func (j *Journal) Clear() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var ids []derive.ChannelID
+	err := j.iterate(channelPrefix, func(key, _ []byte) error {
+		var id derive.ChannelID
+		copy(id[:], key[len(channelPrefix):])
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := j.deleteChannel(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (j *Journal) deleteChannel(id derive.ChannelID) error {
+	batch := j.db.NewBatch()
+	if err := batch.Delete(channelKey(id)); err != nil {
+		return err
+	}
+	err := j.iterate(append(common.CopyBytes(txPrefix), id[:]...), func(key, _ []byte) error {
+		return batch.Delete(common.CopyBytes(key))
+	})
+	if err != nil {
+		return err
+	}
+
+	return batch.Write()
+}
+
+func (j *Journal) putJSON(key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return j.db.Put(key, data)
+}
+
+func (j *Journal) iterate(prefix []byte, fn func(key, value []byte) error) error {
+	it := j.db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if err := fn(it.Key(), it.Value()); err != nil {
+			return err
+		}
+	}
+
+	return it.Error()
+}
+
+func channelKey(id derive.ChannelID) []byte {
+	return append(common.CopyBytes(channelPrefix), id[:]...)
+}
+
+func txKey(id derive.ChannelID, frameNumber uint16) []byte {
+	key := append(common.CopyBytes(txPrefix), id[:]...)
+	return binary.BigEndian.AppendUint16(key, frameNumber)
+}
@@ -0,0 +1,77 @@
+package journal
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+func TestChannels(t *testing.T) {
+	j := NewMemoryJournal()
+
+	require.NoError(t, j.PutChannel(&ChannelRecord{
+		ID:         derive.ChannelID{2},
+		FirstBlock: eth.BlockID{Number: 11},
+		LastBlock:  eth.BlockID{Number: 20},
+		Frames:     []*FrameRecord{{Number: 0, Data: []byte{1}}},
+	}))
+	require.NoError(t, j.PutChannel(&ChannelRecord{
+		ID:         derive.ChannelID{1},
+		FirstBlock: eth.BlockID{Number: 21},
+		LastBlock:  eth.BlockID{Number: 30},
+	}))
+	// The transactions must not be iterated as channels.
+	require.NoError(t, j.PutTx(derive.ChannelID{2}, 0, &TxRecord{Hash: common.Hash{1}}))
+
+	records, err := j.Channels()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, derive.ChannelID{2}, records[0].ID)
+	require.Equal(t, []byte{1}, []byte(records[0].Frames[0].Data))
+	require.Equal(t, derive.ChannelID{1}, records[1].ID)
+
+	require.NoError(t, j.Clear())
+	records, err = j.Channels()
+	require.NoError(t, err)
+	require.Empty(t, records)
+	txs, err := j.Txs(derive.ChannelID{2})
+	require.NoError(t, err)
+	require.Empty(t, txs)
+}
+
+func TestTxs(t *testing.T) {
+	j := NewMemoryJournal()
+	id := derive.ChannelID{1}
+
+	require.NoError(t, j.PutChannel(&ChannelRecord{ID: id}))
+	require.NoError(t, j.PutTx(id, 0, &TxRecord{Hash: common.Hash{1}}))
+	require.NoError(t, j.PutTx(id, 1, &TxRecord{Hash: common.Hash{2}}))
+	require.NoError(t, j.PutTx(derive.ChannelID{2}, 0, &TxRecord{Hash: common.Hash{3}}))
+	// Confirming a transaction replaces the record.
+	require.NoError(t, j.PutTx(id, 1, &TxRecord{Hash: common.Hash{2}, InclusionBlock: &eth.BlockID{Number: 5}}))
+
+	txs, err := j.Txs(id)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, common.Hash{1}, txs[0].Hash)
+	require.Nil(t, txs[0].InclusionBlock)
+	require.Equal(t, uint64(5), txs[1].InclusionBlock.Number)
+
+	require.NoError(t, j.DeleteTx(id, 0))
+	txs, err = j.Txs(id)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	// Deleting the channel deletes its transactions only.
+	require.NoError(t, j.DeleteChannel(id))
+	txs, err = j.Txs(id)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+	txs, err = j.Txs(derive.ChannelID{2})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+}
@@ -2,10 +2,12 @@ package metrics
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/wemixkanvas/kanvas/components/node/eth"
@@ -29,10 +31,15 @@ type Metricer interface {
 	RecordChannelClosed(id derive.ChannelID, numPendingBlocks int, numFrames int, inputBytes int, outputComprBytes int, reason error)
 	RecordChannelFullySubmitted(id derive.ChannelID)
 	RecordChannelTimedOut(id derive.ChannelID)
+	RecordComprRatioEstimate(ratio float64)
 
 	RecordBatchTxSubmitted()
 	RecordBatchTxSuccess()
 	RecordBatchTxFailed()
+	RecordBatchTxHeld()
+
+	RecordL1BaseFeePaid(fee *big.Int)
+	RecordL1BaseFeeAvoided(fee *big.Int)
 
 	Document() []kmetrics.DocumentedMetric
 }
@@ -59,8 +66,12 @@ type Metrics struct {
 	ChannelClosedReason prometheus.Gauge
 	ChannelNumFrames    prometheus.Gauge
 	ChannelComprRatio   prometheus.Histogram
+	ComprRatioEstimate  prometheus.Gauge
 
 	BatcherTxEvs kmetrics.EventVec
+
+	L1BaseFeePaid    prometheus.Counter
+	L1BaseFeeAvoided prometheus.Counter
 }
 
 var _ Metricer = (*Metrics)(nil)
@@ -138,8 +149,24 @@ func NewMetrics(procName string) *Metrics {
 			Help:      "Compression ratios of closed channel.",
 			Buckets:   append([]float64{0.1, 0.2}, prometheus.LinearBuckets(0.3, 0.05, 14)...),
 		}),
+		ComprRatioEstimate: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "compr_ratio_estimate",
+			Help:      "Estimated compression ratio of the next channel.",
+		}),
 
 		BatcherTxEvs: kmetrics.NewEventVec(factory, ns, "batcher_tx", "BatcherTx", []string{"stage"}),
+
+		L1BaseFeePaid: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "l1_base_fee_paid_gwei",
+			Help:      "Total L1 base fees paid by the batcher transactions, in gwei.",
+		}),
+		L1BaseFeeAvoided: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "l1_base_fee_avoided_gwei",
+			Help:      "Estimated L1 base fees avoided by holding back batcher transactions, in gwei.",
+		}),
 	}
 }
 
@@ -179,6 +206,7 @@ const (
 	TxStageSubmitted = "submitted"
 	TxStageSuccess   = "success"
 	TxStageFailed    = "failed"
+	TxStageHeld      = "held"
 )
 
 func (m *Metrics) RecordLatestL1Block(l1ref eth.L1BlockRef) {
@@ -236,6 +264,12 @@ func (m *Metrics) RecordChannelTimedOut(id derive.ChannelID) {
 	m.ChannelEvs.Record(StageTimedOut)
 }
 
+// RecordComprRatioEstimate should be called when the estimate of the
+// compression ratio got updated.
+func (m *Metrics) RecordComprRatioEstimate(ratio float64) {
+	m.ComprRatioEstimate.Set(ratio)
+}
+
 func (m *Metrics) RecordBatchTxSubmitted() {
 	m.BatcherTxEvs.Record(TxStageSubmitted)
 }
@@ -247,3 +281,26 @@ func (m *Metrics) RecordBatchTxSuccess() {
 func (m *Metrics) RecordBatchTxFailed() {
 	m.BatcherTxEvs.Record(TxStageFailed)
 }
+
+// RecordBatchTxHeld should be called when frames are held back because of the
+// L1 base fee.
+func (m *Metrics) RecordBatchTxHeld() {
+	m.BatcherTxEvs.Record(TxStageHeld)
+}
+
+func (m *Metrics) RecordL1BaseFeePaid(fee *big.Int) {
+	m.L1BaseFeePaid.Add(weiToGwei(fee))
+}
+
+func (m *Metrics) RecordL1BaseFeeAvoided(fee *big.Int) {
+	m.L1BaseFeeAvoided.Add(weiToGwei(fee))
+}
+
+// weiToGwei divides the wei value by 10^9 to get a number in gwei as a float64
+func weiToGwei(wei *big.Int) float64 {
+	num := new(big.Rat).SetInt(wei)
+	denom := big.NewRat(params.GWei, 1)
+	num = num.Quo(num, denom)
+	f, _ := num.Float64()
+	return f
+}
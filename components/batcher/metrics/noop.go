@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"math/big"
+
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 	kmetrics "github.com/wemixkanvas/kanvas/utils/service/metrics"
@@ -24,7 +26,12 @@ func (*noopMetrics) RecordChannelClosed(derive.ChannelID, int, int, int, int, er
 
 func (*noopMetrics) RecordChannelFullySubmitted(derive.ChannelID) {}
 func (*noopMetrics) RecordChannelTimedOut(derive.ChannelID)       {}
+func (*noopMetrics) RecordComprRatioEstimate(float64)             {}
 
 func (*noopMetrics) RecordBatchTxSubmitted() {}
 func (*noopMetrics) RecordBatchTxSuccess()   {}
 func (*noopMetrics) RecordBatchTxFailed()    {}
+func (*noopMetrics) RecordBatchTxHeld()      {}
+
+func (*noopMetrics) RecordL1BaseFeePaid(*big.Int)    {}
+func (*noopMetrics) RecordL1BaseFeeAvoided(*big.Int) {}
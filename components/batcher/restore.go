@@ -0,0 +1,202 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/wemixkanvas/kanvas/components/batcher/journal"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+)
+
+// restoreState restores the closed channels from the journal, so that their
+// remaining frames are submitted after a restart instead of being orphaned on L1.
+// The journaled transactions are reconciled with the L1 receipts. A channel which
+// can't be completed anymore is abandoned together with the following channels,
+// and their blocks are loaded again from the safe head.
+func (b *BatchSubmitter) restoreState(ctx context.Context) error {
+	if b.Journal == nil {
+		return nil
+	}
+	records, err := b.Journal.Channels()
+	if err != nil {
+		return fmt.Errorf("failed to read journaled channels: %w", err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	cCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+	syncStatus, err := b.RollupClient.SyncStatus(cCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to get sync status: %w", err)
+	}
+
+	var (
+		channels []*channel
+		last     = syncStatus.SafeL2.ID()
+		abandon  bool
+	)
+	for _, record := range records {
+		if record.LastBlock.Number <= syncStatus.SafeL2.Number {
+			b.log.Info("Dropping journaled channel behind the safe head", "id", record.ID, "last_block", record.LastBlock)
+		} else if abandon {
+			b.log.Warn("Abandoning journaled channel following an abandoned one", "id", record.ID)
+		} else if ch, err := b.restoreChannel(ctx, record, last, syncStatus.HeadL1.Number); err != nil {
+			b.log.Warn("Abandoning journaled channel", "id", record.ID, "err", err)
+			// The blocks of the following channels don't extend the restored ones anymore.
+			abandon = true
+		} else if ch.isFullySubmitted() {
+			b.log.Info("Journaled channel is fully submitted", "id", record.ID, "last_block", record.LastBlock)
+			last = record.LastBlock
+		} else {
+			b.log.Info("Restored channel from journal", "id", record.ID,
+				"first_block", record.FirstBlock, "last_block", record.LastBlock,
+				"frames_pending", ch.builder.NumFrames(), "frames_confirmed", len(ch.confirmedTransactions))
+			channels = append(channels, ch)
+			last = record.LastBlock
+			continue
+		}
+
+		if err := b.Journal.DeleteChannel(record.ID); err != nil {
+			return fmt.Errorf("failed to delete journaled channel: %w", err)
+		}
+	}
+
+	if last != syncStatus.SafeL2.ID() {
+		b.state.restore(channels, last.Hash)
+		b.lastStoredBlock = last
+	}
+	return nil
+}
+
+// restoreChannel restores the journaled channel, which must start right after
+// the given parent block. The frames which are not confirmed on L1 are queued to
+// be submitted again.
+func (b *BatchSubmitter) restoreChannel(ctx context.Context, record *journal.ChannelRecord, parent eth.BlockID, l1Head uint64) (*channel, error) {
+	if record.FirstBlock.Number != parent.Number+1 {
+		return nil, fmt.Errorf("channel does not start after block %v", parent)
+	}
+	blocks := make([]*types.Block, 0, record.LastBlock.Number-record.FirstBlock.Number+1)
+	for number := record.FirstBlock.Number; number <= record.LastBlock.Number; number++ {
+		cCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+		block, err := b.L2Client.BlockByNumber(cCtx, new(big.Int).SetUint64(number))
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch L2 block %d: %w", number, err)
+		}
+		if block.ParentHash() != parent.Hash {
+			return nil, fmt.Errorf("L2 block %d does not extend %v: %w", number, parent, ErrReorg)
+		}
+		blocks = append(blocks, block)
+		parent = eth.ToBlockID(block)
+	}
+	if parent != record.LastBlock {
+		return nil, fmt.Errorf("last L2 block %v does not match %v: %w", parent, record.LastBlock, ErrReorg)
+	}
+
+	txs, err := b.Journal.Txs(record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journaled txs: %w", err)
+	}
+	var (
+		frames    []frameData
+		confirmed = make(map[txID]eth.BlockID)
+		minBlock  = uint64(math.MaxUint64)
+	)
+	for _, frame := range record.Frames {
+		id := txID{chID: record.ID, frameNumber: uint16(frame.Number)}
+		inclusionBlock, err := b.txInclusionBlock(ctx, txs[id.frameNumber])
+		if err != nil {
+			return nil, err
+		}
+		if inclusionBlock != nil {
+			confirmed[id] = *inclusionBlock
+			if inclusionBlock.Number < minBlock {
+				minBlock = inclusionBlock.Number
+			}
+			continue
+		}
+		if len(frame.Data) == 0 {
+			return nil, fmt.Errorf("frame %d is neither confirmed nor journaled with data", id.frameNumber)
+		}
+		frames = append(frames, frameData{id: id, data: frame.Data})
+	}
+
+	ch, err := restoreChannel(b.log, b.metr, b.Channel, record.ID, blocks, frames, confirmed)
+	if err != nil {
+		return nil, err
+	}
+	if ch.isTimedOut() {
+		return nil, errors.New("channel timed out")
+	}
+	// The remaining frames must be included before the channel times out.
+	if len(frames) > 0 && len(confirmed) > 0 && minBlock+b.Channel.ChannelTimeout-b.Channel.SubSafetyMargin <= l1Head {
+		return nil, errors.New("channel is close to timing out")
+	}
+
+	// Sync the journal with the reconciled state. The frames to be submitted
+	// again get new transactions.
+	for id, inclusionBlock := range confirmed {
+		inclusionBlock := inclusionBlock
+		record := &journal.TxRecord{InclusionBlock: &inclusionBlock}
+		if tx := txs[id.frameNumber]; tx != nil {
+			record.Hash = tx.Hash
+		}
+		if err := b.Journal.PutTx(id.chID, id.frameNumber, record); err != nil {
+			return nil, err
+		}
+	}
+	for _, frame := range frames {
+		if txs[frame.id.frameNumber] == nil {
+			continue
+		}
+		if err := b.Journal.DeleteTx(frame.id.chID, frame.id.frameNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	return ch, nil
+}
+
+// txInclusionBlock returns the L1 block in which the journaled transaction is
+// included, or nil if it is not included in the canonical chain.
+func (b *BatchSubmitter) txInclusionBlock(ctx context.Context, record *journal.TxRecord) (*eth.BlockID, error) {
+	if record == nil {
+		return nil, nil
+	}
+
+	if record.InclusionBlock != nil {
+		cCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+		header, err := b.L1Client.HeaderByNumber(cCtx, new(big.Int).SetUint64(record.InclusionBlock.Number))
+		cancel()
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to fetch L1 block %d: %w", record.InclusionBlock.Number, err)
+		}
+		if header != nil && header.Hash() == record.InclusionBlock.Hash {
+			return record.InclusionBlock, nil
+		}
+		// The inclusion block got reorged out, the tx may be included in another block.
+	}
+
+	if record.Hash == (common.Hash{}) {
+		return nil, nil
+	}
+	cCtx, cancel := context.WithTimeout(ctx, networkTimeout)
+	receipt, err := b.L1Client.TransactionReceipt(cCtx, record.Hash)
+	cancel()
+	if errors.Is(err, ethereum.NotFound) {
+		// Not included yet, or replaced by a tx with a bumped fee which is not journaled.
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch receipt of %s: %w", record.Hash, err)
+	}
+	return &eth.BlockID{Hash: receipt.BlockHash, Number: receipt.BlockNumber.Uint64()}, nil
+}
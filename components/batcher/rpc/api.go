@@ -2,11 +2,42 @@ package rpc
 
 import (
 	"context"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 )
 
+const NamespaceAdmin = "admin"
+
+// PendingState is the state of the blocks and the channels which are not fully submitted yet.
+type PendingState struct {
+	// BlocksPending is the number of blocks waiting to be added to a channel.
+	BlocksPending int `json:"blocksPending"`
+	// Channels are the pending channels in the order of creation. The last one may still be open.
+	Channels []*ChannelState `json:"channels"`
+}
+
+// ChannelState is the state of a pending channel.
+type ChannelState struct {
+	ID         derive.ChannelID `json:"id"`
+	Full       bool             `json:"full"`
+	FullReason string           `json:"fullReason,omitempty"`
+	Blocks     int              `json:"blocks"`
+	// InputBytes and OutputBytes are the amounts of data before and after the compression.
+	InputBytes  int `json:"inputBytes"`
+	OutputBytes int `json:"outputBytes"`
+	// FramesPending is the number of frames output but not sent yet.
+	FramesPending int `json:"framesPending"`
+	// FramesInFlight is the number of frames being sent.
+	FramesInFlight int `json:"framesInFlight"`
+	// FramesConfirmed is the number of frames included in L1.
+	FramesConfirmed int `json:"framesConfirmed"`
+}
+
 type batcherClient interface {
 	Start() error
 	Stop() error
+	PendingState() *PendingState
+	ForceCloseChannel(ctx context.Context) (derive.ChannelID, error)
 }
 
 type adminAPI struct {
@@ -23,6 +54,17 @@ func (a *adminAPI) StartBatcher(_ context.Context) error {
 	return a.b.Start()
 }
 
+// StopBatcher stops the batch submission after the in-flight transaction is done.
 func (a *adminAPI) StopBatcher(_ context.Context) error {
 	return a.b.Stop()
 }
+
+func (a *adminAPI) PendingState(_ context.Context) (*PendingState, error) {
+	return a.b.PendingState(), nil
+}
+
+// ForceCloseChannel closes the current channel, so that it's submitted without waiting for more blocks,
+// and returns its ID.
+func (a *adminAPI) ForceCloseChannel(ctx context.Context) (derive.ChannelID, error) {
+	return a.b.ForceCloseChannel(ctx)
+}
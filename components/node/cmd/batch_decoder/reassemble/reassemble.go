@@ -23,6 +23,7 @@ type ChannelWithMetadata struct {
 	InvalidBatches bool                `json:"invalid_batches"`
 	Frames         []FrameWithMetadata `json:"frames"`
 	Batches        []derive.BatchV1    `json:"batches"`
+	SpanBatches    []*derive.SpanBatch `json:"span_batches,omitempty"`
 }
 
 type FrameWithMetadata struct {
@@ -102,14 +103,18 @@ func processFrames(id derive.ChannelID, frames []FrameWithMetadata) ChannelWithM
 	}
 
 	var batches []derive.BatchV1
+	var spanBatches []*derive.SpanBatch
 	invalidBatches := false
 	if ch.IsReady() {
-		br, err := derive.BatchReader(ch.Reader(), eth.L1BlockRef{})
+		// Legacy zlib channels are detected by their header, so all channel formats can be decoded.
+		br, err := derive.BatchReader(ch.Reader(), eth.L1BlockRef{}, true)
 		if err == nil {
 			for batch, err := br(); err != io.EOF; batch, err = br() {
 				if err != nil {
 					fmt.Printf("Error reading batch for channel %v. Err: %v\n", id.String(), err)
 					invalidBatches = true
+				} else if batch.Batch.IsSpan() {
+					spanBatches = append(spanBatches, batch.Batch.Span)
 				} else {
 					batches = append(batches, batch.Batch.BatchV1)
 				}
@@ -128,6 +133,7 @@ func processFrames(id derive.ChannelID, frames []FrameWithMetadata) ChannelWithM
 		InvalidFrames:  invalidFrame,
 		InvalidBatches: invalidBatches,
 		Batches:        batches,
+		SpanBatches:    spanBatches,
 	}
 }
 
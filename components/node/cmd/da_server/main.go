@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli"
+
+	"github.com/wemixkanvas/kanvas/components/node/da"
+	kservice "github.com/wemixkanvas/kanvas/utils/service"
+	klog "github.com/wemixkanvas/kanvas/utils/service/log"
+)
+
+const envVarPrefix = "DA_SERVER"
+
+var (
+	AddrFlag = cli.StringFlag{
+		Name:   "addr",
+		Usage:  "Address the DA server listens on",
+		Value:  "127.0.0.1",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "ADDR"),
+	}
+	PortFlag = cli.IntFlag{
+		Name:   "port",
+		Usage:  "Port the DA server listens on",
+		Value:  3100,
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "PORT"),
+	}
+	DirFlag = cli.StringFlag{
+		Name:     "dir",
+		Usage:    "Directory the data of the commitments is stored in",
+		Required: true,
+		EnvVar:   kservice.PrefixEnvVar(envVarPrefix, "DIR"),
+	}
+)
+
+func main() {
+	klog.SetupDefaults()
+
+	app := cli.NewApp()
+	app.Name = "da-server"
+	app.Usage = "Kanvas DA Server"
+	app.Description = "Reference DA server, which stores the batch data of the commitments posted to L1 on disk and serves it over HTTP."
+	app.Flags = append([]cli.Flag{AddrFlag, PortFlag, DirFlag}, klog.CLIFlags(envVarPrefix)...)
+	app.Action = Main
+
+	if err := app.Run(os.Args); err != nil {
+		log.Crit("Application failed", "message", err)
+	}
+}
+
+func Main(ctx *cli.Context) error {
+	logCfg := klog.ReadCLIConfig(ctx)
+	if err := logCfg.Check(); err != nil {
+		return fmt.Errorf("invalid log config: %w", err)
+	}
+	logger := klog.NewLogger(logCfg)
+
+	store, err := da.NewFileStore(ctx.GlobalString(DirFlag.Name))
+	if err != nil {
+		return err
+	}
+	server := da.NewServer(logger, ctx.GlobalString(AddrFlag.Name), ctx.GlobalInt(PortFlag.Name), store)
+	if err := server.Start(); err != nil {
+		return fmt.Errorf("failed to start DA server: %w", err)
+	}
+	defer func() {
+		if err := server.Stop(); err != nil {
+			logger.Error("Failed to stop DA server", "err", err)
+		}
+	}()
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, []os.Signal{
+		os.Interrupt,
+		os.Kill,
+		syscall.SIGTERM,
+		syscall.SIGQUIT,
+	}...)
+	<-interruptChannel
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package da
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+// clientTimeout bounds a single request to the DA server, including reading the data.
+const clientTimeout = 30 * time.Second
+
+// ErrInputTooLarge is returned when the data of a commitment is larger than MaxInputSize.
+var ErrInputTooLarge = derive.ErrDAInputTooLarge
+
+// Client is an HTTP client of a DA server, see Server.
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+var _ derive.DAClient = (*Client)(nil)
+
+func NewClient(url string) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: clientTimeout},
+	}
+}
+
+// GetInput returns the data of the commitment. It's not verified against the commitment.
+func (c *Client) GetInput(ctx context.Context, comm derive.DACommitment) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/get/%s", c.url, comm), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, derive.ErrDACommitmentNotFound
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get data: status %s", resp.Status)
+	}
+	// Read one more byte than allowed, to tell the data at the limit from the larger data.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxInputSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	if len(data) > MaxInputSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, MaxInputSize)
+	}
+	return data, nil
+}
+
+// SetInput stores the data in the DA server and returns its commitment.
+func (c *Client) SetInput(ctx context.Context, data []byte) (derive.DACommitment, error) {
+	comm := derive.NewKeccak256Commitment(data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fmt.Sprintf("%s/put/%s", c.url, comm), bytes.NewReader(data))
+	if err != nil {
+		return derive.DACommitment{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return derive.DACommitment{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return derive.DACommitment{}, fmt.Errorf("failed to put data: status %s", resp.Status)
+	}
+	return comm, nil
+}
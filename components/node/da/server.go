@@ -0,0 +1,133 @@
+package da
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+// MaxInputSize is the maximum size of the data of a commitment the server accepts.
+const MaxInputSize = 10_000_000
+
+// Server is a reference DA server, which serves the data of the commitments over HTTP:
+//
+//	GET /get/<commitment> returns the data of the hex encoded commitment, or 404 if not found.
+//	PUT /put/<commitment> stores the request body, which must match the commitment.
+type Server struct {
+	log      log.Logger
+	endpoint string
+	store    Store
+
+	httpServer *http.Server
+	listener   net.Listener
+}
+
+func NewServer(log log.Logger, host string, port int, store Store) *Server {
+	s := &Server{
+		log:      log,
+		endpoint: net.JoinHostPort(host, strconv.Itoa(port)),
+		store:    store,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/get/", s.handleGet)
+	mux.HandleFunc("/put/", s.handlePut)
+	s.httpServer = &http.Server{Handler: mux}
+	return s
+}
+
+// Start starts serving in the background.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s.listener = listener
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("DA server stopped", "err", err)
+		}
+	}()
+	s.log.Info("Started DA server", "endpoint", s.Endpoint())
+	return nil
+}
+
+// Endpoint returns the address the server listens on, once started.
+func (s *Server) Endpoint() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.endpoint
+}
+
+func (s *Server) Stop() error {
+	return s.httpServer.Close()
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	comm, err := parseCommitment(r.URL.Path, "/get/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.store.Get(r.Context(), comm)
+	if errors.Is(err, derive.ErrDACommitmentNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		s.log.Error("Failed to get data", "commitment", comm, "err", err)
+		http.Error(w, "failed to get data", http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write(data); err != nil {
+		s.log.Warn("Failed to write response", "commitment", comm, "err", err)
+	}
+}
+
+func (s *Server) handlePut(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	comm, err := parseCommitment(r.URL.Path, "/put/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxInputSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read data: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := comm.Verify(data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.store.Put(r.Context(), comm, data); err != nil {
+		s.log.Error("Failed to put data", "commitment", comm, "err", err)
+		http.Error(w, "failed to put data", http.StatusInternalServerError)
+		return
+	}
+	s.log.Debug("Stored data", "commitment", comm, "size", len(data))
+}
+
+func parseCommitment(path string, prefix string) (derive.DACommitment, error) {
+	var comm derive.DACommitment
+	if err := comm.UnmarshalText([]byte(strings.TrimPrefix(path, prefix))); err != nil {
+		return derive.DACommitment{}, fmt.Errorf("invalid commitment: %w", err)
+	}
+	return comm, nil
+}
@@ -0,0 +1,76 @@
+package da
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/components/node/testutils"
+)
+
+func TestServerRoundTrip(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	server := NewServer(testlog.Logger(t, log.LvlInfo), "127.0.0.1", 0, store)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	ctx := context.Background()
+	url := "http://" + server.Endpoint()
+	client := NewClient(url)
+	rng := rand.New(rand.NewSource(1234))
+	data := testutils.RandomData(rng, 1000)
+
+	_, err = client.GetInput(ctx, derive.NewKeccak256Commitment(data))
+	require.ErrorIs(t, err, derive.ErrDACommitmentNotFound)
+
+	comm, err := client.SetInput(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, derive.NewKeccak256Commitment(data), comm)
+
+	input, err := client.GetInput(ctx, comm)
+	require.NoError(t, err)
+	require.Equal(t, data, input)
+
+	// data which doesn't match the commitment is rejected
+	other := testutils.RandomData(rng, 1000)
+	otherComm := derive.NewKeccak256Commitment(other)
+	resp, err := http.Post(fmt.Sprintf("%s/put/%s", url, otherComm), "application/octet-stream", bytes.NewReader(data))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	_, err = client.GetInput(ctx, otherComm)
+	require.ErrorIs(t, err, derive.ErrDACommitmentNotFound)
+
+	resp, err = http.Get(url + "/get/0x1234")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestClientInputTooLarge(t *testing.T) {
+	size := MaxInputSize
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, size))
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	comm := derive.NewKeccak256Commitment([]byte{1})
+
+	data, err := client.GetInput(context.Background(), comm)
+	require.NoError(t, err)
+	require.Len(t, data, MaxInputSize)
+
+	size = MaxInputSize + 1
+	_, err = client.GetInput(context.Background(), comm)
+	require.ErrorIs(t, err, ErrInputTooLarge)
+}
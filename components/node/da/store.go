@@ -0,0 +1,62 @@
+package da
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
+)
+
+// Store stores the data of the DA commitments.
+type Store interface {
+	// Get returns the data of the commitment, or derive.ErrDACommitmentNotFound if it's not stored.
+	Get(ctx context.Context, comm derive.DACommitment) ([]byte, error)
+	// Put stores the data of the commitment, which must be verified by the caller.
+	Put(ctx context.Context, comm derive.DACommitment, data []byte) error
+}
+
+// FileStore stores the data of each commitment in a file of a directory.
+type FileStore struct {
+	directory string
+}
+
+// NewFileStore creates a file store in the given directory, which is created if it doesn't exist.
+func NewFileStore(directory string) (*FileStore, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return &FileStore{directory: directory}, nil
+}
+
+func (s *FileStore) Get(ctx context.Context, comm derive.DACommitment) ([]byte, error) {
+	data, err := os.ReadFile(s.fileName(comm))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, derive.ErrDACommitmentNotFound
+	}
+	return data, err
+}
+
+// Put writes the data to a temporary file first, so that a commitment is never
+// stored with partial data.
+func (s *FileStore) Put(ctx context.Context, comm derive.DACommitment, data []byte) error {
+	file, err := os.CreateTemp(s.directory, "put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.fileName(comm))
+}
+
+func (s *FileStore) fileName(comm derive.DACommitment) string {
+	return filepath.Join(s.directory, comm.String())
+}
@@ -175,6 +175,11 @@ var (
 		EnvVar:   prefixEnvVar("L2_BACKUP_UNSAFE_SYNC_RPC"),
 		Required: false,
 	}
+	DARpc = cli.StringFlag{
+		Name:   "da.rpc",
+		Usage:  "HTTP endpoint of the external DA server, to fetch the batch data of the DA commitments. Required once the external DA fork is configured.",
+		EnvVar: prefixEnvVar("DA_RPC"),
+	}
 )
 
 var requiredFlags = []cli.Flag{
@@ -207,6 +212,7 @@ var optionalFlags = []cli.Flag{
 	HeartbeatMonikerFlag,
 	HeartbeatURLFlag,
 	BackupL2UnsafeSyncRPC,
+	DARpc,
 }
 
 // Flags contains the list of configuration options available to the binary.
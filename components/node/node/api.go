@@ -13,9 +13,16 @@ import (
 	"github.com/wemixkanvas/kanvas/bindings/predeploys"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/rollup"
+	"github.com/wemixkanvas/kanvas/components/node/sources"
+	"github.com/wemixkanvas/kanvas/components/node/sources/caching"
 	"github.com/wemixkanvas/kanvas/components/node/version"
 )
 
+const (
+	// outputCacheSize is the number of the computed outputs cached by block hash.
+	outputCacheSize = 1000
+)
+
 type l2EthClient interface {
 	InfoByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, error)
 	// GetProof returns a proof of the account, it may return a nil result without error if the address was not found.
@@ -72,15 +79,21 @@ type nodeAPI struct {
 	dr     driverClient
 	log    log.Logger
 	m      rpcMetrics
+
+	// outputs caches the computed outputs by block hash, without the sync status.
+	outputs *caching.LRUCache
 }
 
 func NewNodeAPI(config *rollup.Config, l2Client l2EthClient, dr driverClient, log log.Logger, m rpcMetrics) *nodeAPI {
+	// The cache metrics are tracked only if the given metrics support them.
+	cacheMetrics, _ := m.(caching.Metrics)
 	return &nodeAPI{
-		config: config,
-		client: l2Client,
-		dr:     dr,
-		log:    log,
-		m:      m,
+		config:  config,
+		client:  l2Client,
+		dr:      dr,
+		log:     log,
+		m:       m,
+		outputs: caching.NewLRUCache(cacheMetrics, "outputs", outputCacheSize),
 	}
 }
 
@@ -88,11 +101,44 @@ func (n *nodeAPI) OutputAtBlock(ctx context.Context, number hexutil.Uint64) (*et
 	recordDur := n.m.RecordRPCServerRequest("kanvas_outputAtBlock")
 	defer recordDur()
 
-	ref, status, err := n.dr.BlockRefWithStatus(ctx, uint64(number))
+	return n.outputAtBlock(ctx, uint64(number))
+}
+
+// OutputsAtBlocks returns the outputs at the given blocks in a single round trip, in the same order.
+func (n *nodeAPI) OutputsAtBlocks(ctx context.Context, numbers []hexutil.Uint64) ([]*eth.OutputResponse, error) {
+	recordDur := n.m.RecordRPCServerRequest("kanvas_outputsAtBlocks")
+	defer recordDur()
+
+	if len(numbers) > sources.MaxOutputsAtBlocks {
+		return nil, fmt.Errorf("too many blocks requested: %d, maximum is %d", len(numbers), sources.MaxOutputsAtBlocks)
+	}
+
+	outputs := make([]*eth.OutputResponse, len(numbers))
+	for i, number := range numbers {
+		output, err := n.outputAtBlock(ctx, uint64(number))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get output at block %d: %w", number, err)
+		}
+		outputs[i] = output
+	}
+
+	return outputs, nil
+}
+
+func (n *nodeAPI) outputAtBlock(ctx context.Context, number uint64) (*eth.OutputResponse, error) {
+	ref, status, err := n.dr.BlockRefWithStatus(ctx, number)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get L2 block ref with sync status: %w", err)
 	}
 
+	// The output of a block never changes, so it is cached by the block hash. Once the block is reorged out,
+	// its hash is not canonical anymore and the cached output is never returned again until it is evicted.
+	if cached, ok := n.outputs.Get(ref.Hash); ok {
+		output := *cached.(*eth.OutputResponse)
+		output.Status = status
+		return &output, nil
+	}
+
 	head, err := n.client.InfoByHash(ctx, ref.Hash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get L2 block by hash %s: %w", ref, err)
@@ -126,14 +172,18 @@ func (n *nodeAPI) OutputAtBlock(ctx context.Context, number hexutil.Uint64) (*et
 		return nil, err
 	}
 
-	return &eth.OutputResponse{
+	output := &eth.OutputResponse{
 		Version:               l2OutputRootVersion,
 		OutputRoot:            l2OutputRoot,
 		BlockRef:              ref,
 		WithdrawalStorageRoot: proof.StorageHash,
 		StateRoot:             head.Root(),
-		Status:                status,
-	}, nil
+	}
+	n.outputs.Add(ref.Hash, output)
+
+	result := *output
+	result.Status = status
+	return &result, nil
 }
 
 func (n *nodeAPI) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
@@ -39,6 +39,10 @@ type Config struct {
 	// Optional
 	Tracer    Tracer
 	Heartbeat HeartbeatConfig
+
+	// DARpc is the HTTP endpoint of the external DA server. It's required once
+	// the external DA fork is configured, to resolve the DA commitments.
+	DARpc string
 }
 
 type RPCConfig struct {
@@ -94,5 +98,8 @@ func (cfg *Config) Check() error {
 			return fmt.Errorf("p2p config error: %w", err)
 		}
 	}
+	if cfg.Rollup.ExternalDAForkTime != nil && cfg.DARpc == "" {
+		return errors.New("the external DA fork is configured, but the DA server is not set")
+	}
 	return nil
 }
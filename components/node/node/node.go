@@ -13,9 +13,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/wemixkanvas/kanvas/components/node/client"
+	"github.com/wemixkanvas/kanvas/components/node/da"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/metrics"
 	"github.com/wemixkanvas/kanvas/components/node/p2p"
+	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 	"github.com/wemixkanvas/kanvas/components/node/rollup/driver"
 	"github.com/wemixkanvas/kanvas/components/node/sources"
 )
@@ -217,7 +219,12 @@ func (n *KanvasNode) initL2(ctx context.Context, cfg *Config, snapshotLog log.Lo
 		}
 	}
 
-	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.l2Source, n.l1Source, syncClient, n, n.log, snapshotLog, n.metrics)
+	var daClient derive.DAClient
+	if cfg.DARpc != "" {
+		daClient = da.NewClient(cfg.DARpc)
+	}
+
+	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.l2Source, n.l1Source, syncClient, daClient, n, n.log, snapshotLog, n.metrics)
 
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
@@ -125,6 +126,17 @@ func TestOutputAtBlock(t *testing.T) {
 	require.Equal(t, "0xb46d4bcb0e471e1b8506031a1f34ebc6f200253cbaba56246dd2320e8e2c8f13", out.StateRoot.String())
 	require.Equal(t, "0xc1917a80cb25ccc50d0d1921525a44fb619b4601194ca726ae32312f08a799f8", out.WithdrawalStorageRoot.String())
 	require.Equal(t, *status, *out.Status)
+
+	// The output is cached, so the block and the proof are fetched only once.
+	var outs []*eth.OutputResponse
+	err = client.CallContext(context.Background(), &outs, "kanvas_outputsAtBlocks", []hexutil.Uint64{0xdcdc89, 0xdcdc89})
+	require.NoError(t, err)
+	require.Len(t, outs, 2)
+	for _, o := range outs {
+		require.Equal(t, out.OutputRoot, o.OutputRoot)
+		require.Equal(t, *status, *o.Status)
+	}
+
 	l2Client.Mock.AssertExpectations(t)
 	drClient.Mock.AssertExpectations(t)
 }
@@ -21,6 +21,8 @@ import (
 // BatchV1Type := 0
 // batchV1 := BatchV1Type ++ RLP([epoch, timestamp, transaction_list]
 //
+// SpanBatchType := 1, see SpanBatch. Only valid once the span batch fork is active.
+//
 // An empty input is not a valid batch.
 //
 // Note: the type system is based on L1 typed transactions.
@@ -32,6 +34,7 @@ var encodeBufferPool = sync.Pool{
 
 const (
 	BatchV1Type = iota
+	SpanBatchType
 )
 
 type BatchV1 struct {
@@ -46,6 +49,14 @@ type BatchV1 struct {
 type BatchData struct {
 	BatchV1
 	// batches may contain additional data with new upgrades
+
+	// Span is set instead of BatchV1 if the batch is a span batch
+	Span *SpanBatch
+}
+
+// IsSpan returns whether the batch is a span batch.
+func (b *BatchData) IsSpan() bool {
+	return b.Span != nil
 }
 
 func (b *BatchV1) Epoch() eth.BlockID {
@@ -71,6 +82,10 @@ func (b *BatchData) MarshalBinary() ([]byte, error) {
 }
 
 func (b *BatchData) encodeTyped(buf *bytes.Buffer) error {
+	if b.IsSpan() {
+		buf.WriteByte(SpanBatchType)
+		return rlp.Encode(buf, b.Span)
+	}
 	buf.WriteByte(BatchV1Type)
 	return rlp.Encode(buf, &b.BatchV1)
 }
@@ -102,6 +117,9 @@ func (b *BatchData) decodeTyped(data []byte) error {
 	switch data[0] {
 	case BatchV1Type:
 		return rlp.DecodeBytes(data[1:], &b.BatchV1)
+	case SpanBatchType:
+		b.Span = new(SpanBatch)
+		return rlp.DecodeBytes(data[1:], b.Span)
 	default:
 		return fmt.Errorf("unrecognized batch type: %d", data[0])
 	}
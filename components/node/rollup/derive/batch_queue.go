@@ -43,7 +43,11 @@ type BatchQueue struct {
 	l1Blocks []eth.L1BlockRef
 
 	// batches in order of when we've first seen them, grouped by L2 timestamp
+	// span batches are grouped by the L2 timestamp of their first block
 	batches map[uint64][]*BatchWithL1InclusionBlock
+
+	// remaining batches of the accepted span batch, to be derived before any other batch
+	nextSpan []*BatchData
 }
 
 // NewBatchQueue creates a BatchQueue, which should be Reset(origin) before use.
@@ -119,6 +123,7 @@ func (bq *BatchQueue) Reset(ctx context.Context, base eth.L1BlockRef, _ eth.Syst
 	// It is set in the engine queue (two stages away) such that the L2 Safe Head origin is the progress
 	bq.origin = base
 	bq.batches = make(map[uint64][]*BatchWithL1InclusionBlock)
+	bq.nextSpan = nil
 	// Include the new origin as an origin to build on
 	// Note: This is only for the initialization case. During normal resets we will later
 	// throw out this block.
@@ -139,6 +144,12 @@ func (bq *BatchQueue) AddBatch(batch *BatchData, l2SafeHead eth.L2BlockRef) {
 	if validity == BatchDrop {
 		return // if we do drop the batch, CheckBatch will log the drop reason with WARN level.
 	}
+	if batch.IsSpan() {
+		timestamp := batch.Span.FirstTimestamp()
+		bq.log.Debug("Adding span batch", "batch_timestamp", timestamp, "parent_hash", batch.Span.ParentHash, "batch_epoch", batch.Span.FirstEpochNum(), "blocks", len(batch.Span.Blocks), "txs", batch.Span.TxCount())
+		bq.batches[timestamp] = append(bq.batches[timestamp], &data)
+		return
+	}
 	bq.log.Debug("Adding batch", "batch_timestamp", batch.Timestamp, "parent_hash", batch.ParentHash, "batch_epoch", batch.Epoch(), "txs", len(batch.Transactions))
 	bq.batches[batch.Timestamp] = append(bq.batches[batch.Timestamp], &data)
 }
@@ -161,10 +172,19 @@ func (bq *BatchQueue) deriveNextBatch(ctx context.Context, outOfData bool, l2Saf
 		return nil, NewResetError(fmt.Errorf("buffered L1 chain epoch %s in batch queue does not match safe head origin %s", epoch, l2SafeHead.L1Origin))
 	}
 
+	nextTimestamp := l2SafeHead.Time + bq.config.BlockTime
+	bq.pruneBatches(nextTimestamp)
+
+	// The blocks of an accepted span batch are derived first.
+	if len(bq.nextSpan) > 0 {
+		if batch := bq.nextSpanBatch(l2SafeHead); batch != nil {
+			return batch, nil
+		}
+	}
+
 	// Find the first-seen batch that matches all validity conditions.
 	// We may not have sufficient information to proceed filtering, and then we stop.
 	// There may be none: in that case we force-create an empty batch
-	nextTimestamp := l2SafeHead.Time + bq.config.BlockTime
 	var nextBatch *BatchWithL1InclusionBlock
 
 	// Go over all batches, in order of inclusion, and find the first batch we can accept.
@@ -178,6 +198,17 @@ batchLoop:
 		case BatchFuture:
 			return nil, NewCriticalError(fmt.Errorf("found batch with timestamp %d marked as future batch, but expected timestamp %d", batch.Batch.Timestamp, nextTimestamp))
 		case BatchDrop:
+			if batch.Batch.IsSpan() {
+				bq.log.Warn("dropping span batch",
+					"batch_timestamp", batch.Batch.Span.FirstTimestamp(),
+					"parent_hash", batch.Batch.Span.ParentHash,
+					"batch_epoch", batch.Batch.Span.FirstEpochNum(),
+					"blocks", len(batch.Batch.Span.Blocks),
+					"l2_safe_head", l2SafeHead.ID(),
+					"l2_safe_head_time", l2SafeHead.Time,
+				)
+				continue
+			}
 			bq.log.Warn("dropping batch",
 				"batch_timestamp", batch.Batch.Timestamp,
 				"parent_hash", batch.Batch.ParentHash,
@@ -208,6 +239,15 @@ batchLoop:
 		bq.batches[nextTimestamp] = remaining
 	}
 
+	if nextBatch != nil && nextBatch.Batch.IsSpan() {
+		bq.log.Info("Found next span batch", "epoch", epoch, "batch_timestamp", nextBatch.Batch.Span.FirstTimestamp(), "blocks", len(nextBatch.Batch.Span.Blocks))
+		bq.nextSpan = bq.spanBatches(nextBatch.Batch.Span)
+		if batch := bq.nextSpanBatch(l2SafeHead); batch != nil {
+			return batch, nil
+		}
+		return nil, NewCriticalError(errors.New("accepted span batch does not apply on top of the safe head"))
+	}
+
 	if nextBatch != nil {
 		// advance epoch if necessary
 		if nextBatch.Batch.EpochNum == rollup.Epoch(epoch.Number)+1 {
@@ -244,7 +284,7 @@ batchLoop:
 	if nextTimestamp < nextEpoch.Time || firstOfEpoch {
 		bq.log.Info("Generating next batch", "epoch", epoch, "timestamp", nextTimestamp)
 		return &BatchData{
-			BatchV1{
+			BatchV1: BatchV1{
 				ParentHash:   l2SafeHead.Hash,
 				EpochNum:     rollup.Epoch(epoch.Number),
 				EpochHash:    epoch.Hash,
@@ -260,3 +300,61 @@ batchLoop:
 	bq.l1Blocks = bq.l1Blocks[1:]
 	return nil, io.EOF
 }
+
+// pruneBatches drops the batches before the given next timestamp, which can't be accepted
+// anymore. They are left over if their blocks are derived from an accepted span batch.
+func (bq *BatchQueue) pruneBatches(nextTimestamp uint64) {
+	for timestamp, batches := range bq.batches {
+		if timestamp < nextTimestamp {
+			bq.log.Debug("Dropping old batches", "batch_timestamp", timestamp, "batches", len(batches), "next_timestamp", nextTimestamp)
+			delete(bq.batches, timestamp)
+		}
+	}
+}
+
+// spanBatches splits the span batch into the batches of its blocks, of which the epoch hashes
+// are taken from the L1 blocks. The span batch must have been checked against the L1 blocks.
+// The parent hashes are set once the parent blocks are derived.
+func (bq *BatchQueue) spanBatches(span *SpanBatch) []*BatchData {
+	batches := make([]*BatchData, 0, len(span.Blocks))
+	timestamp, epochNum := span.Timestamp, span.EpochNum
+	for _, block := range span.Blocks {
+		timestamp += block.TimeDelta
+		epochNum += rollup.Epoch(block.EpochDelta)
+		batches = append(batches, &BatchData{
+			BatchV1: BatchV1{
+				EpochNum:     epochNum,
+				EpochHash:    bq.l1Blocks[uint64(epochNum)-bq.l1Blocks[0].Number].Hash,
+				Timestamp:    timestamp,
+				Transactions: block.Transactions,
+			},
+		})
+	}
+	return batches
+}
+
+// nextSpanBatch pops the batch of the next block of the accepted span batch, which must apply
+// on top of the given safe head. Otherwise, the rest of the span batch is dropped and nil is returned.
+func (bq *BatchQueue) nextSpanBatch(l2SafeHead eth.L2BlockRef) *BatchData {
+	batch := bq.nextSpan[0]
+	epoch := bq.l1Blocks[0]
+	if batch.Timestamp != l2SafeHead.Time+bq.config.BlockTime ||
+		(batch.EpochNum != rollup.Epoch(epoch.Number) && batch.EpochNum != rollup.Epoch(epoch.Number)+1) {
+		bq.log.Warn("dropping rest of span batch, which does not apply on top of the safe head",
+			"batch_timestamp", batch.Timestamp, "batch_epoch", batch.EpochNum, "blocks", len(bq.nextSpan),
+			"l2_safe_head", l2SafeHead.ID(), "l2_safe_head_time", l2SafeHead.Time)
+		bq.nextSpan = nil
+		return nil
+	}
+	bq.nextSpan = bq.nextSpan[1:]
+
+	// The parent block of the first batch is checked with the span batch, and the following
+	// batches apply on top of the blocks derived from the previous batches of the span.
+	batch.ParentHash = l2SafeHead.Hash
+	// advance epoch if necessary
+	if batch.EpochNum == rollup.Epoch(epoch.Number)+1 {
+		bq.l1Blocks = bq.l1Blocks[1:]
+	}
+	bq.log.Info("Found next batch of span batch", "epoch", epoch, "batch_epoch", batch.EpochNum, "batch_timestamp", batch.Timestamp, "remaining", len(bq.nextSpan))
+	return batch
+}
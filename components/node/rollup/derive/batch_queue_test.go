@@ -48,7 +48,7 @@ func mockHash(time uint64, layer uint8) common.Hash {
 func b(timestamp uint64, epoch eth.L1BlockRef) *BatchData {
 	rng := rand.New(rand.NewSource(int64(timestamp)))
 	data := testutils.RandomData(rng, 20)
-	return &BatchData{BatchV1{
+	return &BatchData{BatchV1: BatchV1{
 		ParentHash:   mockHash(timestamp-2, 2),
 		Timestamp:    timestamp,
 		EpochNum:     rollup.Epoch(epoch.Number),
@@ -183,6 +183,74 @@ func TestBatchQueueEager(t *testing.T) {
 	}
 }
 
+// TestBatchQueueSpanBatch adds a span batch and asserts that enough calls to
+// `NextBatch` return the batches of all of its blocks, once the fork is active.
+// Duplicate batches of the blocks of the span batch are pruned.
+func TestBatchQueueSpanBatch(t *testing.T) {
+	log := testlog.Logger(t, log.LvlCrit)
+	l1 := L1Chain([]uint64{10, 20, 30})
+	batches := []*BatchData{b(12, l1[0]), b(14, l1[0]), b(16, l1[0]), b(18, l1[0]), b(20, l1[0]), b(22, l1[0]), b(24, l1[1])}
+	span := NewSpanBatch(&batches[0].BatchV1)
+	for _, batch := range batches[1:] {
+		require.NoError(t, span.Append(&batch.BatchV1, mockHash(batch.Timestamp-2, 2), 2))
+	}
+
+	for _, forkActive := range []bool{false, true} {
+		safeHead := eth.L2BlockRef{
+			Hash:           mockHash(10, 2),
+			Number:         0,
+			ParentHash:     common.Hash{},
+			Time:           10,
+			L1Origin:       l1[0].ID(),
+			SequenceNumber: 0,
+		}
+		cfg := &rollup.Config{
+			Genesis: rollup.Genesis{
+				L2Time: 10,
+			},
+			BlockTime:          2,
+			MaxProposerDrift:   600,
+			ProposerWindowSize: 30,
+		}
+		if forkActive {
+			cfg.SpanBatchForkTime = &l1[1].Time
+		}
+
+		input := &fakeBatchQueueInput{
+			batches: []*BatchData{{Span: span}, b(14, l1[0]), b(16, l1[0])},
+			errors:  []error{nil, nil, nil},
+			origin:  l1[0],
+		}
+
+		bq := NewBatchQueue(log, cfg, input)
+		_ = bq.Reset(context.Background(), l1[0], eth.SystemConfig{})
+		// Advance the origin
+		input.origin = l1[1]
+
+		if !forkActive {
+			b, e := bq.NextBatch(context.Background(), safeHead)
+			require.ErrorIs(t, e, NotEnoughData)
+			require.Nil(t, b)
+			continue
+		}
+
+		for i := 0; i < len(batches); i++ {
+			b, e := bq.NextBatch(context.Background(), safeHead)
+			require.NoError(t, e)
+			require.Equal(t, batches[i], b)
+
+			safeHead.Number += 1
+			safeHead.Time += 2
+			safeHead.Hash = mockHash(b.Timestamp, 2)
+			safeHead.L1Origin = b.Epoch()
+		}
+		b, e := bq.NextBatch(context.Background(), safeHead)
+		require.ErrorIs(t, e, io.EOF)
+		require.Nil(t, b)
+		require.Empty(t, bq.batches)
+	}
+}
+
 // TestBatchQueueInvalidInternalAdvance asserts that we do not miss an epoch when generating batches.
 // This is a regression test for CLI-3378.
 func TestBatchQueueInvalidInternalAdvance(t *testing.T) {
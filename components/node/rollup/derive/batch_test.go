@@ -26,6 +26,19 @@ func TestBatchRoundTrip(t *testing.T) {
 				Transactions: []hexutil.Bytes{[]byte{0, 0, 0}, []byte{0x76, 0xfd, 0x7c}},
 			},
 		},
+		{
+			Span: &SpanBatch{
+				ParentHash:    common.Hash{31: 0x42},
+				EpochNum:      1,
+				LastEpochHash: common.Hash{31: 0x13},
+				Timestamp:     1647026951,
+				Blocks: []SpanBatchBlock{
+					{Transactions: []hexutil.Bytes{[]byte{0, 0, 0}}},
+					{EpochDelta: 1, TimeDelta: 2, Transactions: []hexutil.Bytes{}},
+					{TimeDelta: 2, Transactions: []hexutil.Bytes{[]byte{0x76, 0xfd, 0x7c}}},
+				},
+			},
+		},
 	}
 
 	for i, batch := range batches {
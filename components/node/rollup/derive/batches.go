@@ -30,6 +30,10 @@ const (
 // The first entry of the l1Blocks should match the origin of the l2SafeHead. One or more consecutive l1Blocks should be provided.
 // In case of only a single L1 block, the decision whether a batch is valid may have to stay undecided.
 func CheckBatch(cfg *rollup.Config, log log.Logger, l1Blocks []eth.L1BlockRef, l2SafeHead eth.L2BlockRef, batch *BatchWithL1InclusionBlock) BatchValidity {
+	if batch.Batch.IsSpan() {
+		return checkSpanBatch(cfg, log, l1Blocks, l2SafeHead, batch)
+	}
+
 	// add details to the log
 	log = log.New(
 		"batch_timestamp", batch.Batch.Timestamp,
@@ -142,3 +146,129 @@ func CheckBatch(cfg *rollup.Config, log log.Logger, l1Blocks []eth.L1BlockRef, l
 
 	return BatchAccept
 }
+
+// checkSpanBatch checks if the given span batch can be applied on top of the given l2SafeHead.
+// The blocks of the span are checked like singular batches, except for the parent hash that is
+// only checked for the first block. The epochs of all blocks must be within the given l1Blocks,
+// which are linked by their hashes, so checking the epoch hash of the last block covers all epochs.
+// If any of the blocks is invalid, the whole span batch is dropped.
+func checkSpanBatch(cfg *rollup.Config, log log.Logger, l1Blocks []eth.L1BlockRef, l2SafeHead eth.L2BlockRef, batch *BatchWithL1InclusionBlock) BatchValidity {
+	span := batch.Batch.Span
+	// add details to the log
+	log = log.New(
+		"batch_timestamp", span.FirstTimestamp(),
+		"parent_hash", span.ParentHash,
+		"batch_epoch", span.FirstEpochNum(),
+		"blocks", len(span.Blocks),
+		"txs", span.TxCount(),
+	)
+
+	if !cfg.IsSpanBatchFork(batch.L1InclusionBlock.Time) {
+		log.Warn("dropping span batch included before the span batch fork", "l1_inclusion_time", batch.L1InclusionBlock.Time)
+		return BatchDrop
+	}
+
+	// sanity check we have consistent inputs
+	if len(l1Blocks) == 0 {
+		log.Warn("missing L1 block input, cannot proceed with batch checking")
+		return BatchUndecided
+	}
+	epoch := l1Blocks[0]
+
+	nextTimestamp := l2SafeHead.Time + cfg.BlockTime
+	if span.FirstTimestamp() > nextTimestamp {
+		log.Trace("received out-of-order span batch for future processing after next batch", "next_timestamp", nextTimestamp)
+		return BatchFuture
+	}
+	if span.FirstTimestamp() < nextTimestamp {
+		log.Warn("dropping span batch with old timestamp", "min_timestamp", nextTimestamp)
+		return BatchDrop
+	}
+
+	// dependent on above timestamp check. If the timestamp is correct, then it must build on top of the safe head.
+	if span.ParentHash != l2SafeHead.Hash {
+		log.Warn("ignoring span batch with mismatching parent hash", "current_safe_head", l2SafeHead.Hash)
+		return BatchDrop
+	}
+
+	// Filter out batches that were included too late. The epoch of the first block is the oldest one.
+	if uint64(span.FirstEpochNum())+cfg.ProposerWindowSize < batch.L1InclusionBlock.Number {
+		log.Warn("span batch was included too late, proposer window expired")
+		return BatchDrop
+	}
+
+	var (
+		timestamp  = span.Timestamp
+		epochNum   = uint64(span.EpochNum)
+		prevEpoch  = epoch.Number
+		batchIndex = 0
+	)
+	for i, block := range span.Blocks {
+		timestamp += block.TimeDelta
+		epochNum += block.EpochDelta
+		log := log.New("block_index", i, "block_timestamp", timestamp, "block_epoch", epochNum)
+
+		if expected := nextTimestamp + uint64(i)*cfg.BlockTime; timestamp != expected {
+			log.Warn("dropping span batch with non-contiguous block timestamp", "expected", expected)
+			return BatchDrop
+		}
+		// Each block sticks to the epoch of the previous block or advances it by one.
+		if epochNum < prevEpoch {
+			log.Warn("dropped span batch, epoch is too old", "minimum", prevEpoch)
+			return BatchDrop
+		}
+		if epochNum > prevEpoch+1 {
+			log.Warn("span batch is for future epoch too far ahead, while it has the next timestamp, so it must be invalid", "previous_epoch", prevEpoch)
+			return BatchDrop
+		}
+		batchIndex = int(epochNum - epoch.Number)
+		if batchIndex >= len(l1Blocks) {
+			log.Info("eager span batch wants to advance epoch, but could not without more L1 blocks", "current_epoch", epoch.ID())
+			return BatchUndecided
+		}
+		batchOrigin := l1Blocks[batchIndex]
+
+		if timestamp < batchOrigin.Time {
+			log.Warn("span batch block timestamp is less than L1 origin timestamp", "l1_timestamp", batchOrigin.Time, "origin", batchOrigin.ID())
+			return BatchDrop
+		}
+
+		// Check if we ran out of proposer time drift
+		if max := batchOrigin.Time + cfg.MaxProposerDrift; timestamp > max {
+			if len(block.Transactions) > 0 {
+				log.Warn("span batch block exceeded proposer time drift, proposer must adopt new L1 origin to include transactions again", "max_time", max)
+				return BatchDrop
+			}
+			// The empty block is allowed only if it was the right thing to do to maintain the L2 time >= L1 time invariant.
+			if epochNum == prevEpoch {
+				if batchIndex+1 >= len(l1Blocks) {
+					log.Info("without the next L1 origin we cannot determine yet if this empty block that exceeds the time drift is still valid")
+					return BatchUndecided
+				}
+				if timestamp >= l1Blocks[batchIndex+1].Time {
+					log.Info("span batch block exceeded proposer time drift without adopting next origin, and next L1 origin would have been valid")
+					return BatchDrop
+				}
+			}
+		}
+
+		for k, txBytes := range block.Transactions {
+			if len(txBytes) == 0 {
+				log.Warn("transaction data must not be empty, but found empty tx", "tx_index", k)
+				return BatchDrop
+			}
+			if txBytes[0] == types.DepositTxType {
+				log.Warn("proposers may not embed any deposits into batch data, but found tx that has one", "tx_index", k)
+				return BatchDrop
+			}
+		}
+		prevEpoch = epochNum
+	}
+
+	if span.LastEpochHash != l1Blocks[batchIndex].Hash {
+		log.Warn("span batch is for different L1 chain, epoch hash does not match", "expected", l1Blocks[batchIndex].ID())
+		return BatchDrop
+	}
+
+	return BatchAccept
+}
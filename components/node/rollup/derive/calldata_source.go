@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -27,18 +28,21 @@ type L1TransactionFetcher interface {
 // batch submitter transactions.
 // This is not a stage in the pipeline, but a wrapper for another stage in the pipeline
 type DataSourceFactory struct {
-	log     log.Logger
-	cfg     *rollup.Config
-	fetcher L1TransactionFetcher
+	log      log.Logger
+	cfg      *rollup.Config
+	fetcher  L1TransactionFetcher
+	daClient DAClient
 }
 
-func NewDataSourceFactory(log log.Logger, cfg *rollup.Config, fetcher L1TransactionFetcher) *DataSourceFactory {
-	return &DataSourceFactory{log: log, cfg: cfg, fetcher: fetcher}
+// NewDataSourceFactory creates a data source factory. The DA client is used to resolve the
+// commitments to data stored by an external DA service, it may be nil if not configured.
+func NewDataSourceFactory(log log.Logger, cfg *rollup.Config, fetcher L1TransactionFetcher, daClient DAClient) *DataSourceFactory {
+	return &DataSourceFactory{log: log, cfg: cfg, fetcher: fetcher, daClient: daClient}
 }
 
 // OpenData returns a CalldataSourceImpl. This struct implements the `Next` function.
 func (ds *DataSourceFactory) OpenData(ctx context.Context, id eth.BlockID, batcherAddr common.Address) DataIter {
-	return NewDataSource(ctx, ds.log, ds.cfg, ds.fetcher, id, batcherAddr)
+	return NewDataSource(ctx, ds.log, ds.cfg, ds.fetcher, ds.daClient, id, batcherAddr)
 }
 
 // DataSource is a fault tolerant approach to fetching data.
@@ -48,44 +52,56 @@ type DataSource struct {
 	// Internal state + data
 	open bool
 	data []eth.Data
+	// whether the DA commitments are resolved, once the external DA fork is active
+	resolveDA bool
+	// l1Time is the time of the L1 block, which bounds the retries of the DA data not found
+	l1Time uint64
+	// now returns the current time, replaced in tests
+	now func() time.Time
 	// Required to re-attempt fetching
-	id      eth.BlockID
-	cfg     *rollup.Config // TODO: `DataFromEVMTransactions` should probably not take the full config
-	fetcher L1TransactionFetcher
-	log     log.Logger
+	id       eth.BlockID
+	cfg      *rollup.Config // TODO: `DataFromEVMTransactions` should probably not take the full config
+	fetcher  L1TransactionFetcher
+	daClient DAClient
+	log      log.Logger
 
 	batcherAddr common.Address
 }
 
 // NewDataSource creates a new calldata source. It suppresses errors in fetching the L1 block if they occur.
 // If there is an error, it will attempt to fetch the result on the next call to `Next`.
-func NewDataSource(ctx context.Context, log log.Logger, cfg *rollup.Config, fetcher L1TransactionFetcher, block eth.BlockID, batcherAddr common.Address) DataIter {
-	_, txs, err := fetcher.InfoAndTxsByHash(ctx, block.Hash)
-	if err != nil {
-		return &DataSource{
-			open:        false,
-			id:          block,
-			cfg:         cfg,
-			fetcher:     fetcher,
-			log:         log,
-			batcherAddr: batcherAddr,
-		}
-	} else {
-		return &DataSource{
-			open: true,
-			data: DataFromEVMTransactions(cfg, batcherAddr, txs, log.New("origin", block)),
-		}
+func NewDataSource(ctx context.Context, log log.Logger, cfg *rollup.Config, fetcher L1TransactionFetcher, daClient DAClient, block eth.BlockID, batcherAddr common.Address) DataIter {
+	ds := &DataSource{
+		id:          block,
+		cfg:         cfg,
+		fetcher:     fetcher,
+		daClient:    daClient,
+		log:         log.New("origin", block),
+		batcherAddr: batcherAddr,
+		now:         time.Now,
 	}
+	if info, txs, err := fetcher.InfoAndTxsByHash(ctx, block.Hash); err == nil {
+		ds.openData(info, txs)
+	}
+	return ds
+}
+
+func (ds *DataSource) openData(info eth.BlockInfo, txs types.Transactions) {
+	ds.open = true
+	ds.data = DataFromEVMTransactions(ds.cfg, ds.batcherAddr, txs, ds.log)
+	ds.resolveDA = ds.cfg.IsExternalDAFork(info.Time())
+	ds.l1Time = info.Time()
 }
 
 // Next returns the next piece of data if it has it. If the constructor failed, this
 // will attempt to reinitialize itself. If it cannot find the block it returns a ResetError
 // otherwise it returns a temporary error if fetching the block returns an error.
+// The DA commitments are resolved to the data they commit to. A temporary error is returned
+// as well if the data can't be fetched yet, see resolveCommitment.
 func (ds *DataSource) Next(ctx context.Context) (eth.Data, error) {
 	if !ds.open {
-		if _, txs, err := ds.fetcher.InfoAndTxsByHash(ctx, ds.id.Hash); err == nil {
-			ds.open = true
-			ds.data = DataFromEVMTransactions(ds.cfg, ds.batcherAddr, txs, log.New("origin", ds.id))
+		if info, txs, err := ds.fetcher.InfoAndTxsByHash(ctx, ds.id.Hash); err == nil {
+			ds.openData(info, txs)
 		} else if errors.Is(err, ethereum.NotFound) {
 			return nil, NewResetError(fmt.Errorf("failed to open calldata source: %w", err))
 		} else {
@@ -94,11 +110,53 @@ func (ds *DataSource) Next(ctx context.Context) (eth.Data, error) {
 	}
 	if len(ds.data) == 0 {
 		return nil, io.EOF
-	} else {
-		data := ds.data[0]
-		ds.data = ds.data[1:]
+	}
+
+	data := ds.data[0]
+	if ds.resolveDA && IsDACommitmentData(data) {
+		resolved, err := ds.resolveCommitment(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		data = resolved
+	}
+	ds.data = ds.data[1:]
+	return data, nil
+}
+
+// resolveCommitment fetches and verifies the data of the DA commitment. An invalid commitment
+// is returned as is, to be dropped like any other invalid data. So is a commitment whose data
+// does not match it or is too large, as fetching it again doesn't change the result.
+// The data which is not found is fetched again until DAResolveWindow has passed since the L1
+// block, and dropped afterwards. Any other error of the DA client is temporary.
+func (ds *DataSource) resolveCommitment(ctx context.Context, data eth.Data) (eth.Data, error) {
+	comm, err := DecodeDACommitment(data[1:])
+	if err != nil {
+		ds.log.Warn("ignoring invalid DA commitment", "err", err)
+		return data, nil
+	}
+	if ds.daClient == nil {
+		return nil, NewCriticalError(fmt.Errorf("failed to resolve DA commitment %s: %w", comm, ErrDAClientNotConfigured))
+	}
+	input, err := ds.daClient.GetInput(ctx, comm)
+	if errors.Is(err, ErrDACommitmentNotFound) {
+		deadline := time.Unix(int64(ds.l1Time), 0).Add(DAResolveWindow)
+		if ds.now().After(deadline) {
+			ds.log.Warn("ignoring DA commitment, data not found within the resolve window", "commitment", comm, "deadline", deadline)
+			return data, nil
+		}
+		return nil, NewTemporaryError(fmt.Errorf("failed to fetch data of DA commitment %s: %w", comm, err))
+	} else if errors.Is(err, ErrDAInputTooLarge) {
+		ds.log.Warn("ignoring DA commitment, data is too large", "commitment", comm, "err", err)
+		return data, nil
+	} else if err != nil {
+		return nil, NewTemporaryError(fmt.Errorf("failed to fetch data of DA commitment %s: %w", comm, err))
+	}
+	if err := comm.Verify(input); err != nil {
+		ds.log.Warn("ignoring DA commitment, data does not match", "commitment", comm, "err", err)
 		return data, nil
 	}
+	return input, nil
 }
 
 // DataFromEVMTransactions filters all of the transactions and returns the calldata from transactions
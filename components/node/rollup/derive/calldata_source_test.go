@@ -1,10 +1,14 @@
 package derive
 
 import (
+	"context"
 	"crypto/ecdsa"
+	"errors"
+	"io"
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -125,3 +129,164 @@ func TestDataFromEVMTransactions(t *testing.T) {
 	}
 
 }
+
+type testDAClient map[DACommitment][]byte
+
+// errDAClient fails to fetch the data of any commitment with the given error.
+type errDAClient struct {
+	err error
+}
+
+func (c errDAClient) GetInput(ctx context.Context, comm DACommitment) ([]byte, error) {
+	return nil, c.err
+}
+
+func (c testDAClient) GetInput(ctx context.Context, comm DACommitment) ([]byte, error) {
+	data, ok := c[comm]
+	if !ok {
+		return nil, ErrDACommitmentNotFound
+	}
+	return data, nil
+}
+
+// TestDataSourceDACommitments asserts that the DA commitments are resolved to the data
+// they commit to once the external DA fork is active. The data which can't be fetched yet is
+// not skipped, while the data which does not match or is not found within DAResolveWindow is dropped.
+func TestDataSourceDACommitments(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	batcherPriv := testutils.RandomKey()
+	batcherAddr := crypto.PubkeyToAddress(batcherPriv.PublicKey)
+	forkTime := uint64(1000)
+	cfg := &rollup.Config{
+		L1ChainID:          big.NewInt(100),
+		BatchInboxAddress:  testutils.RandomAddress(rng),
+		ExternalDAForkTime: &forkTime,
+	}
+	signer := cfg.L1Signer()
+
+	calldata := append([]byte{DerivationVersion0}, testutils.RandomData(rng, 100)...)
+	input := append([]byte{DerivationVersion0}, testutils.RandomData(rng, 100)...)
+	comm := NewKeccak256Commitment(input)
+
+	newTx := func(data []byte) *types.Transaction {
+		tx, err := types.SignNewTx(batcherPriv, signer, &types.DynamicFeeTx{
+			ChainID:   signer.ChainID(),
+			GasTipCap: big.NewInt(2 * params.GWei),
+			GasFeeCap: big.NewInt(30 * params.GWei),
+			Gas:       100_000,
+			To:        &cfg.BatchInboxAddress,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return tx
+	}
+	txs := types.Transactions{newTx(calldata), newTx(comm.TxData())}
+
+	openData := func(l1Time uint64, daClient DAClient) DataIter {
+		info := &testutils.MockBlockInfo{InfoHash: testutils.RandomHash(rng), InfoTime: l1Time}
+		fetcher := &testutils.MockL1Source{}
+		fetcher.ExpectInfoAndTxsByHash(info.Hash(), info, txs, nil)
+		return NewDataSource(context.Background(), testlog.Logger(t, log.LvlCrit), cfg, fetcher, daClient, info.ID(), batcherAddr)
+	}
+	next := func(src DataIter) (eth.Data, error) {
+		return src.Next(context.Background())
+	}
+	// dropped asserts that the commitment is returned as is, which is dropped by the frame queue.
+	dropped := func(t *testing.T, src DataIter) {
+		data, err := next(src)
+		require.NoError(t, err)
+		require.Equal(t, eth.Data(comm.TxData()), data)
+		_, err = ParseFrames(data)
+		require.Error(t, err)
+		_, err = next(src)
+		require.ErrorIs(t, err, io.EOF)
+	}
+
+	t.Run("before fork", func(t *testing.T) {
+		src := openData(forkTime-1, nil)
+		data, err := next(src)
+		require.NoError(t, err)
+		require.Equal(t, eth.Data(calldata), data)
+		data, err = next(src)
+		require.NoError(t, err)
+		require.Equal(t, eth.Data(comm.TxData()), data)
+		_, err = next(src)
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("resolved", func(t *testing.T) {
+		src := openData(forkTime, testDAClient{comm: input})
+		data, err := next(src)
+		require.NoError(t, err)
+		require.Equal(t, eth.Data(calldata), data)
+		data, err = next(src)
+		require.NoError(t, err)
+		require.Equal(t, eth.Data(input), data)
+		_, err = next(src)
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		daClient := testDAClient{}
+		src := openData(forkTime, daClient)
+		src.(*DataSource).now = func() time.Time { return time.Unix(int64(forkTime), 0) }
+		_, err := next(src)
+		require.NoError(t, err)
+		_, err = next(src)
+		require.ErrorIs(t, err, ErrTemporary)
+		require.ErrorIs(t, err, ErrDACommitmentNotFound)
+
+		// the commitment is resolved again once the data is available
+		daClient[comm] = input
+		data, err := next(src)
+		require.NoError(t, err)
+		require.Equal(t, eth.Data(input), data)
+	})
+
+	t.Run("not found after window", func(t *testing.T) {
+		src := openData(forkTime, testDAClient{})
+		l1Time := time.Unix(int64(forkTime), 0)
+		src.(*DataSource).now = func() time.Time { return l1Time.Add(DAResolveWindow) }
+		_, err := next(src)
+		require.NoError(t, err)
+		_, err = next(src)
+		require.ErrorIs(t, err, ErrTemporary)
+
+		src.(*DataSource).now = func() time.Time { return l1Time.Add(DAResolveWindow + time.Second) }
+		dropped(t, src)
+	})
+
+	t.Run("fetch failed", func(t *testing.T) {
+		fetchErr := errors.New("connection refused")
+		src := openData(forkTime, errDAClient{err: fetchErr})
+		src.(*DataSource).now = func() time.Time { return time.Unix(int64(forkTime), 0).Add(2 * DAResolveWindow) }
+		_, err := next(src)
+		require.NoError(t, err)
+		_, err = next(src)
+		require.ErrorIs(t, err, ErrTemporary)
+		require.ErrorIs(t, err, fetchErr)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		src := openData(forkTime, errDAClient{err: ErrDAInputTooLarge})
+		_, err := next(src)
+		require.NoError(t, err)
+		dropped(t, src)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		src := openData(forkTime, testDAClient{comm: calldata})
+		_, err := next(src)
+		require.NoError(t, err)
+		dropped(t, src)
+	})
+
+	t.Run("no client", func(t *testing.T) {
+		src := openData(forkTime, nil)
+		_, err := next(src)
+		require.NoError(t, err)
+		_, err = next(src)
+		require.ErrorIs(t, err, ErrCritical)
+		require.ErrorIs(t, err, ErrDAClientNotConfigured)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
 )
 
 // Channel In Reader reads a batch from the channel
@@ -17,6 +18,7 @@ import (
 
 type ChannelInReader struct {
 	log log.Logger
+	cfg *rollup.Config
 
 	nextBatchFn func() (BatchWithL1InclusionBlock, error)
 
@@ -26,9 +28,10 @@ type ChannelInReader struct {
 var _ ResetableStage = (*ChannelInReader)(nil)
 
 // NewChannelInReader creates a ChannelInReader, which should be Reset(origin) before use.
-func NewChannelInReader(log log.Logger, prev *ChannelBank) *ChannelInReader {
+func NewChannelInReader(log log.Logger, cfg *rollup.Config, prev *ChannelBank) *ChannelInReader {
 	return &ChannelInReader{
 		log:  log,
+		cfg:  cfg,
 		prev: prev,
 	}
 }
@@ -39,7 +42,8 @@ func (cr *ChannelInReader) Origin() eth.L1BlockRef {
 
 // TODO: Take full channel for better logging
 func (cr *ChannelInReader) WriteChannel(data []byte) error {
-	if f, err := BatchReader(bytes.NewBuffer(data), cr.Origin()); err == nil {
+	origin := cr.Origin()
+	if f, err := BatchReader(bytes.NewBuffer(data), origin, cr.cfg.IsCompressionFork(origin.Time)); err == nil {
 		cr.nextBatchFn = f
 		return nil
 	} else {
@@ -2,12 +2,12 @@ package derive
 
 import (
 	"bytes"
-	"compress/zlib"
 	"crypto/rand"
 	"errors"
 	"fmt"
 	"io"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -18,6 +18,10 @@ import (
 var ErrNotDepositTx = errors.New("first transaction in block is not a deposit tx")
 var ErrTooManyRLPBytes = errors.New("batch would cause RLP bytes to go over limit")
 
+// spanBatchOverhead is the upper bound of the encoded size of a span batch without its
+// blocks, i.e. the batch type, the header fields and the RLP list headers.
+const spanBatchOverhead = 128
+
 type ChannelOut struct {
 	id ChannelID
 	// Frame ID of the next frame to emit. Increment after emitting
@@ -25,8 +29,19 @@ type ChannelOut struct {
 	// rlpLength is the uncompressed size of the channel. Must be less than MAX_RLP_BYTES_PER_CHANNEL
 	rlpLength int
 
+	// Compression algorithm. Written as the first byte of the channel if versioned
+	algo      CompressionAlgo
+	versioned bool
 	// Compressor stage. Write input data to it
-	compress *zlib.Writer
+	compress compressor
+	// Whether the blocks are added to a single span batch, which is written
+	// to the compressor stage when the channel is closed
+	useSpan bool
+	span    *SpanBatch
+	// L2 block time and hash of the last block of the span batch, which the
+	// next block must extend
+	blockTime uint64
+	spanTip   common.Hash
 	// post compression buffer
 	buf bytes.Buffer
 
@@ -37,22 +52,37 @@ func (co *ChannelOut) ID() ChannelID {
 	return co.id
 }
 
+// NewChannelOut creates a channel out in the legacy format, which is a zlib stream
+// without the compression algorithm byte.
 func NewChannelOut() (*ChannelOut, error) {
+	return newChannelOut(Zlib, false)
+}
+
+// NewVersionedChannelOut creates a channel out in the versioned format, which is only
+// valid once the compression fork is active.
+func NewVersionedChannelOut(algo CompressionAlgo) (*ChannelOut, error) {
+	return newChannelOut(algo, true)
+}
+
+func newChannelOut(algo CompressionAlgo, versioned bool) (*ChannelOut, error) {
 	c := &ChannelOut{
 		id:        ChannelID{}, // TODO: use GUID here instead of fully random data
 		frame:     0,
 		rlpLength: 0,
+		algo:      algo,
+		versioned: versioned,
 	}
 	_, err := rand.Read(c.id[:])
 	if err != nil {
 		return nil, err
 	}
 
-	compress, err := zlib.NewWriterLevel(&c.buf, zlib.BestCompression)
+	compress, err := newCompressor(algo, &c.buf)
 	if err != nil {
 		return nil, err
 	}
 	c.compress = compress
+	c.writeVersion()
 
 	return c, nil
 }
@@ -63,11 +93,36 @@ func (co *ChannelOut) Reset() error {
 	co.rlpLength = 0
 	co.buf.Reset()
 	co.compress.Reset(&co.buf)
+	co.writeVersion()
+	co.span = nil
+	co.spanTip = common.Hash{}
 	co.closed = false
 	_, err := rand.Read(co.id[:])
 	return err
 }
 
+// writeVersion writes the compression algorithm byte in front of the compressed data
+// if the channel is versioned.
+func (co *ChannelOut) writeVersion() {
+	if co.versioned {
+		co.buf.WriteByte(byte(co.algo))
+	}
+}
+
+// UseSpanBatch makes the channel add all blocks to a single span batch, which is
+// written when the channel is closed, so no frames are ready before. Span batches
+// are only valid once the span batch fork is active. The blocks must be contiguous
+// with the given L2 block time, see AddBlockBatch.
+// It must be called before any block is added.
+func (co *ChannelOut) UseSpanBatch(blockTime uint64) error {
+	if co.rlpLength > 0 {
+		return errors.New("blocks already added")
+	}
+	co.useSpan = true
+	co.blockTime = blockTime
+	return nil
+}
+
 // AddBlock adds a block to the channel. It returns the RLP encoded byte size
 // and an error if there is a problem adding the block. The only sentinel error
 // that it returns is ErrTooManyRLPBytes. If this error is returned, the channel
@@ -81,7 +136,7 @@ func (co *ChannelOut) AddBlock(block *types.Block) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return co.AddBatch(batch)
+	return co.AddBlockBatch(batch, block.Hash())
 }
 
 // AddBatch adds a batch to the channel. It returns the RLP encoded byte size
@@ -92,10 +147,27 @@ func (co *ChannelOut) AddBlock(block *types.Block) (uint64, error) {
 // AddBatch should be used together with BlockToBatch if you need to access the
 // BatchData before adding a block to the channel. It isn't possible to access
 // the batch data with AddBlock.
+//
+// A span batch channel needs the hash of the block of the batch, so its batches
+// must be added with AddBlockBatch.
 func (co *ChannelOut) AddBatch(batch *BatchData) (uint64, error) {
+	if co.useSpan {
+		return 0, errors.New("span batch blocks must be added with their hash")
+	}
+	return co.AddBlockBatch(batch, common.Hash{})
+}
+
+// AddBlockBatch adds the batch of the block with the given hash to the channel, like
+// AddBatch. If the channel uses a span batch, the batch must extend the last block,
+// or ErrSpanBatchNotContiguous is returned, in which case the channel should be
+// closed and the block added to a new one.
+func (co *ChannelOut) AddBlockBatch(batch *BatchData, blockHash common.Hash) (uint64, error) {
 	if co.closed {
 		return 0, errors.New("already closed")
 	}
+	if co.useSpan {
+		return co.addToSpanBatch(batch, blockHash)
+	}
 
 	// We encode to a temporary buffer to determine the encoded length to
 	// ensure that the total size of all RLP elements is less than or equal to MAX_RLP_BYTES_PER_CHANNEL
@@ -113,6 +185,37 @@ func (co *ChannelOut) AddBatch(batch *BatchData) (uint64, error) {
 	return uint64(written), err
 }
 
+// addToSpanBatch appends the batch to the span batch of the channel. It returns the
+// RLP encoded byte size of the block, and of the span batch overhead for the first block.
+func (co *ChannelOut) addToSpanBatch(batch *BatchData, blockHash common.Hash) (uint64, error) {
+	block, overhead := SpanBatchBlock{Transactions: batch.Transactions}, spanBatchOverhead
+	if co.span != nil {
+		var err error
+		if block, err = co.span.NextBlock(&batch.BatchV1, co.spanTip, co.blockTime); err != nil {
+			return 0, err
+		}
+		overhead = 0
+	}
+	enc, err := rlp.EncodeToBytes(&block)
+	if err != nil {
+		return 0, err
+	}
+	size := len(enc) + overhead
+	if co.rlpLength+size > MaxRLPBytesPerChannel {
+		return 0, fmt.Errorf("could not add %d bytes to channel of %d bytes, max is %d. err: %w",
+			size, co.rlpLength, MaxRLPBytesPerChannel, ErrTooManyRLPBytes)
+	}
+	co.rlpLength += size
+
+	if co.span == nil {
+		co.span = NewSpanBatch(&batch.BatchV1)
+	} else if err := co.span.Append(&batch.BatchV1, co.spanTip, co.blockTime); err != nil {
+		return 0, err
+	}
+	co.spanTip = blockHash
+	return uint64(size), nil
+}
+
 // InputBytes returns the total amount of RLP-encoded input bytes.
 func (co *ChannelOut) InputBytes() int {
 	return co.rlpLength
@@ -136,6 +239,11 @@ func (co *ChannelOut) Close() error {
 		return errors.New("already closed")
 	}
 	co.closed = true
+	if co.span != nil {
+		if err := rlp.Encode(co.compress, &BatchData{Span: co.span}); err != nil {
+			return err
+		}
+	}
 	return co.compress.Close()
 }
 
@@ -205,7 +313,7 @@ func BlockToBatch(block *types.Block) (*BatchData, L1BlockInfo, error) {
 	}
 
 	return &BatchData{
-		BatchV1{
+		BatchV1: BatchV1{
 			ParentHash:   block.ParentHash(),
 			EpochNum:     rollup.Epoch(l1Info.Number),
 			EpochHash:    l1Info.BlockHash,
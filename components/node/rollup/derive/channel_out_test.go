@@ -2,13 +2,18 @@ package derive
 
 import (
 	"bytes"
+	"io"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
 )
 
 func TestChannelOutAddBlock(t *testing.T) {
@@ -29,6 +34,70 @@ func TestChannelOutAddBlock(t *testing.T) {
 	})
 }
 
+func TestChannelOutSpanBatch(t *testing.T) {
+	co, err := NewVersionedChannelOut(Zlib)
+	require.NoError(t, err)
+	require.NoError(t, co.UseSpanBatch(2))
+
+	newBatch := func(i int) *BatchData {
+		return &BatchData{BatchV1: BatchV1{
+			ParentHash:   common.Hash{byte(i)},
+			EpochNum:     rollup.Epoch(10 + i/2),
+			EpochHash:    common.Hash{0xee, byte(10 + i/2)},
+			Timestamp:    uint64(100 + 2*i),
+			Transactions: []hexutil.Bytes{{0x02, byte(i)}},
+		}}
+	}
+	var batches []*BatchData
+	for i := 0; i < 4; i++ {
+		batch := newBatch(i)
+		_, err := co.AddBlockBatch(batch, common.Hash{byte(i + 1)})
+		require.NoError(t, err)
+		batches = append(batches, batch)
+	}
+	require.Error(t, co.UseSpanBatch(2), "blocks already added")
+	_, err = co.AddBatch(newBatch(4))
+	require.Error(t, err, "span batch blocks must be added with their hash")
+
+	// The next block must extend the last block by the block time
+	wrongParent := newBatch(4)
+	wrongParent.ParentHash = common.Hash{0xff}
+	_, err = co.AddBlockBatch(wrongParent, common.Hash{5})
+	require.ErrorIs(t, err, ErrSpanBatchNotContiguous)
+	wrongTime := newBatch(4)
+	wrongTime.Timestamp += 2
+	_, err = co.AddBlockBatch(wrongTime, common.Hash{5})
+	require.ErrorIs(t, err, ErrSpanBatchNotContiguous)
+	oldTime := newBatch(4)
+	oldTime.Timestamp = batches[0].Timestamp
+	_, err = co.AddBlockBatch(oldTime, common.Hash{5})
+	require.ErrorIs(t, err, ErrSpanBatchNotContiguous)
+
+	require.NoError(t, co.Close())
+
+	var buf bytes.Buffer
+	_, err = co.OutputFrame(&buf, MaxRLPBytesPerChannel)
+	require.ErrorIs(t, err, io.EOF)
+	var frame Frame
+	require.NoError(t, frame.UnmarshalBinary(&buf))
+
+	br, err := BatchReader(bytes.NewReader(frame.Data), eth.L1BlockRef{}, true)
+	require.NoError(t, err)
+	batch, err := br()
+	require.NoError(t, err)
+	require.True(t, batch.Batch.IsSpan())
+	span := batch.Batch.Span
+	require.Equal(t, batches[0].ParentHash, span.ParentHash)
+	require.Equal(t, batches[0].Timestamp, span.FirstTimestamp())
+	require.Equal(t, batches[0].EpochNum, span.FirstEpochNum())
+	require.Equal(t, batches[3].Timestamp, span.LastTimestamp())
+	require.Equal(t, batches[3].EpochNum, span.LastEpochNum())
+	require.Equal(t, batches[3].EpochHash, span.LastEpochHash)
+	require.Equal(t, 4, span.TxCount())
+	_, err = br()
+	require.ErrorIs(t, err, io.EOF)
+}
+
 // TestRLPByteLimit ensures that stream encoder is properly limiting the length.
 // It will decode the input if `len(input) <= inputLimit`.
 func TestRLPByteLimit(t *testing.T) {
@@ -0,0 +1,147 @@
+package derive
+
+import (
+	"bufio"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionAlgo is the algorithm the channel data is compressed with.
+//
+// Before the compression fork, the channel data is a plain zlib stream. Once the fork
+// is active, the channel data may be prefixed with the algorithm byte. The algorithm
+// bytes never have 8 as their lower nibble, so that they can't be confused with the
+// first byte of a zlib stream, which is still accepted after the fork.
+//
+// The algorithms are registered in compressionCodecs by their byte. The bytes which are
+// not registered are reserved:
+//   - 0x02 for a brotli-style algorithm.
+//   - 0x03 to 0x07 for future algorithms.
+//   - 0x80 to 0xff for experimental algorithms, which are never accepted by the derivation.
+type CompressionAlgo uint8
+
+const (
+	Zlib CompressionAlgo = 0x00
+	Zstd CompressionAlgo = 0x01
+)
+
+var ErrUnknownCompressionAlgo = errors.New("unknown compression algorithm")
+
+// compressionCodec creates the compression stages of an algorithm.
+type compressionCodec struct {
+	name string
+	// newCompressor returns the compression stage of a ChannelOut writing to w.
+	newCompressor func(w io.Writer) (compressor, error)
+	// newDecompressor returns a reader over the data decompressed from r.
+	newDecompressor func(r io.Reader) (io.Reader, error)
+}
+
+// compressionCodecs are the supported compression algorithms. Adding an algorithm changes
+// the derivation, so it must only be accepted by the derivation from a new fork onwards.
+var compressionCodecs = map[CompressionAlgo]compressionCodec{
+	Zlib: {
+		name: "zlib",
+		newCompressor: func(w io.Writer) (compressor, error) {
+			return zlib.NewWriterLevel(w, zlib.BestCompression)
+		},
+		newDecompressor: func(r io.Reader) (io.Reader, error) {
+			return zlib.NewReader(r)
+		},
+	},
+	Zstd: {
+		name: "zstd",
+		newCompressor: func(w io.Writer) (compressor, error) {
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression), zstd.WithEncoderConcurrency(1))
+		},
+		newDecompressor: func(r io.Reader) (io.Reader, error) {
+			// Decode synchronously, so that no goroutines are left behind if the reader is dropped.
+			dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(MaxRLPBytesPerChannel))
+			if err != nil {
+				return nil, err
+			}
+			return dec, nil
+		},
+	},
+}
+
+// CompressionAlgos are all the supported compression algorithms, ordered by their byte.
+var CompressionAlgos = func() []CompressionAlgo {
+	algos := make([]CompressionAlgo, 0, len(compressionCodecs))
+	for algo := range compressionCodecs {
+		algos = append(algos, algo)
+	}
+	sort.Slice(algos, func(i, j int) bool { return algos[i] < algos[j] })
+	return algos
+}()
+
+func (a CompressionAlgo) String() string {
+	if codec, ok := compressionCodecs[a]; ok {
+		return codec.name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(a))
+}
+
+// IsValid returns whether the compression algorithm is supported.
+func (a CompressionAlgo) IsValid() bool {
+	_, ok := compressionCodecs[a]
+	return ok
+}
+
+// ParseCompressionAlgo parses the name of a compression algorithm.
+func ParseCompressionAlgo(s string) (CompressionAlgo, error) {
+	for _, algo := range CompressionAlgos {
+		if algo.String() == s {
+			return algo, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownCompressionAlgo, s)
+}
+
+// compressor is the compression stage of a ChannelOut.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+func newCompressor(algo CompressionAlgo, w io.Writer) (compressor, error) {
+	codec, ok := compressionCodecs[algo]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCompressionAlgo, algo)
+	}
+	return codec.newCompressor(w)
+}
+
+// newDecompressor returns a reader over the decompressed channel data.
+// If the channel format is versioned, the algorithm is read from the first byte,
+// unless the data is a legacy zlib stream.
+func newDecompressor(r io.Reader, versioned bool) (io.Reader, error) {
+	if !versioned {
+		return zlib.NewReader(r)
+	}
+
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	// The compression method of a zlib stream is stored in the lower nibble of its first byte.
+	if prefix[0]&0x0f == 8 {
+		return zlib.NewReader(br)
+	}
+	if _, err := br.Discard(1); err != nil {
+		return nil, err
+	}
+
+	algo := CompressionAlgo(prefix[0])
+	codec, ok := compressionCodecs[algo]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCompressionAlgo, algo)
+	}
+	return codec.newDecompressor(br)
+}
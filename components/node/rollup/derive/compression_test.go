@@ -0,0 +1,53 @@
+package derive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompressionAlgos checks that the registered algorithms can't be confused with a
+// zlib stream, don't use the reserved bytes, and round trip the data.
+func TestCompressionAlgos(t *testing.T) {
+	reserved := map[CompressionAlgo]bool{0x02: true, 0x03: true, 0x04: true, 0x05: true, 0x06: true, 0x07: true}
+	data := bytes.Repeat([]byte("kanvas"), 100)
+
+	require.Equal(t, []CompressionAlgo{Zlib, Zstd}, CompressionAlgos)
+	for _, algo := range CompressionAlgos {
+		t.Run(algo.String(), func(t *testing.T) {
+			require.NotEqual(t, byte(8), byte(algo)&0x0f, "algorithm byte looks like a zlib stream")
+			require.False(t, reserved[algo], "algorithm byte is reserved")
+			require.Less(t, byte(algo), byte(0x80), "algorithm byte is experimental")
+
+			parsed, err := ParseCompressionAlgo(algo.String())
+			require.NoError(t, err)
+			require.Equal(t, algo, parsed)
+
+			var buf bytes.Buffer
+			buf.WriteByte(byte(algo))
+			c, err := newCompressor(algo, &buf)
+			require.NoError(t, err)
+			_, err = c.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, c.Close())
+
+			r, err := newDecompressor(&buf, true)
+			require.NoError(t, err)
+			out, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, data, out)
+		})
+	}
+}
+
+func TestCompressionAlgoUnknown(t *testing.T) {
+	_, err := newCompressor(0x02, io.Discard)
+	require.ErrorIs(t, err, ErrUnknownCompressionAlgo)
+	_, err = newDecompressor(bytes.NewReader([]byte{0x02, 0x00}), true)
+	require.ErrorIs(t, err, ErrUnknownCompressionAlgo)
+	_, err = ParseCompressionAlgo("brotli")
+	require.ErrorIs(t, err, ErrUnknownCompressionAlgo)
+	require.False(t, CompressionAlgo(0x02).IsValid())
+}
@@ -0,0 +1,127 @@
+package derive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Once the external DA fork is active, the data of a batcher transaction can be stored by an
+// external data availability service, while the transaction only carries a commitment to it:
+//
+// data       = DACommitmentVersion ++ commitment
+// commitment = commitment_type ++ commitment_data
+//
+// For the Keccak256CommitmentType, the commitment data is the keccak256 hash of the original
+// transaction data, which is DerivationVersion0 ++ Frame(s).
+
+// DACommitmentVersion is the version byte of the transaction data carrying a DA commitment.
+const DACommitmentVersion = 1
+
+// DAResolveWindow is how long after the L1 block including a DA commitment its data is fetched
+// again if the DA service doesn't store it. The commitment is dropped afterwards, so that a
+// commitment to unavailable data does not stall the derivation.
+const DAResolveWindow = 12 * time.Hour
+
+// DACommitmentType is the type of a DA commitment, which determines how it's verified.
+type DACommitmentType byte
+
+const Keccak256CommitmentType DACommitmentType = 0
+
+var (
+	ErrInvalidDACommitment     = errors.New("invalid DA commitment")
+	ErrDACommitmentMismatch    = errors.New("data does not match DA commitment")
+	ErrDACommitmentNotFound    = errors.New("data of DA commitment not found")
+	ErrDAInputTooLarge         = errors.New("data of DA commitment is too large")
+	ErrDAClientNotConfigured   = errors.New("no DA client configured")
+	ErrUnknownDACommitmentType = errors.New("unknown DA commitment type")
+)
+
+// DAClient fetches the data of the commitments from the external data availability service.
+type DAClient interface {
+	// GetInput returns the data of the commitment. It's not verified against the commitment.
+	// It returns ErrDACommitmentNotFound if the service doesn't store the data,
+	// and ErrDAInputTooLarge if the data is larger than the client accepts.
+	GetInput(ctx context.Context, comm DACommitment) ([]byte, error)
+}
+
+// DACommitment is a commitment to data stored by an external data availability service.
+type DACommitment struct {
+	Type DACommitmentType
+	Hash common.Hash
+}
+
+// NewKeccak256Commitment creates the keccak256 commitment to the given data.
+func NewKeccak256Commitment(data []byte) DACommitment {
+	return DACommitment{Type: Keccak256CommitmentType, Hash: crypto.Keccak256Hash(data)}
+}
+
+// DecodeDACommitment decodes an encoded commitment, as returned by Encode.
+func DecodeDACommitment(data []byte) (DACommitment, error) {
+	if len(data) == 0 {
+		return DACommitment{}, fmt.Errorf("%w: empty", ErrInvalidDACommitment)
+	}
+	switch typ := DACommitmentType(data[0]); typ {
+	case Keccak256CommitmentType:
+		if len(data) != 1+common.HashLength {
+			return DACommitment{}, fmt.Errorf("%w: invalid length %d", ErrInvalidDACommitment, len(data))
+		}
+		return DACommitment{Type: typ, Hash: common.BytesToHash(data[1:])}, nil
+	default:
+		return DACommitment{}, fmt.Errorf("%w: %d", ErrUnknownDACommitmentType, typ)
+	}
+}
+
+// Encode returns the commitment type followed by the commitment data.
+func (c DACommitment) Encode() []byte {
+	return append([]byte{byte(c.Type)}, c.Hash.Bytes()...)
+}
+
+// TxData returns the data of the batcher transaction carrying the commitment.
+func (c DACommitment) TxData() []byte {
+	return append([]byte{DACommitmentVersion}, c.Encode()...)
+}
+
+// Verify checks that the given data matches the commitment.
+func (c DACommitment) Verify(data []byte) error {
+	switch c.Type {
+	case Keccak256CommitmentType:
+		if hash := crypto.Keccak256Hash(data); hash != c.Hash {
+			return fmt.Errorf("%w: got hash %s, expected %s", ErrDACommitmentMismatch, hash, c.Hash)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownDACommitmentType, c.Type)
+	}
+}
+
+func (c DACommitment) String() string {
+	return hexutil.Encode(c.Encode())
+}
+
+func (c DACommitment) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *DACommitment) UnmarshalText(text []byte) error {
+	data, err := hexutil.Decode(string(text))
+	if err != nil {
+		return err
+	}
+	comm, err := DecodeDACommitment(data)
+	if err != nil {
+		return err
+	}
+	*c = comm
+	return nil
+}
+
+// IsDACommitmentData returns whether the batcher transaction data carries a DA commitment.
+func IsDACommitmentData(data []byte) bool {
+	return len(data) > 0 && data[0] == DACommitmentVersion
+}
@@ -0,0 +1,46 @@
+package derive
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/testutils"
+)
+
+func TestDACommitmentRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	data := testutils.RandomData(rng, 1000)
+	comm := NewKeccak256Commitment(data)
+
+	txData := comm.TxData()
+	require.True(t, IsDACommitmentData(txData))
+	decoded, err := DecodeDACommitment(txData[1:])
+	require.NoError(t, err)
+	require.Equal(t, comm, decoded)
+	require.NoError(t, decoded.Verify(data))
+
+	text, err := comm.MarshalText()
+	require.NoError(t, err)
+	var unmarshaled DACommitment
+	require.NoError(t, unmarshaled.UnmarshalText(text))
+	require.Equal(t, comm, unmarshaled)
+}
+
+func TestDACommitmentInvalid(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	data := testutils.RandomData(rng, 1000)
+	comm := NewKeccak256Commitment(data)
+
+	require.ErrorIs(t, comm.Verify(data[1:]), ErrDACommitmentMismatch)
+
+	_, err := DecodeDACommitment(nil)
+	require.ErrorIs(t, err, ErrInvalidDACommitment)
+	_, err = DecodeDACommitment(comm.Encode()[:20])
+	require.ErrorIs(t, err, ErrInvalidDACommitment)
+	_, err = DecodeDACommitment(append([]byte{0xff}, comm.Hash[:]...))
+	require.ErrorIs(t, err, ErrUnknownDACommitmentType)
+
+	require.False(t, IsDACommitmentData(append([]byte{DerivationVersion0}, data...)))
+}
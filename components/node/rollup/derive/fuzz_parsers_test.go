@@ -2,12 +2,14 @@ package derive
 
 import (
 	"bytes"
+	"io"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -18,6 +20,7 @@ import (
 
 	"github.com/wemixkanvas/kanvas/bindings/bindings"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
 	"github.com/wemixkanvas/kanvas/components/node/testutils"
 )
 
@@ -287,3 +290,101 @@ func FuzzUnmarshallLogEvent(f *testing.F) {
 		}
 	})
 }
+
+// FuzzChannelRoundTripLegacy checks that batches round trip through a channel in the
+// legacy zlib format, which is read before and after the compression fork.
+func FuzzChannelRoundTripLegacy(f *testing.F) {
+	fuzzChannelRoundTrip(f, NewChannelOut, false, true)
+}
+
+// FuzzChannelRoundTripZlib checks that batches round trip through a versioned zlib channel.
+func FuzzChannelRoundTripZlib(f *testing.F) {
+	fuzzChannelRoundTrip(f, func() (*ChannelOut, error) { return NewVersionedChannelOut(Zlib) }, true)
+}
+
+// FuzzChannelRoundTripZstd checks that batches round trip through a versioned zstd channel.
+func FuzzChannelRoundTripZstd(f *testing.F) {
+	fuzzChannelRoundTrip(f, func() (*ChannelOut, error) { return NewVersionedChannelOut(Zstd) }, true)
+}
+
+// fuzzChannelRoundTrip adds fuzzed batches to a channel created by newChannelOut, outputs
+// its frames and checks that the batches read back from the frame data with each of the
+// given channel formats are equal to the added ones.
+func fuzzChannelRoundTrip(f *testing.F, newChannelOut func() (*ChannelOut, error), versioned ...bool) {
+	f.Add(common.Hash{1}.Bytes(), uint64(10), uint64(1000), []byte{0xde, 0xad}, uint8(3), uint64(120))
+	f.Fuzz(func(t *testing.T, hash []byte, epochNum, timestamp uint64, tx []byte, numBatches uint8, maxFrameSize uint64) {
+		co, err := newChannelOut()
+		require.NoError(t, err)
+
+		var batches []*BatchData
+		for i := 0; i < int(numBatches)%16+1; i++ {
+			batch := &BatchData{BatchV1: BatchV1{
+				ParentHash:   common.BytesToHash(hash),
+				EpochNum:     rollup.Epoch(epochNum),
+				EpochHash:    common.BytesToHash(append(common.CopyBytes(hash), byte(i))),
+				Timestamp:    timestamp + uint64(i),
+				Transactions: []hexutil.Bytes{tx, append(common.CopyBytes(tx), byte(i))},
+			}}
+			_, err := co.AddBatch(batch)
+			require.NoError(t, err)
+			batches = append(batches, batch)
+		}
+		require.NoError(t, co.Close())
+
+		// Output frames of any size bigger than the fixed frame overhead.
+		maxFrameSize = maxFrameSize%1000 + 24
+		var data []byte
+		for {
+			var buf bytes.Buffer
+			_, err := co.OutputFrame(&buf, maxFrameSize)
+			if err != nil && err != io.EOF {
+				t.Fatalf("Failed to output frame: %v", err)
+			}
+			var frame Frame
+			require.NoError(t, frame.UnmarshalBinary(&buf))
+			data = append(data, frame.Data...)
+			if err == io.EOF {
+				break
+			}
+		}
+
+		for _, v := range versioned {
+			br, err := BatchReader(bytes.NewReader(data), eth.L1BlockRef{}, v)
+			require.NoError(t, err)
+			for _, expected := range batches {
+				actual, err := br()
+				require.NoError(t, err)
+				expectedData, err := expected.MarshalBinary()
+				require.NoError(t, err)
+				actualData, err := actual.Batch.MarshalBinary()
+				require.NoError(t, err)
+				require.Equal(t, expectedData, actualData)
+			}
+			_, err = br()
+			require.ErrorIs(t, err, io.EOF)
+		}
+	})
+}
+
+// FuzzBatchReader checks that reading arbitrary channel data doesn't panic, whether
+// the channel format is versioned or not.
+func FuzzBatchReader(f *testing.F) {
+	for _, algo := range CompressionAlgos {
+		f.Add(append([]byte{byte(algo)}, 0xff, 0x00))
+	}
+	f.Add([]byte{0x78, 0xda, 0x00})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, versioned := range []bool{false, true} {
+			br, err := BatchReader(bytes.NewReader(data), eth.L1BlockRef{}, versioned)
+			if err != nil {
+				continue
+			}
+			// A channel can't hold more batches than it has bytes.
+			for i := 0; i <= len(data); i++ {
+				if _, err := br(); err != nil {
+					break
+				}
+			}
+		}
+	})
+}
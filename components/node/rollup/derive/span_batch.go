@@ -0,0 +1,133 @@
+package derive
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
+)
+
+// Span batch format
+//
+// SpanBatchType := 1
+// spanBatch := SpanBatchType ++ RLP([parent_hash, epoch_num, last_epoch_hash, timestamp, blocks])
+// block := [epoch_delta, time_delta, transaction_list]
+//
+// A span batch encodes a contiguous range of L2 blocks. Only the parent hash of the first block
+// and the epoch hash of the last block are included, the epochs and timestamps of the blocks are
+// delta-encoded from the previous block, or from the span header for the first block.
+
+var ErrSpanBatchNotContiguous = errors.New("block is not contiguous with the span batch")
+
+// SpanBatch is a contiguous range of L2 blocks.
+type SpanBatch struct {
+	ParentHash    common.Hash  // parent L2 block hash of the first block
+	EpochNum      rollup.Epoch // base l1 num, the epoch of the first block is relative to it
+	LastEpochHash common.Hash  // l1 block hash of the epoch of the last block
+	Timestamp     uint64       // base timestamp, the timestamp of the first block is relative to it
+	Blocks        []SpanBatchBlock
+}
+
+// SpanBatchBlock is a single L2 block of a span batch.
+type SpanBatchBlock struct {
+	EpochDelta uint64 // l1 num delta from the previous block
+	TimeDelta  uint64 // timestamp delta from the previous block
+	// no feeRecipient address input, all fees go to a L2 contract
+	Transactions []hexutil.Bytes
+}
+
+// NewSpanBatch creates a span batch starting with the given batch.
+func NewSpanBatch(first *BatchV1) *SpanBatch {
+	return &SpanBatch{
+		ParentHash:    first.ParentHash,
+		EpochNum:      first.EpochNum,
+		LastEpochHash: first.EpochHash,
+		Timestamp:     first.Timestamp,
+		Blocks:        []SpanBatchBlock{{Transactions: first.Transactions}},
+	}
+}
+
+// NextBlock returns the block of the given batch, if it was appended to the span. The batch
+// must extend the last block, whose hash is given, by the given block time, as the span is
+// dropped by the derivation otherwise.
+func (b *SpanBatch) NextBlock(batch *BatchV1, lastHash common.Hash, blockTime uint64) (SpanBatchBlock, error) {
+	lastEpoch, lastTimestamp := b.LastEpochNum(), b.LastTimestamp()
+	if batch.ParentHash != lastHash {
+		return SpanBatchBlock{}, fmt.Errorf("%w: parent hash %s is not the last block hash %s", ErrSpanBatchNotContiguous, batch.ParentHash, lastHash)
+	}
+	if batch.EpochNum < lastEpoch || batch.EpochNum > lastEpoch+1 {
+		return SpanBatchBlock{}, fmt.Errorf("%w: epoch %d does not follow epoch %d", ErrSpanBatchNotContiguous, batch.EpochNum, lastEpoch)
+	}
+	if batch.Timestamp != lastTimestamp+blockTime {
+		return SpanBatchBlock{}, fmt.Errorf("%w: timestamp %d does not follow timestamp %d", ErrSpanBatchNotContiguous, batch.Timestamp, lastTimestamp)
+	}
+	return SpanBatchBlock{
+		EpochDelta:   uint64(batch.EpochNum - lastEpoch),
+		TimeDelta:    batch.Timestamp - lastTimestamp,
+		Transactions: batch.Transactions,
+	}, nil
+}
+
+// Append adds the batch of the next block to the span, see NextBlock.
+func (b *SpanBatch) Append(batch *BatchV1, lastHash common.Hash, blockTime uint64) error {
+	block, err := b.NextBlock(batch, lastHash, blockTime)
+	if err != nil {
+		return err
+	}
+	b.Blocks = append(b.Blocks, block)
+	b.LastEpochHash = batch.EpochHash
+	return nil
+}
+
+// FirstTimestamp returns the timestamp of the first block.
+func (b *SpanBatch) FirstTimestamp() uint64 {
+	return b.Timestamp + b.Blocks[0].TimeDelta
+}
+
+// FirstEpochNum returns the epoch of the first block.
+func (b *SpanBatch) FirstEpochNum() rollup.Epoch {
+	return b.EpochNum + rollup.Epoch(b.Blocks[0].EpochDelta)
+}
+
+// LastTimestamp returns the timestamp of the last block.
+func (b *SpanBatch) LastTimestamp() uint64 {
+	timestamp := b.Timestamp
+	for _, block := range b.Blocks {
+		timestamp += block.TimeDelta
+	}
+	return timestamp
+}
+
+// LastEpochNum returns the epoch of the last block.
+func (b *SpanBatch) LastEpochNum() rollup.Epoch {
+	epoch := b.EpochNum
+	for _, block := range b.Blocks {
+		epoch += rollup.Epoch(block.EpochDelta)
+	}
+	return epoch
+}
+
+// TxCount returns the number of transactions of all blocks.
+func (b *SpanBatch) TxCount() int {
+	var count int
+	for _, block := range b.Blocks {
+		count += len(block.Transactions)
+	}
+	return count
+}
+
+// DecodeRLP implements rlp.Decoder. A span batch must contain at least one block.
+func (b *SpanBatch) DecodeRLP(s *rlp.Stream) error {
+	type spanBatch SpanBatch // no methods, to not recurse into DecodeRLP
+	if err := s.Decode((*spanBatch)(b)); err != nil {
+		return err
+	}
+	if len(b.Blocks) == 0 {
+		return errors.New("span batch has no blocks")
+	}
+	return nil
+}
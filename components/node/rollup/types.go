@@ -78,6 +78,19 @@ type Config struct {
 	DepositContractAddress common.Address `json:"deposit_contract_address"`
 	// L1 System Config Address
 	L1SystemConfigAddress common.Address `json:"l1_system_config_address"`
+
+	// CompressionForkTime sets the activation time of the versioned channel format, which prefixes
+	// the channel data with the compression algorithm. It is compared against the timestamp of the
+	// L1 block the channel is read from. Not active if nil.
+	CompressionForkTime *uint64 `json:"compression_fork_time,omitempty"`
+	// SpanBatchForkTime sets the activation time of the span batches, which encode a contiguous range
+	// of L2 blocks at once. It is compared against the timestamp of the L1 block the batch is included
+	// in. Not active if nil.
+	SpanBatchForkTime *uint64 `json:"span_batch_fork_time,omitempty"`
+	// ExternalDAForkTime sets the activation time of the commitments to batcher transaction data
+	// stored by an external data availability service. It is compared against the timestamp of the
+	// L1 block the commitment is included in. Not active if nil.
+	ExternalDAForkTime *uint64 `json:"external_da_fork_time,omitempty"`
 }
 
 // ValidateL1Config checks L1 config variables for errors.
@@ -227,6 +240,21 @@ func (cfg *Config) Check() error {
 	return nil
 }
 
+// IsCompressionFork returns true if the versioned channel format is active at the given L1 timestamp.
+func (c *Config) IsCompressionFork(timestamp uint64) bool {
+	return c.CompressionForkTime != nil && timestamp >= *c.CompressionForkTime
+}
+
+// IsSpanBatchFork returns true if the span batches are active at the given L1 timestamp.
+func (c *Config) IsSpanBatchFork(timestamp uint64) bool {
+	return c.SpanBatchForkTime != nil && timestamp >= *c.SpanBatchForkTime
+}
+
+// IsExternalDAFork returns true if the external DA commitments are active at the given L1 timestamp.
+func (c *Config) IsExternalDAFork(timestamp uint64) bool {
+	return c.ExternalDAForkTime != nil && timestamp >= *c.ExternalDAForkTime
+}
+
 func (c *Config) L1Signer() types.Signer {
 	return types.NewLondonSigner(c.L1ChainID)
 }
@@ -255,6 +283,11 @@ func (c *Config) Description(l2Chains map[string]string) string {
 	banner += fmt.Sprintf("  L2 starting time: %d ~ %s\n", c.Genesis.L2Time, fmtTime(c.Genesis.L2Time))
 	banner += fmt.Sprintf("  L2 block: %s %d\n", c.Genesis.L2.Hash, c.Genesis.L2.Number)
 	banner += fmt.Sprintf("  L1 block: %s %d\n", c.Genesis.L1.Hash, c.Genesis.L1.Number)
+	// Report the upgrade configuration
+	banner += "Post-genesis upgrades:\n"
+	banner += fmt.Sprintf("  - Compression: %s\n", fmtForkTimeOrUnset(c.CompressionForkTime))
+	banner += fmt.Sprintf("  - Span batch: %s\n", fmtForkTimeOrUnset(c.SpanBatchForkTime))
+	banner += fmt.Sprintf("  - External DA: %s\n", fmtForkTimeOrUnset(c.ExternalDAForkTime))
 	return banner
 }
 
@@ -277,7 +310,9 @@ func (c *Config) LogDescription(log log.Logger, l2Chains map[string]string) {
 	log.Info("Rollup Config", "l2_chain_id", c.L2ChainID, "l2_network", networkL2, "l1_chain_id", c.L1ChainID,
 		"l1_network", networkL1, "l2_start_time", c.Genesis.L2Time, "l2_block_hash", c.Genesis.L2.Hash.String(),
 		"l2_block_number", c.Genesis.L2.Number, "l1_block_hash", c.Genesis.L1.Hash.String(),
-		"l1_block_number", c.Genesis.L1.Number)
+		"l1_block_number", c.Genesis.L1.Number, "compression_fork_time", fmtForkTimeOrUnset(c.CompressionForkTime),
+		"span_batch_fork_time", fmtForkTimeOrUnset(c.SpanBatchForkTime),
+		"external_da_fork_time", fmtForkTimeOrUnset(c.ExternalDAForkTime))
 }
 
 func fmtForkTimeOrUnset(v *uint64) string {
@@ -3,6 +3,7 @@ package rollup
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"math/big"
 	"math/rand"
 	"testing"
@@ -334,3 +335,35 @@ func TestConfig_Check(t *testing.T) {
 		})
 	}
 }
+
+func TestCompressionFork(t *testing.T) {
+	config := randConfig()
+	assert.False(t, config.IsCompressionFork(0))
+	assert.False(t, config.IsCompressionFork(math.MaxUint64))
+
+	forkTime := uint64(1000)
+	config.CompressionForkTime = &forkTime
+	assert.False(t, config.IsCompressionFork(999))
+	assert.True(t, config.IsCompressionFork(1000))
+	assert.True(t, config.IsCompressionFork(1001))
+}
+
+func TestSpanBatchFork(t *testing.T) {
+	config := randConfig()
+	assert.False(t, config.IsSpanBatchFork(math.MaxUint64))
+
+	forkTime := uint64(1000)
+	config.SpanBatchForkTime = &forkTime
+	assert.False(t, config.IsSpanBatchFork(999))
+	assert.True(t, config.IsSpanBatchFork(1000))
+}
+
+func TestExternalDAFork(t *testing.T) {
+	config := randConfig()
+	assert.False(t, config.IsExternalDAFork(math.MaxUint64))
+
+	forkTime := uint64(1000)
+	config.ExternalDAForkTime = &forkTime
+	assert.False(t, config.IsExternalDAFork(999))
+	assert.True(t, config.IsExternalDAFork(1000))
+}
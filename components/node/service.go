@@ -84,6 +84,7 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 			Moniker: ctx.GlobalString(flags.HeartbeatMonikerFlag.Name),
 			URL:     ctx.GlobalString(flags.HeartbeatURLFlag.Name),
 		},
+		DARpc: ctx.GlobalString(flags.DARpc.Name),
 	}
 	if err := cfg.Check(); err != nil {
 		return nil, err
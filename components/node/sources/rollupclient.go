@@ -10,6 +10,9 @@ import (
 	"github.com/wemixkanvas/kanvas/components/node/rollup"
 )
 
+// MaxOutputsAtBlocks is the maximum number of the outputs requested at once with OutputsAtBlocks.
+const MaxOutputsAtBlocks = 256
+
 type RollupClient struct {
 	rpc client.RPC
 }
@@ -24,6 +27,18 @@ func (r *RollupClient) OutputAtBlock(ctx context.Context, blockNum uint64) (*eth
 	return output, err
 }
 
+// OutputsAtBlocks returns the outputs at the given blocks in a single request, in the same order.
+// At most MaxOutputsAtBlocks blocks can be requested at once.
+func (r *RollupClient) OutputsAtBlocks(ctx context.Context, blockNums []uint64) ([]*eth.OutputResponse, error) {
+	nums := make([]hexutil.Uint64, len(blockNums))
+	for i, num := range blockNums {
+		nums[i] = hexutil.Uint64(num)
+	}
+	var outputs []*eth.OutputResponse
+	err := r.rpc.CallContext(ctx, &outputs, "kanvas_outputsAtBlocks", nums)
+	return outputs, err
+}
+
 func (r *RollupClient) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	var output *eth.SyncStatus
 	err := r.rpc.CallContext(ctx, &output, "kanvas_syncStatus")
@@ -2,50 +2,133 @@ package challenge
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"math/big"
+	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	pb "github.com/wemixkanvas/kanvas/components/validator/challenge/kanvas-grpc-proto"
+	ktls "github.com/wemixkanvas/kanvas/utils/service/tls"
+	"github.com/wemixkanvas/kanvas/utils/service/tls/certman"
 )
 
+// Fetcher fetches the proofs with the v1 prover protocol, which generates a proof in a single unary call.
 type Fetcher struct {
 	Client  pb.ProofClient
 	logger  log.Logger
 	conn    *grpc.ClientConn
+	cm      *certman.CertMan
 	timeout time.Duration
 }
 
-func NewFetcher(grpcUrl string, timeout time.Duration, logger log.Logger) (*Fetcher, error) {
-	if grpcUrl == "" {
-		return nil, fmt.Errorf("no grpc url specified")
-	}
-
-	conn, err := grpc.Dial(grpcUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewFetcher(grpcUrl string, timeout time.Duration, tlsConfig ktls.CLIConfig, logger log.Logger) (*Fetcher, error) {
+	conn, cm, err := dialProver(grpcUrl, tlsConfig, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to grpc server: %w", err)
+		return nil, err
 	}
 
 	return &Fetcher{
 		Client:  pb.NewProofClient(conn),
 		logger:  logger,
 		conn:    conn,
+		cm:      cm,
 		timeout: timeout,
 	}, nil
 }
 
+// dialProver connects to the prover grpc server. If TLS is enabled, the client certificate is
+// authenticated to the prover, and reloaded by certman whenever it changes.
+func dialProver(grpcUrl string, tlsConfig ktls.CLIConfig, logger log.Logger) (*grpc.ClientConn, *certman.CertMan, error) {
+	if grpcUrl == "" {
+		return nil, nil, errors.New("no grpc url specified")
+	}
+
+	if !tlsConfig.TLSEnabled() {
+		conn, err := grpc.Dial(grpcUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to grpc server: %w", err)
+		}
+		return conn, nil, nil
+	}
+
+	logger.Info("tlsConfig specified, loading tls config for prover")
+	caCert, err := os.ReadFile(tlsConfig.TLSCaCert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tls.ca: %w", err)
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, nil, fmt.Errorf("failed to parse tls.ca: %s", tlsConfig.TLSCaCert)
+	}
+
+	cm, err := certman.New(logger, tlsConfig.TLSCert, tlsConfig.TLSKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tls cert or key: %w", err)
+	}
+	if err := cm.Watch(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start certman watcher: %w", err)
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		MinVersion:           tls.VersionTLS13,
+		RootCAs:              caCertPool,
+		GetClientCertificate: cm.GetClientCertificate,
+	})
+	conn, err := grpc.Dial(grpcUrl, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		cm.Stop()
+		return nil, nil, fmt.Errorf("failed to connect to grpc server: %w", err)
+	}
+
+	return conn, cm, nil
+}
+
+// closeProver closes the connection to the prover, and stops watching the client certificate.
+func closeProver(conn *grpc.ClientConn, cm *certman.CertMan) error {
+	if cm != nil {
+		cm.Stop()
+	}
+	return conn.Close()
+}
+
 type ProofAndPair struct {
 	Proof []*big.Int
 	Pair  []*big.Int
 }
 
-func (f *Fetcher) FetchProofAndPair(blockRef eth.L2BlockRef) (*ProofAndPair, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+// finalPairLength is the number of the elements of the final pair, which is read by ZKVerifier.
+const finalPairLength = 4
+
+// ErrInvalidProof is returned when the proof generated by the prover is not valid.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// Check checks the shape of the proof and the pair, before it is verified by ZKVerifier.
+func (p *ProofAndPair) Check() error {
+	if len(p.Proof) == 0 {
+		return fmt.Errorf("%w: empty proof", ErrInvalidProof)
+	}
+	if len(p.Pair) != finalPairLength {
+		return fmt.Errorf("%w: final pair has %d elements, expected %d", ErrInvalidProof, len(p.Pair), finalPairLength)
+	}
+	return nil
+}
+
+func (f *Fetcher) FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	return f.fetch(ctx, blockRef)
+}
+
+func (f *Fetcher) fetch(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
 	defer cancel()
 
 	blockNumberHex := fmt.Sprintf("0x%x", blockRef.Number)
@@ -65,9 +148,20 @@ func (f *Fetcher) FetchProofAndPair(blockRef eth.L2BlockRef) (*ProofAndPair, err
 	return result, nil
 }
 
+// checkHealth checks the state of the connection, since the v1 protocol has no call to check the prover.
+func (f *Fetcher) checkHealth(_ context.Context) error {
+	switch state := f.conn.GetState(); state {
+	case connectivity.Idle:
+		f.conn.Connect()
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return fmt.Errorf("grpc connection is %s", state)
+	}
+	return nil
+}
+
 func (f *Fetcher) Close() error {
 	f.logger.Info("Closing grpc connection")
-	return f.conn.Close()
+	return closeProver(f.conn, f.cm)
 }
 
 func Decode(data []byte) []*big.Int {
@@ -0,0 +1,244 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	pbv2 "github.com/wemixkanvas/kanvas/components/validator/challenge/kanvas-grpc-proto/v2"
+	ktls "github.com/wemixkanvas/kanvas/utils/service/tls"
+	"github.com/wemixkanvas/kanvas/utils/service/tls/certman"
+)
+
+const (
+	// ProverProtocolVersion is the version of the prover protocol spoken by FetcherV2.
+	ProverProtocolVersion = 2
+
+	handshakeTimeout = 10 * time.Second
+	cancelJobTimeout = 10 * time.Second
+	// defaultJobPollInterval is the interval to poll the status of a job, if the prover does not support streaming.
+	defaultJobPollInterval = 10 * time.Second
+)
+
+var ErrProtocolUnsupported = errors.New("prover does not support the protocol version")
+
+// FetcherV2 fetches the proofs with the v2 prover protocol. A proof job is submitted, pinned to the block hash,
+// and its status is streamed, or polled if the prover does not support streaming, until it is done or failed.
+type FetcherV2 struct {
+	Client       pbv2.ProverServiceClient
+	logger       log.Logger
+	conn         *grpc.ClientConn
+	cm           *certman.CertMan
+	timeout      time.Duration
+	pollInterval time.Duration
+
+	// info is the result of the handshake, which is done on the first fetch.
+	mu   sync.Mutex
+	info *pbv2.ProverInfo
+}
+
+func NewFetcherV2(grpcUrl string, timeout time.Duration, tlsConfig ktls.CLIConfig, logger log.Logger) (*FetcherV2, error) {
+	conn, cm, err := dialProver(grpcUrl, tlsConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FetcherV2{
+		Client:       pbv2.NewProverServiceClient(conn),
+		logger:       logger,
+		conn:         conn,
+		cm:           cm,
+		timeout:      timeout,
+		pollInterval: defaultJobPollInterval,
+	}, nil
+}
+
+// ProverInfo returns the version and the capabilities of the prover, checking that the prover speaks
+// the same protocol version. The result is cached once the handshake succeeds.
+func (f *FetcherV2) ProverInfo(ctx context.Context) (*pbv2.ProverInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.info != nil {
+		return f.info, nil
+	}
+
+	return f.handshake(ctx)
+}
+
+// checkHealth checks the prover by doing the handshake again.
+func (f *FetcherV2) checkHealth(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err := f.handshake(ctx)
+	return err
+}
+
+func (f *FetcherV2) handshake(ctx context.Context) (*pbv2.ProverInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+
+	info, err := f.Client.GetProverInfo(ctx, &pbv2.ProverInfoRequest{ProtocolVersion: ProverProtocolVersion})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("%w: v%d", ErrProtocolUnsupported, ProverProtocolVersion)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get prover info: %w", err)
+	}
+
+	if info.ProtocolVersion != ProverProtocolVersion {
+		return nil, fmt.Errorf("%w: v%d, prover speaks v%d", ErrProtocolUnsupported, ProverProtocolVersion, info.ProtocolVersion)
+	}
+	if f.info == nil {
+		if !info.BlockHashPinning {
+			f.logger.Warn("prover does not support block hash pinning, the hash of the proven block is checked after the job")
+		}
+		f.logger.Info("connected to prover", "version", info.Version, "protocol", info.ProtocolVersion,
+			"streaming", info.Streaming, "maxConcurrentJobs", info.MaxConcurrentJobs)
+	}
+	f.info = info
+
+	return info, nil
+}
+
+func (f *FetcherV2) FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	return f.fetch(ctx, blockRef)
+}
+
+func (f *FetcherV2) fetch(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	info, err := f.ProverInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := f.Client.SubmitJob(ctx, &pbv2.SubmitJobRequest{
+		BlockNumber: blockRef.Number,
+		BlockHash:   blockRef.Hash.Bytes(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit proof job of block %s: %w", blockRef, err)
+	}
+	f.logger.Info("submitted proof job", "jobId", job.JobId, "block", blockRef, "status", job.Status)
+
+	if !isJobFinished(job) {
+		jobId := job.JobId
+		job, err = f.waitJob(ctx, info, jobId)
+		if err != nil {
+			f.cancelJob(jobId)
+			return nil, fmt.Errorf("failed to wait for proof job %s: %w", jobId, err)
+		}
+	}
+
+	return jobResult(blockRef, job)
+}
+
+// waitJob waits until the job is done or failed.
+func (f *FetcherV2) waitJob(ctx context.Context, info *pbv2.ProverInfo, jobId string) (*pbv2.Job, error) {
+	if info.Streaming {
+		job, err := f.watchJob(ctx, jobId)
+		if status.Code(err) != codes.Unimplemented {
+			return job, err
+		}
+		f.logger.Warn("prover does not support streaming, polling the job", "jobId", jobId)
+	}
+
+	return f.pollJob(ctx, jobId)
+}
+
+func (f *FetcherV2) watchJob(ctx context.Context, jobId string) (*pbv2.Job, error) {
+	stream, err := f.Client.WatchJob(ctx, &pbv2.JobRequest{JobId: jobId})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		job, err := stream.Recv()
+		if err == io.EOF {
+			return nil, errors.New("job stream is closed before the job is finished")
+		} else if err != nil {
+			return nil, err
+		}
+
+		f.logger.Debug("proof job updated", "jobId", jobId, "status", job.Status)
+		if isJobFinished(job) {
+			return job, nil
+		}
+	}
+}
+
+func (f *FetcherV2) pollJob(ctx context.Context, jobId string) (*pbv2.Job, error) {
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := f.Client.GetJob(ctx, &pbv2.JobRequest{JobId: jobId})
+		if err != nil {
+			return nil, err
+		}
+
+		f.logger.Debug("proof job polled", "jobId", jobId, "status", job.Status)
+		if isJobFinished(job) {
+			return job, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// cancelJob cancels the job which is not waited anymore, so that the prover does not waste its resources.
+func (f *FetcherV2) cancelJob(jobId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelJobTimeout)
+	defer cancel()
+
+	if _, err := f.Client.CancelJob(ctx, &pbv2.JobRequest{JobId: jobId}); err != nil {
+		f.logger.Warn("failed to cancel proof job", "jobId", jobId, "err", err)
+		return
+	}
+	f.logger.Info("canceled proof job", "jobId", jobId)
+}
+
+func (f *FetcherV2) Close() error {
+	f.logger.Info("Closing grpc connection")
+	return closeProver(f.conn, f.cm)
+}
+
+func isJobFinished(job *pbv2.Job) bool {
+	return job.Status == pbv2.JobStatus_JOB_STATUS_DONE || job.Status == pbv2.JobStatus_JOB_STATUS_FAILED
+}
+
+func jobResult(blockRef eth.L2BlockRef, job *pbv2.Job) (*ProofAndPair, error) {
+	if job.Status == pbv2.JobStatus_JOB_STATUS_FAILED {
+		return nil, fmt.Errorf("proof job %s failed: %s", job.JobId, job.Error)
+	}
+
+	if job.BlockNumber != blockRef.Number || common.BytesToHash(job.BlockHash) != blockRef.Hash {
+		return nil, fmt.Errorf("proof job %s proved block %d:%s, expected %s",
+			job.JobId, job.BlockNumber, common.BytesToHash(job.BlockHash), blockRef)
+	}
+
+	if job.Result == nil {
+		return nil, fmt.Errorf("proof job %s is done without result", job.JobId)
+	}
+
+	return &ProofAndPair{
+		Proof: Decode(job.Result.Proof),
+		Pair:  Decode(job.Result.FinalPair),
+	}, nil
+}
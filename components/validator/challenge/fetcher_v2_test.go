@@ -0,0 +1,220 @@
+package challenge
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	pbv2 "github.com/wemixkanvas/kanvas/components/validator/challenge/kanvas-grpc-proto/v2"
+)
+
+// mockProver proves the blocks by the given hashes. A job is proving until it is polled or watched provingRounds times.
+type mockProver struct {
+	pbv2.UnimplementedProverServiceServer
+
+	info          *pbv2.ProverInfo
+	canonical     map[uint64]common.Hash
+	provingRounds int
+
+	mu       sync.Mutex
+	jobs     map[string]*pbv2.Job
+	rounds   map[string]int
+	canceled []string
+}
+
+func (p *mockProver) GetProverInfo(context.Context, *pbv2.ProverInfoRequest) (*pbv2.ProverInfo, error) {
+	return p.info, nil
+}
+
+func (p *mockProver) SubmitJob(_ context.Context, req *pbv2.SubmitJobRequest) (*pbv2.Job, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	jobId := common.BytesToHash(req.BlockHash).Hex()
+	job := &pbv2.Job{
+		JobId:       jobId,
+		BlockNumber: req.BlockNumber,
+		BlockHash:   req.BlockHash,
+		Status:      pbv2.JobStatus_JOB_STATUS_QUEUED,
+	}
+	if p.canonical[req.BlockNumber] != common.BytesToHash(req.BlockHash) {
+		job.Status = pbv2.JobStatus_JOB_STATUS_FAILED
+		job.Error = "block hash mismatch"
+	}
+	p.jobs[jobId] = job
+
+	return job, nil
+}
+
+// advance moves the job forward by a single round.
+func (p *mockProver) advance(jobId string) *pbv2.Job {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job := p.jobs[jobId]
+	if job.Status == pbv2.JobStatus_JOB_STATUS_QUEUED || job.Status == pbv2.JobStatus_JOB_STATUS_PROVING {
+		p.rounds[jobId]++
+		job.Status = pbv2.JobStatus_JOB_STATUS_PROVING
+		if p.rounds[jobId] > p.provingRounds {
+			job.Status = pbv2.JobStatus_JOB_STATUS_DONE
+			job.Result = &pbv2.ProofResult{
+				Proof:     make([]byte, 32),
+				FinalPair: make([]byte, 64),
+			}
+		}
+	}
+
+	return &pbv2.Job{
+		JobId:       job.JobId,
+		BlockNumber: job.BlockNumber,
+		BlockHash:   job.BlockHash,
+		Status:      job.Status,
+		Error:       job.Error,
+		Result:      job.Result,
+	}
+}
+
+func (p *mockProver) GetJob(_ context.Context, req *pbv2.JobRequest) (*pbv2.Job, error) {
+	return p.advance(req.JobId), nil
+}
+
+func (p *mockProver) WatchJob(req *pbv2.JobRequest, stream pbv2.ProverService_WatchJobServer) error {
+	if !p.info.Streaming {
+		return p.UnimplementedProverServiceServer.WatchJob(req, stream)
+	}
+
+	for {
+		job := p.advance(req.JobId)
+		if err := stream.Send(job); err != nil {
+			return err
+		}
+		if isJobFinished(job) {
+			return nil
+		}
+	}
+}
+
+func (p *mockProver) CancelJob(_ context.Context, req *pbv2.JobRequest) (*pbv2.Job, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.canceled = append(p.canceled, req.JobId)
+	job := p.jobs[req.JobId]
+	job.Status = pbv2.JobStatus_JOB_STATUS_FAILED
+	job.Error = "canceled"
+
+	return job, nil
+}
+
+func newMockProver(info *pbv2.ProverInfo, canonical map[uint64]common.Hash) *mockProver {
+	return &mockProver{
+		info:          info,
+		canonical:     canonical,
+		provingRounds: 2,
+		jobs:          make(map[string]*pbv2.Job),
+		rounds:        make(map[string]int),
+	}
+}
+
+func newTestFetcherV2(t *testing.T, srv pbv2.ProverServiceServer, timeout time.Duration) *FetcherV2 {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pbv2.RegisterProverServiceServer(server, srv)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	f := &FetcherV2{
+		Client:       pbv2.NewProverServiceClient(conn),
+		logger:       testlog.Logger(t, log.LvlInfo),
+		conn:         conn,
+		timeout:      timeout,
+		pollInterval: time.Millisecond,
+	}
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	return f
+}
+
+func TestFetcherV2(t *testing.T) {
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	canonical := map[uint64]common.Hash{10: blockRef.Hash}
+
+	for _, streaming := range []bool{true, false} {
+		info := &pbv2.ProverInfo{ProtocolVersion: ProverProtocolVersion, BlockHashPinning: true, Streaming: streaming}
+		f := newTestFetcherV2(t, newMockProver(info, canonical), time.Minute)
+
+		proof, err := f.FetchProofAndPair(context.Background(), blockRef)
+		require.NoError(t, err)
+		require.Len(t, proof.Proof, 1)
+		require.Len(t, proof.Pair, 2)
+	}
+}
+
+func TestFetcherV2StreamingFallback(t *testing.T) {
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	prover := newMockProver(&pbv2.ProverInfo{ProtocolVersion: ProverProtocolVersion}, map[uint64]common.Hash{10: blockRef.Hash})
+	f := newTestFetcherV2(t, prover, time.Minute)
+
+	// The prover claims to support streaming, but WatchJob is not implemented.
+	f.info = &pbv2.ProverInfo{ProtocolVersion: ProverProtocolVersion, Streaming: true}
+	proof, err := f.FetchProofAndPair(context.Background(), blockRef)
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+}
+
+func TestFetcherV2BlockHashPinning(t *testing.T) {
+	info := &pbv2.ProverInfo{ProtocolVersion: ProverProtocolVersion, BlockHashPinning: true, Streaming: true}
+	f := newTestFetcherV2(t, newMockProver(info, map[uint64]common.Hash{10: {1}}), time.Minute)
+
+	_, err := f.FetchProofAndPair(context.Background(), eth.L2BlockRef{Hash: common.Hash{2}, Number: 10})
+	require.ErrorContains(t, err, "block hash mismatch")
+}
+
+func TestFetcherV2Handshake(t *testing.T) {
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+
+	f := newTestFetcherV2(t, &pbv2.UnimplementedProverServiceServer{}, time.Minute)
+	_, err := f.FetchProofAndPair(context.Background(), blockRef)
+	require.ErrorIs(t, err, ErrProtocolUnsupported)
+
+	f = newTestFetcherV2(t, newMockProver(&pbv2.ProverInfo{ProtocolVersion: 3}, nil), time.Minute)
+	_, err = f.FetchProofAndPair(context.Background(), blockRef)
+	require.ErrorIs(t, err, ErrProtocolUnsupported)
+}
+
+func TestFetcherV2Cancel(t *testing.T) {
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	prover := newMockProver(&pbv2.ProverInfo{ProtocolVersion: ProverProtocolVersion}, map[uint64]common.Hash{10: blockRef.Hash})
+	prover.provingRounds = 1 << 30
+	f := newTestFetcherV2(t, prover, 50*time.Millisecond)
+
+	_, err := f.FetchProofAndPair(context.Background(), blockRef)
+	require.Error(t, err)
+
+	prover.mu.Lock()
+	defer prover.mu.Unlock()
+	require.Equal(t, []string{blockRef.Hash.Hex()}, prover.canceled, "job must be canceled when it is not waited anymore")
+}
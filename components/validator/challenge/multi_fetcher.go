@@ -0,0 +1,291 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	ktls "github.com/wemixkanvas/kanvas/utils/service/tls"
+)
+
+type ProverStrategy string
+
+const (
+	// StrategyPrimary requests the proof to the endpoints in the given order, falling back to the next one on failure.
+	StrategyPrimary ProverStrategy = "primary"
+	// StrategyRace requests the proof to all the endpoints at the same time, and takes the first proof.
+	StrategyRace ProverStrategy = "race"
+	// StrategyRoundRobin requests each proof to the next endpoint, falling back to the following ones on failure.
+	StrategyRoundRobin ProverStrategy = "round-robin"
+)
+
+var ProverStrategies = []ProverStrategy{StrategyPrimary, StrategyRace, StrategyRoundRobin}
+
+func (s ProverStrategy) Check() error {
+	for _, strategy := range ProverStrategies {
+		if s == strategy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown prover strategy: %q", s)
+}
+
+// endpointFetcher is the client of a single prover endpoint.
+type endpointFetcher interface {
+	fetch(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error)
+	checkHealth(ctx context.Context) error
+	Close() error
+}
+
+type ProverConfig struct {
+	// Urls are the grpc urls of the prover endpoints, in the order of priority.
+	Urls []string
+	// Version is the version of the prover protocol.
+	Version  uint
+	Strategy ProverStrategy
+	// HealthCheckInterval is the interval to check the health of the endpoints.
+	HealthCheckInterval time.Duration
+	// Timeout is the timeout to fetch a proof from a single endpoint.
+	Timeout   time.Duration
+	TLSConfig ktls.CLIConfig
+}
+
+type proverEndpoint struct {
+	url     string
+	fetcher endpointFetcher
+	healthy atomic.Bool
+}
+
+// MultiFetcher fetches the proofs from multiple prover endpoints with the given strategy, so that
+// an outage of a single prover does not prevent proving the fault. The unhealthy endpoints are
+// requested only if all the endpoints are unhealthy.
+type MultiFetcher struct {
+	log      log.Logger
+	metr     metrics.Metricer
+	strategy ProverStrategy
+
+	endpoints []*proverEndpoint
+	// next is the index of the endpoint to request the next proof to, with the round-robin strategy.
+	next atomic.Uint64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMultiFetcher(cfg ProverConfig, l log.Logger, m metrics.Metricer) (*MultiFetcher, error) {
+	if len(cfg.Urls) == 0 {
+		return nil, errors.New("no prover grpc url specified")
+	}
+	if err := cfg.Strategy.Check(); err != nil {
+		return nil, err
+	}
+
+	var endpoints []*proverEndpoint
+	for _, url := range cfg.Urls {
+		fetcher, err := newEndpointFetcher(cfg, url, l.New("prover", url))
+		if err != nil {
+			for _, e := range endpoints {
+				_ = e.fetcher.Close()
+			}
+			return nil, fmt.Errorf("failed to connect to prover %s: %w", url, err)
+		}
+		endpoints = append(endpoints, &proverEndpoint{url: url, fetcher: fetcher})
+	}
+
+	return newMultiFetcher(endpoints, cfg.Strategy, cfg.HealthCheckInterval, l, m), nil
+}
+
+func newEndpointFetcher(cfg ProverConfig, url string, l log.Logger) (endpointFetcher, error) {
+	if cfg.Version == ProverProtocolVersion {
+		return NewFetcherV2(url, cfg.Timeout, cfg.TLSConfig, l)
+	}
+	return NewFetcher(url, cfg.Timeout, cfg.TLSConfig, l)
+}
+
+func newMultiFetcher(endpoints []*proverEndpoint, strategy ProverStrategy, healthCheckInterval time.Duration, l log.Logger, m metrics.Metricer) *MultiFetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &MultiFetcher{
+		log:       l,
+		metr:      m,
+		strategy:  strategy,
+		endpoints: endpoints,
+		cancel:    cancel,
+	}
+
+	// The endpoints are regarded healthy until they are checked.
+	for _, e := range endpoints {
+		e.healthy.Store(true)
+	}
+
+	if healthCheckInterval > 0 {
+		f.wg.Add(1)
+		go f.healthLoop(ctx, healthCheckInterval)
+	}
+
+	return f
+}
+
+func (f *MultiFetcher) FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	endpoints := f.orderedEndpoints()
+
+	if f.strategy == StrategyRace {
+		return f.race(ctx, blockRef, endpoints)
+	}
+
+	var errs []string
+	for _, e := range endpoints {
+		proof, err := f.fetch(ctx, e, blockRef)
+		if err == nil {
+			return proof, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to fetch proof of block %s: %w", blockRef, ctx.Err())
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", e.url, err))
+	}
+
+	return nil, fmt.Errorf("failed to fetch proof of block %s from all provers: %s", blockRef, strings.Join(errs, ", "))
+}
+
+// race requests the proof to all the endpoints, and cancels the other requests once a proof is fetched.
+func (f *MultiFetcher) race(ctx context.Context, blockRef eth.L2BlockRef, endpoints []*proverEndpoint) (*ProofAndPair, error) {
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		url   string
+		proof *ProofAndPair
+		err   error
+	}
+	results := make(chan result, len(endpoints))
+	for _, e := range endpoints {
+		go func(e *proverEndpoint) {
+			proof, err := f.fetch(raceCtx, e, blockRef)
+			results <- result{url: e.url, proof: proof, err: err}
+		}(e)
+	}
+
+	var errs []string
+	for range endpoints {
+		res := <-results
+		if res.err == nil {
+			return res.proof, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", res.url, res.err))
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to fetch proof of block %s: %w", blockRef, ctx.Err())
+	}
+
+	return nil, fmt.Errorf("failed to fetch proof of block %s from all provers: %s", blockRef, strings.Join(errs, ", "))
+}
+
+func (f *MultiFetcher) fetch(ctx context.Context, e *proverEndpoint, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	start := time.Now()
+	proof, err := e.fetcher.fetch(ctx, blockRef)
+	// A request canceled by the race or by the caller is not a failure of the endpoint.
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	f.metr.RecordProverFetch(e.url, time.Since(start), err)
+	if err != nil {
+		f.log.Warn("failed to fetch proof from prover", "prover", e.url, "block", blockRef, "err", err)
+		f.setHealthy(e, false)
+		return nil, err
+	}
+
+	f.setHealthy(e, true)
+	return proof, nil
+}
+
+// orderedEndpoints returns the endpoints to request in order by the strategy, the healthy endpoints first.
+func (f *MultiFetcher) orderedEndpoints() []*proverEndpoint {
+	start := 0
+	if f.strategy == StrategyRoundRobin {
+		start = int((f.next.Add(1) - 1) % uint64(len(f.endpoints)))
+	}
+
+	var healthy, unhealthy []*proverEndpoint
+	for i := range f.endpoints {
+		e := f.endpoints[(start+i)%len(f.endpoints)]
+		if e.healthy.Load() {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+
+	// If any endpoint is healthy, the unhealthy endpoints are not requested for the race.
+	if f.strategy == StrategyRace && len(healthy) > 0 {
+		return healthy
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (f *MultiFetcher) healthLoop(ctx context.Context, interval time.Duration) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		f.checkHealth(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *MultiFetcher) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range f.endpoints {
+		wg.Add(1)
+		go func(e *proverEndpoint) {
+			defer wg.Done()
+			if err := e.fetcher.checkHealth(ctx); err != nil {
+				if ctx.Err() == nil {
+					f.log.Warn("prover is unhealthy", "prover", e.url, "err", err)
+					f.setHealthy(e, false)
+				}
+				return
+			}
+			f.setHealthy(e, true)
+		}(e)
+	}
+	wg.Wait()
+}
+
+func (f *MultiFetcher) setHealthy(e *proverEndpoint, healthy bool) {
+	if e.healthy.Swap(healthy) != healthy && healthy {
+		f.log.Info("prover is healthy again", "prover", e.url)
+	}
+	f.metr.RecordProverHealth(e.url, healthy)
+}
+
+func (f *MultiFetcher) Close() error {
+	f.cancel()
+	f.wg.Wait()
+
+	var errs []string
+	for _, e := range f.endpoints {
+		if err := e.fetcher.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", e.url, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close provers: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+)
+
+type fakeEndpoint struct {
+	id    int64
+	delay time.Duration
+
+	mu        sync.Mutex
+	fetchErr  error
+	healthErr error
+	calls     int
+	canceled  int
+}
+
+func (e *fakeEndpoint) fetch(ctx context.Context, _ eth.L2BlockRef) (*ProofAndPair, error) {
+	e.mu.Lock()
+	e.calls++
+	err := e.fetchErr
+	e.mu.Unlock()
+
+	select {
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		e.mu.Lock()
+		e.canceled++
+		e.mu.Unlock()
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &ProofAndPair{Proof: []*big.Int{big.NewInt(e.id)}}, nil
+}
+
+func (e *fakeEndpoint) checkHealth(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.healthErr
+}
+
+func (e *fakeEndpoint) Close() error { return nil }
+
+func (e *fakeEndpoint) numCalls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.calls
+}
+
+func newTestMultiFetcher(t *testing.T, strategy ProverStrategy, fakes ...*fakeEndpoint) *MultiFetcher {
+	var endpoints []*proverEndpoint
+	for i, fake := range fakes {
+		fake.id = int64(i)
+		endpoints = append(endpoints, &proverEndpoint{url: string(rune('a' + i)), fetcher: fake})
+	}
+	f := newMultiFetcher(endpoints, strategy, 0, testlog.Logger(t, log.LvlInfo), metrics.NoopMetrics)
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+	return f
+}
+
+var testBlockRef = eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+
+func TestMultiFetcherPrimary(t *testing.T) {
+	primary := &fakeEndpoint{fetchErr: errors.New("prover is down")}
+	fallback := &fakeEndpoint{}
+	f := newTestMultiFetcher(t, StrategyPrimary, primary, fallback)
+
+	proof, err := f.FetchProofAndPair(context.Background(), testBlockRef)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), proof.Proof[0].Int64())
+
+	// The failed primary is requested after the healthy fallback.
+	_, err = f.FetchProofAndPair(context.Background(), testBlockRef)
+	require.NoError(t, err)
+	require.Equal(t, 1, primary.numCalls())
+	require.Equal(t, 2, fallback.numCalls())
+
+	// The primary is requested first again once it recovers.
+	primary.fetchErr = nil
+	f.checkHealth(context.Background())
+	proof, err = f.FetchProofAndPair(context.Background(), testBlockRef)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), proof.Proof[0].Int64())
+
+	primary.fetchErr = errors.New("prover is down")
+	fallback.fetchErr = errors.New("prover is down")
+	_, err = f.FetchProofAndPair(context.Background(), testBlockRef)
+	require.ErrorContains(t, err, "from all provers")
+}
+
+func TestMultiFetcherRace(t *testing.T) {
+	slow := &fakeEndpoint{delay: time.Minute}
+	fast := &fakeEndpoint{delay: time.Millisecond}
+	failing := &fakeEndpoint{fetchErr: errors.New("prover is down")}
+	f := newTestMultiFetcher(t, StrategyRace, slow, fast, failing)
+
+	proof, err := f.FetchProofAndPair(context.Background(), testBlockRef)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), proof.Proof[0].Int64())
+
+	require.Eventually(t, func() bool {
+		slow.mu.Lock()
+		defer slow.mu.Unlock()
+		return slow.canceled == 1
+	}, time.Second, time.Millisecond, "the slow request must be canceled")
+	require.True(t, slow.healthy(f), "a canceled request must not mark the endpoint unhealthy")
+}
+
+func TestMultiFetcherRoundRobin(t *testing.T) {
+	fakes := []*fakeEndpoint{{}, {}, {}}
+	f := newTestMultiFetcher(t, StrategyRoundRobin, fakes...)
+
+	for i := 0; i < 6; i++ {
+		proof, err := f.FetchProofAndPair(context.Background(), testBlockRef)
+		require.NoError(t, err)
+		require.Equal(t, int64(i%3), proof.Proof[0].Int64())
+	}
+}
+
+func TestMultiFetcherHealthCheck(t *testing.T) {
+	unhealthy := &fakeEndpoint{healthErr: errors.New("connection refused")}
+	healthy := &fakeEndpoint{}
+	f := newTestMultiFetcher(t, StrategyPrimary, unhealthy, healthy)
+
+	f.checkHealth(context.Background())
+	proof, err := f.FetchProofAndPair(context.Background(), testBlockRef)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), proof.Proof[0].Int64())
+	require.Equal(t, 0, unhealthy.numCalls())
+}
+
+// healthy returns whether the endpoint of the fake is healthy.
+func (e *fakeEndpoint) healthy(f *MultiFetcher) bool {
+	for _, endpoint := range f.endpoints {
+		if endpoint.fetcher == e {
+			return endpoint.healthy.Load()
+		}
+	}
+	return false
+}
+
+func TestMultiFetcherCancel(t *testing.T) {
+	for _, strategy := range []ProverStrategy{StrategyPrimary, StrategyRoundRobin, StrategyRace} {
+		first := &fakeEndpoint{delay: time.Minute}
+		second := &fakeEndpoint{delay: time.Minute}
+		f := newTestMultiFetcher(t, strategy, first, second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := f.FetchProofAndPair(ctx, testBlockRef)
+		cancel()
+		require.ErrorIs(t, err, context.DeadlineExceeded, strategy)
+
+		// The canceled request is not a failure of the endpoints, and the remaining
+		// endpoints are not requested once canceled.
+		require.True(t, first.healthy(f), strategy)
+		require.True(t, second.healthy(f), strategy)
+		if strategy != StrategyRace {
+			require.Equal(t, 1, first.numCalls()+second.numCalls(), strategy)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package challenge
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// challengeTxGas is the estimated gas of a createChallenge or bisect transaction, except the segments.
+	challengeTxGas = 100_000
+	// segmentGas is the estimated gas to submit and store a single segment.
+	segmentGas = 25_000
+)
+
+type DecisionKind string
+
+const (
+	// DecisionChallenge creates the challenge.
+	DecisionChallenge DecisionKind = "challenge"
+	// DecisionWait waits for the challenge in progress of another output to end.
+	DecisionWait DecisionKind = "wait"
+	// DecisionDefer waits for another challenger engaged with the same output.
+	DecisionDefer DecisionKind = "defer"
+	// DecisionTooLate skips the output which would be finalized before the challenge ends.
+	DecisionTooLate DecisionKind = "too_late"
+	// DecisionTooExpensive skips the challenge costing more than the configured maximum.
+	DecisionTooExpensive DecisionKind = "too_expensive"
+	// DecisionInsufficientBalance skips the challenge which cannot be afforded by the balance of the validator.
+	DecisionInsufficientBalance DecisionKind = "insufficient_balance"
+	// DecisionClose closes the challenge timed out by the challenger.
+	DecisionClose DecisionKind = "close"
+)
+
+type PolicyConfig struct {
+	// MinBalance is the balance kept by the validator after paying for a whole challenge.
+	MinBalance *big.Int
+	// MaxCost is the maximum estimated cost of a challenge. Nil or zero means no maximum.
+	MaxCost *big.Int
+	// ProveFaultGas is the estimated gas of a proveFault transaction.
+	ProveFaultGas uint64
+	// MaxTimeoutCost is the maximum cost of closing a challenge timed out by the challenger. Nil or zero means
+	// the timed out challenges are not closed by the asserter.
+	MaxTimeoutCost *big.Int
+}
+
+// ChallengeState is the state of the chain in which an invalid output is going to be challenged.
+type ChallengeState struct {
+	// InProgress is set if a challenge is in progress on the Colosseum.
+	InProgress bool
+	// EngagedChallenger is the challenger of the challenge in progress, if it is of the same output.
+	EngagedChallenger common.Address
+	// Now is the timestamp of the L1 head.
+	Now uint64
+	// FinalizedAt is the timestamp at which the output is finalized, and cannot be deleted anymore.
+	FinalizedAt uint64
+	GasPrice    *big.Int
+	Balance     *big.Int
+}
+
+type Decision struct {
+	Kind   DecisionKind
+	Reason string
+	// Cost is the estimated cost of the whole challenge, if it was estimated.
+	Cost *big.Int
+}
+
+// ChallengePolicy decides whether an invalid output is worth being challenged, by estimating the cost
+// and the duration of the whole bisection game.
+type ChallengePolicy struct {
+	cfg              PolicyConfig
+	segmentsLengths  []uint64
+	challengeTimeout uint64
+}
+
+// NewChallengePolicy creates a policy for the game with the given segments lengths by turn.
+func NewChallengePolicy(cfg PolicyConfig, segmentsLengths []uint64, challengeTimeout uint64) *ChallengePolicy {
+	return &ChallengePolicy{
+		cfg:              cfg,
+		segmentsLengths:  segmentsLengths,
+		challengeTimeout: challengeTimeout,
+	}
+}
+
+// EstimateGas returns the gas spent by the challenger to play the whole game, which consists of
+// createChallenge on the first turn, bisect on the following challenger turns, and proveFault.
+// The challenger turns are odd numbers.
+func (p *ChallengePolicy) EstimateGas() uint64 {
+	gas := p.cfg.ProveFaultGas
+	for turn := 1; turn <= len(p.segmentsLengths); turn += 2 {
+		gas += challengeTxGas + segmentGas*p.segmentsLengths[turn-1]
+	}
+	return gas
+}
+
+// Duration returns the longest duration of the game, when the asserter takes the whole timeout on its turns.
+// The asserter turns are even numbers.
+func (p *ChallengePolicy) Duration() uint64 {
+	return uint64(len(p.segmentsLengths)/2) * p.challengeTimeout
+}
+
+func (p *ChallengePolicy) Decide(s ChallengeState) Decision {
+	if s.InProgress {
+		if s.EngagedChallenger != (common.Address{}) {
+			return Decision{
+				Kind:   DecisionDefer,
+				Reason: fmt.Sprintf("challenger %s is already engaged with the output", s.EngagedChallenger),
+			}
+		}
+		return Decision{Kind: DecisionWait, Reason: "another challenge is in progress"}
+	}
+
+	if s.Now+p.Duration() >= s.FinalizedAt {
+		return Decision{
+			Kind:   DecisionTooLate,
+			Reason: fmt.Sprintf("output is finalized at %d, before the challenge can end at %d", s.FinalizedAt, s.Now+p.Duration()),
+		}
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(p.EstimateGas()), s.GasPrice)
+	if p.cfg.MaxCost != nil && p.cfg.MaxCost.Sign() > 0 && cost.Cmp(p.cfg.MaxCost) > 0 {
+		return Decision{
+			Kind:   DecisionTooExpensive,
+			Reason: fmt.Sprintf("estimated cost %s exceeds the maximum %s", cost, p.cfg.MaxCost),
+			Cost:   cost,
+		}
+	}
+
+	required := new(big.Int).Set(cost)
+	if p.cfg.MinBalance != nil {
+		required.Add(required, p.cfg.MinBalance)
+	}
+	if s.Balance.Cmp(required) < 0 {
+		return Decision{
+			Kind:   DecisionInsufficientBalance,
+			Reason: fmt.Sprintf("balance %s is less than the estimated cost %s and the minimum balance", s.Balance, cost),
+			Cost:   cost,
+		}
+	}
+
+	return Decision{Kind: DecisionChallenge, Reason: "output is challengeable", Cost: cost}
+}
+
+// DecideTimeout decides whether the asserter closes a challenge timed out by the challenger with the given cost.
+// The Colosseum does not hold bonds, so there is nothing to reclaim but the challenge is closed for good.
+func (p *ChallengePolicy) DecideTimeout(cost *big.Int) Decision {
+	if p.cfg.MaxTimeoutCost == nil || p.cfg.MaxTimeoutCost.Sign() == 0 {
+		return Decision{Kind: DecisionTooExpensive, Reason: "closing timed out challenges is disabled", Cost: cost}
+	}
+	if cost.Cmp(p.cfg.MaxTimeoutCost) > 0 {
+		return Decision{
+			Kind:   DecisionTooExpensive,
+			Reason: fmt.Sprintf("cost %s exceeds the maximum %s", cost, p.cfg.MaxTimeoutCost),
+			Cost:   cost,
+		}
+	}
+	return Decision{Kind: DecisionClose, Reason: "challenger timed out", Cost: cost}
+}
@@ -0,0 +1,74 @@
+package challenge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengePolicyEstimate(t *testing.T) {
+	p := NewChallengePolicy(PolicyConfig{ProveFaultGas: 1_000_000}, []uint64{9, 6, 10, 6}, 100)
+
+	// createChallenge with 9 segments, bisect with 10 segments and proveFault.
+	require.Equal(t, uint64(2*challengeTxGas+19*segmentGas+1_000_000), p.EstimateGas())
+	require.Equal(t, uint64(200), p.Duration())
+}
+
+func TestChallengePolicyDecide(t *testing.T) {
+	p := NewChallengePolicy(PolicyConfig{
+		MinBalance:    big.NewInt(1000),
+		MaxCost:       big.NewInt(1_000_000),
+		ProveFaultGas: 1000,
+	}, []uint64{3, 3}, 100)
+	gas := int64(p.EstimateGas())
+
+	state := func(modify func(s *ChallengeState)) ChallengeState {
+		s := ChallengeState{
+			Now:         1000,
+			FinalizedAt: 2000,
+			GasPrice:    big.NewInt(1),
+			Balance:     big.NewInt(gas + 1000),
+		}
+		if modify != nil {
+			modify(&s)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		state  ChallengeState
+		expect DecisionKind
+	}{
+		{"challenge", state(nil), DecisionChallenge},
+		{"wait", state(func(s *ChallengeState) { s.InProgress = true }), DecisionWait},
+		{"defer", state(func(s *ChallengeState) {
+			s.InProgress = true
+			s.EngagedChallenger = common.Address{1}
+		}), DecisionDefer},
+		{"too late", state(func(s *ChallengeState) { s.FinalizedAt = 1100 }), DecisionTooLate},
+		{"too expensive", state(func(s *ChallengeState) {
+			s.GasPrice = big.NewInt(1_000_000/gas + 1)
+			s.Balance = big.NewInt(1e18)
+		}), DecisionTooExpensive},
+		{"insufficient balance", state(func(s *ChallengeState) { s.Balance = big.NewInt(gas + 999) }), DecisionInsufficientBalance},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision := p.Decide(test.state)
+			require.Equal(t, test.expect, decision.Kind, decision.Reason)
+		})
+	}
+}
+
+func TestChallengePolicyDecideTimeout(t *testing.T) {
+	p := NewChallengePolicy(PolicyConfig{MaxTimeoutCost: big.NewInt(1000)}, []uint64{3, 3}, 100)
+	require.Equal(t, DecisionClose, p.DecideTimeout(big.NewInt(1000)).Kind)
+	require.Equal(t, DecisionTooExpensive, p.DecideTimeout(big.NewInt(1001)).Kind)
+
+	p = NewChallengePolicy(PolicyConfig{}, []uint64{3, 3}, 100)
+	require.Equal(t, DecisionTooExpensive, p.DecideTimeout(big.NewInt(1)).Kind)
+}
@@ -0,0 +1,292 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	"github.com/wemixkanvas/kanvas/utils/service/backoff"
+)
+
+const (
+	// maxProofAttempts is the number of attempts to fetch a proof before the job fails.
+	maxProofAttempts = 5
+	// maxConcurrentProofJobs is the number of proofs requested to the prover at the same time.
+	maxConcurrentProofJobs = 2
+	// maxCachedProofJobs is the number of finished jobs kept in the cache.
+	maxCachedProofJobs = 16
+)
+
+var ErrProofPending = errors.New("proof is not ready yet")
+
+type ProofJobStatus string
+
+const (
+	ProofJobQueued  ProofJobStatus = "queued"
+	ProofJobRunning ProofJobStatus = "running"
+	ProofJobDone    ProofJobStatus = "done"
+	ProofJobFailed  ProofJobStatus = "failed"
+)
+
+type proofFetcher interface {
+	FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error)
+}
+
+// ProofJob is the state of the proof generation of a single L2 block.
+type ProofJob struct {
+	BlockRef   eth.L2BlockRef `json:"blockRef"`
+	Status     ProofJobStatus `json:"status"`
+	Attempts   int            `json:"attempts"`
+	Err        string         `json:"err,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	FinishedAt time.Time      `json:"finishedAt,omitempty"`
+
+	result *ProofAndPair
+	done   chan struct{}
+}
+
+// ProofManager requests the proofs to the prover in the background, so that the proof generation
+// does not block the challenger. The proofs are cached by block hash, and the failed requests are retried.
+type ProofManager struct {
+	log     log.Logger
+	fetcher proofFetcher
+	metr    metrics.ProofMetricer
+
+	strategy backoff.Strategy
+	slots    chan struct{}
+	// onFinished is called when a job is finished, successfully or not.
+	onFinished func(job ProofJob)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[common.Hash]*ProofJob
+}
+
+func NewProofManager(ctx context.Context, l log.Logger, fetcher proofFetcher, m metrics.ProofMetricer, onFinished func(job ProofJob)) *ProofManager {
+	ctx, cancel := context.WithCancel(ctx)
+	return &ProofManager{
+		log:        l,
+		fetcher:    fetcher,
+		metr:       m,
+		strategy:   backoff.Exponential(),
+		slots:      make(chan struct{}, maxConcurrentProofJobs),
+		onFinished: onFinished,
+		ctx:        ctx,
+		cancel:     cancel,
+		jobs:       make(map[common.Hash]*ProofJob),
+	}
+}
+
+// Request starts generating the proof of the given block, unless it is already requested.
+// A failed job is started again.
+func (m *ProofManager) Request(blockRef eth.L2BlockRef) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.request(blockRef)
+}
+
+func (m *ProofManager) request(blockRef eth.L2BlockRef) *ProofJob {
+	if job, ok := m.jobs[blockRef.Hash]; ok && job.Status != ProofJobFailed {
+		return job
+	}
+
+	job := &ProofJob{
+		BlockRef:  blockRef,
+		Status:    ProofJobQueued,
+		CreatedAt: time.Now(),
+		done:      make(chan struct{}),
+	}
+	m.jobs[blockRef.Hash] = job
+	m.log.Info("requesting proof", "block", blockRef)
+	m.metr.RecordProofRequested()
+	m.recordActiveJobs()
+
+	m.wg.Add(1)
+	go m.run(job)
+
+	return job
+}
+
+// Result returns the proof of the given block. If the proof is not generated yet, ErrProofPending is returned.
+func (m *ProofManager) Result(blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[blockRef.Hash]
+	if !ok {
+		return nil, fmt.Errorf("proof of block %s is not requested", blockRef)
+	}
+
+	return job.proof()
+}
+
+func (job *ProofJob) proof() (*ProofAndPair, error) {
+	switch job.Status {
+	case ProofJobDone:
+		return job.result, nil
+	case ProofJobFailed:
+		return nil, fmt.Errorf("failed to generate proof of block %s: %s", job.BlockRef, job.Err)
+	default:
+		return nil, ErrProofPending
+	}
+}
+
+// Wait requests the proof of the given block if needed, and waits until it is generated.
+func (m *ProofManager) Wait(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	m.mu.Lock()
+	job := m.request(blockRef)
+	m.mu.Unlock()
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return job.proof()
+}
+
+// Reject fails the finished job of the given block, because its proof turned out to be invalid.
+// The job is started again when it is requested.
+func (m *ProofManager) Reject(blockRef eth.L2BlockRef, reason error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[blockRef.Hash]
+	if !ok || job.Status != ProofJobDone {
+		return
+	}
+
+	m.log.Warn("rejecting proof", "block", blockRef, "reason", reason)
+	m.metr.RecordProofRejected()
+	job.Status = ProofJobFailed
+	job.Err = fmt.Sprintf("rejected: %v", reason)
+	job.result = nil
+}
+
+// Jobs returns the state of the cached jobs, ordered by block number.
+func (m *ProofManager) Jobs() []ProofJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]ProofJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].BlockRef.Number < jobs[j].BlockRef.Number
+	})
+
+	return jobs
+}
+
+// Close stops the running jobs.
+func (m *ProofManager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+func (m *ProofManager) run(job *ProofJob) {
+	defer m.wg.Done()
+
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-m.ctx.Done():
+		m.finish(job, nil, m.ctx.Err())
+		return
+	}
+
+	m.mu.Lock()
+	job.Status = ProofJobRunning
+	blockRef := job.BlockRef
+	m.mu.Unlock()
+
+	var result *ProofAndPair
+	err := backoff.DoCtx(m.ctx, maxProofAttempts, m.strategy, func() error {
+		m.mu.Lock()
+		job.Attempts++
+		m.mu.Unlock()
+
+		proof, err := m.fetcher.FetchProofAndPair(m.ctx, blockRef)
+		if err != nil {
+			m.log.Warn("failed to fetch proof", "block", blockRef, "err", err)
+			m.metr.RecordProofFetchFailed()
+			return err
+		}
+		result = proof
+		return nil
+	})
+
+	m.finish(job, result, err)
+}
+
+func (m *ProofManager) finish(job *ProofJob, result *ProofAndPair, err error) {
+	m.mu.Lock()
+	job.FinishedAt = time.Now()
+	if err != nil {
+		job.Status = ProofJobFailed
+		job.Err = err.Error()
+		m.log.Error("failed to generate proof", "block", job.BlockRef, "attempts", job.Attempts, "err", err)
+		m.metr.RecordProofFailed()
+	} else {
+		job.Status = ProofJobDone
+		job.result = result
+		m.log.Info("proof generated", "block", job.BlockRef, "attempts", job.Attempts)
+		m.metr.RecordProofSucceeded(job.FinishedAt.Sub(job.CreatedAt))
+	}
+	close(job.done)
+	m.evict()
+	m.recordActiveJobs()
+	finished := *job
+	m.mu.Unlock()
+
+	if m.onFinished != nil && m.ctx.Err() == nil {
+		m.onFinished(finished)
+	}
+}
+
+// evict drops the oldest finished jobs above the cache size.
+func (m *ProofManager) evict() {
+	var finished []*ProofJob
+	for _, job := range m.jobs {
+		if job.Status == ProofJobDone || job.Status == ProofJobFailed {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= maxCachedProofJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(finished[j].FinishedAt)
+	})
+	for _, job := range finished[:len(finished)-maxCachedProofJobs] {
+		delete(m.jobs, job.BlockRef.Hash)
+	}
+}
+
+func (m *ProofManager) recordActiveJobs() {
+	var active int
+	for _, job := range m.jobs {
+		if job.Status == ProofJobQueued || job.Status == ProofJobRunning {
+			active++
+		}
+	}
+	m.metr.RecordProofJobs(active)
+}
@@ -0,0 +1,178 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	"github.com/wemixkanvas/kanvas/utils/service/backoff"
+)
+
+type mockFetcher struct {
+	mu sync.Mutex
+	// failures is the number of fetches failing before succeeding, by block number.
+	failures map[uint64]int
+	calls    map[uint64]int
+	// blocking makes the fetches wait until they are canceled.
+	blocking bool
+}
+
+func (f *mockFetcher) FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*ProofAndPair, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[blockRef.Number]++
+	if f.blocking {
+		f.mu.Unlock()
+		<-ctx.Done()
+		f.mu.Lock()
+		return nil, ctx.Err()
+	}
+	if f.failures[blockRef.Number] > 0 {
+		f.failures[blockRef.Number]--
+		return nil, errors.New("prover is not available")
+	}
+
+	return &ProofAndPair{
+		Proof: []*big.Int{new(big.Int).SetUint64(blockRef.Number)},
+		Pair:  []*big.Int{big.NewInt(1)},
+	}, nil
+}
+
+func (f *mockFetcher) numCalls(number uint64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[number]
+}
+
+func newTestProofManager(t *testing.T, fetcher *mockFetcher, onFinished func(job ProofJob)) *ProofManager {
+	m := NewProofManager(context.Background(), testlog.Logger(t, log.LvlInfo), fetcher, metrics.NoopMetrics, onFinished)
+	m.strategy = backoff.Fixed(time.Millisecond)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestProofManagerCache(t *testing.T) {
+	fetcher := &mockFetcher{failures: map[uint64]int{}, calls: map[uint64]int{}}
+	finished := make(chan ProofJob, 1)
+	m := newTestProofManager(t, fetcher, func(job ProofJob) { finished <- job })
+
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	m.Request(blockRef)
+
+	job := <-finished
+	require.Equal(t, ProofJobDone, job.Status)
+
+	proof, err := m.Result(blockRef)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), proof.Proof[0].Uint64())
+
+	// The proof is cached by block hash.
+	m.Request(blockRef)
+	proof, err = m.Wait(context.Background(), blockRef)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), proof.Proof[0].Uint64())
+	require.Equal(t, 1, fetcher.numCalls(10))
+
+	_, err = m.Result(eth.L2BlockRef{Hash: common.Hash{2}, Number: 10})
+	require.Error(t, err, "proof of a reorged block must not be returned")
+}
+
+func TestProofManagerRetry(t *testing.T) {
+	fetcher := &mockFetcher{failures: map[uint64]int{10: 2, 11: maxProofAttempts}, calls: map[uint64]int{}}
+	m := newTestProofManager(t, fetcher, nil)
+
+	proof, err := m.Wait(context.Background(), eth.L2BlockRef{Hash: common.Hash{1}, Number: 10})
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+	require.Equal(t, 3, fetcher.numCalls(10))
+
+	failedRef := eth.L2BlockRef{Hash: common.Hash{2}, Number: 11}
+	_, err = m.Wait(context.Background(), failedRef)
+	require.Error(t, err)
+	require.Equal(t, maxProofAttempts, fetcher.numCalls(11))
+
+	jobs := m.Jobs()
+	require.Len(t, jobs, 2)
+	require.Equal(t, ProofJobDone, jobs[0].Status)
+	require.Equal(t, ProofJobFailed, jobs[1].Status)
+	require.Equal(t, maxProofAttempts, jobs[1].Attempts)
+
+	// A failed job is started again when requested.
+	proof, err = m.Wait(context.Background(), failedRef)
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+}
+
+func TestProofManagerPending(t *testing.T) {
+	fetcher := &mockFetcher{failures: map[uint64]int{}, calls: map[uint64]int{}}
+	m := newTestProofManager(t, fetcher, nil)
+	// Fill the slots, so that the job stays queued.
+	for i := 0; i < maxConcurrentProofJobs; i++ {
+		m.slots <- struct{}{}
+	}
+
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	m.Request(blockRef)
+	_, err := m.Result(blockRef)
+	require.ErrorIs(t, err, ErrProofPending)
+	require.Equal(t, ProofJobQueued, m.Jobs()[0].Status)
+
+	<-m.slots
+	proof, err := m.Wait(context.Background(), blockRef)
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+}
+
+func TestProofManagerReject(t *testing.T) {
+	fetcher := &mockFetcher{failures: map[uint64]int{}, calls: map[uint64]int{}}
+	m := newTestProofManager(t, fetcher, nil)
+
+	blockRef := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	_, err := m.Wait(context.Background(), blockRef)
+	require.NoError(t, err)
+
+	m.Reject(blockRef, ErrInvalidProof)
+	_, err = m.Result(blockRef)
+	require.Error(t, err, "rejected proof must not be returned")
+	require.Equal(t, ProofJobFailed, m.Jobs()[0].Status)
+
+	// The rejected proof is fetched again when requested.
+	proof, err := m.Wait(context.Background(), blockRef)
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+	require.Equal(t, 2, fetcher.numCalls(10))
+}
+
+func TestProofManagerCloseCancelsFetch(t *testing.T) {
+	fetcher := &mockFetcher{failures: map[uint64]int{}, calls: map[uint64]int{}, blocking: true}
+	finished := make(chan ProofJob, 1)
+	m := NewProofManager(context.Background(), testlog.Logger(t, log.LvlCrit), fetcher, metrics.NoopMetrics, func(job ProofJob) { finished <- job })
+
+	m.Request(eth.L2BlockRef{Hash: common.Hash{1}, Number: 10})
+	require.Eventually(t, func() bool { return fetcher.numCalls(10) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		m.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close must not wait for the in-flight fetch")
+	}
+	require.Equal(t, ProofJobFailed, m.Jobs()[0].Status)
+	require.Empty(t, finished, "finished callback must not be called after close")
+}
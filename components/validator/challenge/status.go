@@ -1,5 +1,10 @@
 package challenge
 
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
 const (
 	StatusNone uint8 = iota
 	StatusChallengerTurn
@@ -8,3 +13,38 @@ const (
 	StatusAsserterTimeout
 	StatusProveReady
 )
+
+// StatusName returns the name of the challenge status.
+func StatusName(status uint8) string {
+	switch status {
+	case StatusNone:
+		return "none"
+	case StatusChallengerTurn:
+		return "challenger_turn"
+	case StatusAsserterTurn:
+		return "asserter_turn"
+	case StatusChallengerTimeout:
+		return "challenger_timeout"
+	case StatusAsserterTimeout:
+		return "asserter_timeout"
+	case StatusProveReady:
+		return "prove_ready"
+	default:
+		return "unknown"
+	}
+}
+
+// ChallengeInfo is the state of a challenge on the Colosseum.
+type ChallengeInfo struct {
+	ChallengeId *hexutil.Big   `json:"challengeId"`
+	OutputIndex *hexutil.Big   `json:"outputIndex"`
+	Turn        hexutil.Uint64 `json:"turn"`
+	Status      string         `json:"status"`
+	TimeoutAt   hexutil.Uint64 `json:"timeoutAt"`
+	Asserter    common.Address `json:"asserter"`
+	Challenger  common.Address `json:"challenger"`
+	SegStart    hexutil.Uint64 `json:"segStart"`
+	SegSize     hexutil.Uint64 `json:"segSize"`
+	// Segments are the segments of the current turn.
+	Segments []common.Hash `json:"segments"`
+}
@@ -5,27 +5,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/wemixkanvas/kanvas/bindings/bindings"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
+	"github.com/wemixkanvas/kanvas/components/node/sources"
 	chal "github.com/wemixkanvas/kanvas/components/validator/challenge"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	"github.com/wemixkanvas/kanvas/components/validator/store"
 	"github.com/wemixkanvas/kanvas/utils"
 )
 
-var OutputsPerWeek = big.NewInt(24 * 7)
+const (
+	// outputsBatchSize is the number of outputs fetched from the L2OutputOracle in a single batch.
+	outputsBatchSize = 100
+	// fullUpdateTicks is the number of poll intervals after which the challenges are updated even without events.
+	fullUpdateTicks = 10
+)
 
 type ProofFetcher interface {
-	FetchProofAndPair(blockRef eth.L2BlockRef) (*chal.ProofAndPair, error)
+	FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*chal.ProofAndPair, error)
 	Close() error
 }
 
+// ProofVerifier verifies the proofs locally, before they are submitted to the Colosseum.
+type ProofVerifier interface {
+	Verify(opts *bind.CallOpts, proof []*big.Int, pair []*big.Int) (bool, error)
+}
+
 type Challenger struct {
 	wg   sync.WaitGroup
 	done chan struct{}
@@ -39,13 +60,57 @@ type Challenger struct {
 
 	l2ooContract      *bindings.L2OutputOracle
 	colosseumContract *bindings.Colosseum
+	zkVerifier        ProofVerifier
 
-	fetcher            ProofFetcher
+	metr               metrics.Metricer
+	proofs             *chal.ProofManager
+	policy             *chal.ChallengePolicy
+	store              *store.Store
+	watcher            *EventWatcher
 	submissionInterval *big.Int
+	finalizationPeriod *big.Int
+	verifyConcurrency  int
+	challengeTimeout   *big.Int
 	checkpoint         *big.Int
+
+	// invalidOutputs is the queue of invalid outputs to be challenged, ordered by output index.
+	invalidOutputs []*OutputRange
+
+	// updateCh is signaled when the watched events require to update the challenges.
+	updateCh chan struct{}
+	// outputsDeletedCh is signaled when the outputs are deleted by the Colosseum, to be submitted again.
+	outputsDeletedCh chan struct{}
+
+	// mu protects the state updated by the event loop.
+	mu sync.Mutex
+	// updateRequired is set until the challenges are updated successfully after an event.
+	updateRequired bool
+	// l1Time is the timestamp of the last watched L1 head.
+	l1Time uint64
+	// deadline is the L1 timestamp at which the status of a tracked challenge changes without an event.
+	deadline uint64
+	// rewindTo is the lowest output index submitted in a reorged L1 block, to be verified again.
+	rewindTo *big.Int
+	// resync is set if the reorg is too deep to know which outputs have to be verified again.
+	resync bool
+	// rescanFrom is the output index requested to verify the outputs again from, without the stored results.
+	rescanFrom *big.Int
+
+	// rescanUntil is the checkpoint before the rescan. The stored results of the outputs before it are not reused.
+	rescanUntil *big.Int
+	// pendingSegments are the segments of the last createChallenge or bisect transaction, stored once it is included.
+	pendingSegments *pendingSegments
 }
 
-func NewChallenger(ctx context.Context, cfg Config, l log.Logger) (*Challenger, error) {
+// pendingSegments are the segments submitted by a transaction which is not included yet.
+type pendingSegments struct {
+	txHash      common.Hash
+	outputIndex *big.Int
+	turn        uint64
+	segments    *chal.Segments
+}
+
+func NewChallenger(ctx context.Context, cfg Config, l log.Logger, m metrics.Metricer) (*Challenger, error) {
 	colosseumContract, err := bindings.NewColosseum(cfg.ColosseumAddr, cfg.L1Client)
 	if err != nil {
 		return nil, err
@@ -60,20 +125,401 @@ func NewChallenger(ctx context.Context, cfg Config, l log.Logger) (*Challenger,
 		return nil, fmt.Errorf("failed to get submission interval: %w", err)
 	}
 
-	return &Challenger{
+	finalizationPeriod, err := l2ooContract.FINALIZATIONPERIODSECONDS(utils.NewSimpleCallOpts(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get finalization period: %w", err)
+	}
+
+	challengeTimeout, err := colosseumContract.CHALLENGETIMEOUT(utils.NewSimpleCallOpts(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenge timeout: %w", err)
+	}
+
+	segmentsLengths, err := fetchSegmentsLengths(ctx, colosseumContract)
+	if err != nil {
+		return nil, err
+	}
+
+	zkVerifierAddr, err := colosseumContract.ZKVERIFIER(utils.NewSimpleCallOpts(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zk verifier address: %w", err)
+	}
+	zkVerifier, err := bindings.NewZKVerifierCaller(zkVerifierAddr, cfg.L1Client)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := newChallengeEventWatcher(cfg, l)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyConcurrency := cfg.VerifyConcurrency
+	if verifyConcurrency < 1 {
+		verifyConcurrency = 1
+	}
+
+	s := cfg.Store
+	if s == nil {
+		s = store.NewMemoryStore()
+	}
+
+	checkpoint, err := s.Checkpoint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if checkpoint != nil {
+		l.Info("resuming challenger from stored checkpoint", "checkpoint", checkpoint)
+	}
+
+	// The invalid outputs found before the restart are queued again. The ones which were
+	// challenged or deleted in the meantime are dropped before creating a challenge.
+	invalidRecords, err := s.InvalidOutputs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invalid outputs: %w", err)
+	}
+	invalidOutputs := make([]*OutputRange, len(invalidRecords))
+	for i, record := range invalidRecords {
+		invalidOutputs[i] = &OutputRange{
+			OutputIndex: record.OutputIndex.ToInt(),
+			OutputRoot:  record.OutputRoot,
+			StartBlock:  uint64(record.L2BlockNumber) - submissionInterval.Uint64(),
+			EndBlock:    uint64(record.L2BlockNumber),
+		}
+	}
+
+	c := &Challenger{
 		done:     make(chan struct{}),
 		log:      l,
 		ctx:      ctx,
 		cfg:      cfg,
 		callOpts: utils.NewCallOptsWithSender(ctx, cfg.From),
-		txOpts:   utils.NewSimpleTxOpts(ctx, cfg.From, cfg.SignerFn),
+		txOpts:   newTxOpts(ctx, cfg),
 
 		l2ooContract:      l2ooContract,
 		colosseumContract: colosseumContract,
+		zkVerifier:        zkVerifier,
 
-		fetcher:            cfg.ProofFetcher,
+		metr:               m,
+		policy:             chal.NewChallengePolicy(cfg.ChallengePolicy, segmentsLengths, challengeTimeout.Uint64()),
+		store:              s,
+		watcher:            watcher,
 		submissionInterval: submissionInterval,
-	}, nil
+		finalizationPeriod: finalizationPeriod,
+		verifyConcurrency:  verifyConcurrency,
+		challengeTimeout:   challengeTimeout,
+		checkpoint:         checkpoint,
+		invalidOutputs:     invalidOutputs,
+
+		updateCh:         make(chan struct{}, 1),
+		outputsDeletedCh: make(chan struct{}, 1),
+		updateRequired:   true,
+	}
+
+	if cfg.ProofFetcher != nil {
+		// The challenges are updated as soon as a proof is generated, to submit it without waiting for the next poll.
+		c.proofs = chal.NewProofManager(ctx, l, cfg.ProofFetcher, m, func(chal.ProofJob) {
+			c.notifyUpdate()
+		})
+	}
+
+	return c, nil
+}
+
+// fetchSegmentsLengths returns the lengths of the segments by turn, which are set until the last turn.
+func fetchSegmentsLengths(ctx context.Context, colosseumContract *bindings.Colosseum) ([]uint64, error) {
+	var lengths []uint64
+	for turn := int64(1); ; turn++ {
+		length, err := colosseumContract.GetSegmentsLength(utils.NewSimpleCallOpts(ctx), big.NewInt(turn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get segments length of turn %d: %w", turn, err)
+		}
+		if length.Sign() == 0 {
+			return lengths, nil
+		}
+		lengths = append(lengths, length.Uint64())
+	}
+}
+
+// newChallengeEventWatcher creates a watcher of the events which change the state of the challenges
+// or submit new outputs to be verified.
+func newChallengeEventWatcher(cfg Config, l log.Logger) (*EventWatcher, error) {
+	colosseumAbi, err := bindings.ColosseumMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	l2ooAbi, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	topics := []common.Hash{
+		colosseumAbi.Events["ChallengeCreated"].ID,
+		colosseumAbi.Events["Bisected"].ID,
+		colosseumAbi.Events["ProofCompleted"].ID,
+		colosseumAbi.Events["Closed"].ID,
+		l2ooAbi.Events["OutputSubmitted"].ID,
+		l2ooAbi.Events["OutputsDeleted"].ID,
+	}
+
+	return NewEventWatcher(l, cfg.L1Client, []common.Address{cfg.ColosseumAddr, cfg.L2OutputOracleAddr}, topics), nil
+}
+
+// Start watches the events of the Colosseum and the L2OutputOracle, and signals UpdateCh
+// when the challenges have to be updated.
+func (c *Challenger) Start() {
+	c.wg.Add(1)
+	go c.eventLoop()
+}
+
+func (c *Challenger) Stop() {
+	close(c.done)
+	c.wg.Wait()
+	if c.proofs != nil {
+		c.proofs.Close()
+	}
+}
+
+// ProofJobs returns the state of the proof jobs requested to the prover.
+func (c *Challenger) ProofJobs() []chal.ProofJob {
+	if c.proofs == nil {
+		return nil
+	}
+	return c.proofs.Jobs()
+}
+
+// UpdateCh is signaled when DetermineChallengeTx has to be called.
+func (c *Challenger) UpdateCh() <-chan struct{} {
+	return c.updateCh
+}
+
+// OutputsDeletedCh is signaled when the outputs are deleted from the L2OutputOracle, so that the output
+// submitter submits them again without waiting for the next poll.
+func (c *Challenger) OutputsDeletedCh() <-chan struct{} {
+	return c.outputsDeletedCh
+}
+
+// IsUpdateRequired returns true if the challenges were not updated since the last event,
+// or if the last update failed.
+func (c *Challenger) IsUpdateRequired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.updateRequired
+}
+
+// RequireUpdate makes the challenges updated again on the next poll, e.g. after the transaction to submit failed.
+func (c *Challenger) RequireUpdate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.updateRequired = true
+}
+
+// notifyUpdate makes the challenges updated immediately.
+func (c *Challenger) notifyUpdate() {
+	c.RequireUpdate()
+
+	select {
+	case c.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Challenger) eventLoop() {
+	defer c.wg.Done()
+
+	// New L1 heads are pushed if the L1 client supports subscriptions. Otherwise, the L1 head is polled.
+	heads := make(chan *types.Header, 10)
+	var headErr <-chan error
+	sub, err := c.cfg.L1Client.SubscribeNewHead(c.ctx, heads)
+	if err != nil {
+		c.log.Info("cannot subscribe to new L1 heads, polling instead", "err", err)
+	} else {
+		defer sub.Unsubscribe()
+		headErr = sub.Err()
+	}
+	polling := sub == nil
+
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+
+	ticks := 0
+	for {
+		select {
+		case <-heads:
+			c.pollEvents()
+		case <-ticker.C:
+			if polling {
+				c.pollEvents()
+			}
+			// The queued outputs and the open challenges are re-evaluated without events, since the decisions
+			// to wait or to skip them depend on the L1 fees, the balance and the time.
+			ticks++
+			if ticks%fullUpdateTicks == 0 {
+				c.notifyUpdate()
+			}
+		case err := <-headErr:
+			c.log.Warn("L1 head subscription failed, polling instead", "err", err)
+			headErr = nil
+			polling = true
+		case <-c.done:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Challenger) pollEvents() {
+	ctx, cancel := context.WithTimeout(c.ctx, time.Minute)
+	defer cancel()
+
+	head, added, removed, incomplete, err := c.watcher.Poll(ctx)
+	if err != nil {
+		c.log.Error("failed to poll challenge events", "err", err)
+		return
+	}
+
+	c.handleDeletedOutputs(ctx, added)
+
+	c.mu.Lock()
+	c.l1Time = head.Time
+	for _, l := range removed {
+		if ev, err := c.l2ooContract.ParseOutputSubmitted(l); err == nil {
+			c.log.Info("output submission was reorged", "outputIndex", ev.L2OutputIndex, "block", l.BlockNumber)
+			if c.rewindTo == nil || c.rewindTo.Cmp(ev.L2OutputIndex) == 1 {
+				c.rewindTo = ev.L2OutputIndex
+			}
+		}
+	}
+	if incomplete {
+		c.log.Warn("challenge events may be missed, verifying the outputs again")
+		c.resync = true
+	}
+
+	shouldUpdate := len(added) > 0 || len(removed) > 0 || incomplete
+	if c.deadline != 0 && head.Time >= c.deadline {
+		c.log.Info("challenge timeout reached", "deadline", c.deadline, "l1Time", head.Time)
+		c.deadline = 0
+		shouldUpdate = true
+	}
+	c.mu.Unlock()
+
+	if shouldUpdate {
+		c.notifyUpdate()
+	}
+}
+
+// handleDeletedOutputs records the outputs deleted from the L2OutputOracle by the Colosseum. The output root
+// proven faulty is stored, so that the output submitter does not submit it again from the deleted index.
+func (c *Challenger) handleDeletedOutputs(ctx context.Context, logs []types.Log) {
+	provenTxs := make(map[common.Hash]bool)
+	for _, l := range logs {
+		ev, err := c.colosseumContract.ParseProofCompleted(l)
+		if err != nil {
+			continue
+		}
+		provenTxs[l.TxHash] = true
+		if err := c.recordFaultyOutput(ctx, ev.OutputIndex, l.BlockNumber); err != nil {
+			c.log.Error("failed to record faulty output", "outputIndex", ev.OutputIndex, "err", err)
+		}
+	}
+
+	deleted := false
+	for _, l := range logs {
+		ev, err := c.l2ooContract.ParseOutputsDeleted(l)
+		if err != nil {
+			continue
+		}
+		deleted = true
+		count := new(big.Int).Sub(ev.PrevNextOutputIndex, ev.NewNextOutputIndex).Uint64()
+		c.log.Warn("outputs were deleted", "from", ev.NewNextOutputIndex, "to", ev.PrevNextOutputIndex, "provenFaulty", provenTxs[l.TxHash])
+		c.metr.RecordOutputsDeleted(count, provenTxs[l.TxHash])
+	}
+
+	if deleted {
+		select {
+		case c.outputsDeletedCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// recordFaultyOutput stores the output root proven faulty in the given L1 block. The deleted output is read
+// from the state before the L1 block, or from the verification result if the state is not available.
+func (c *Challenger) recordFaultyOutput(ctx context.Context, outputIndex *big.Int, blockNumber uint64) error {
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber - 1)}
+	output, err := c.l2ooContract.GetL2Output(callOpts, outputIndex)
+	if err != nil {
+		record, recordErr := c.store.Output(outputIndex)
+		if recordErr != nil {
+			return recordErr
+		}
+		if record == nil {
+			return fmt.Errorf("deleted output is not available: %w", err)
+		}
+		output.L2BlockNumber = new(big.Int).SetUint64(uint64(record.L2BlockNumber))
+		output.OutputRoot = record.OutputRoot
+	}
+
+	c.log.Error("output was proven faulty", "outputIndex", outputIndex, "l2BlockNumber", output.L2BlockNumber,
+		"outputRoot", common.Hash(output.OutputRoot))
+	return c.store.PutFaultyOutput(output.L2BlockNumber.Uint64(), output.OutputRoot)
+}
+
+// Rescan requests to verify the outputs again from the given output index, without reusing the stored results.
+func (c *Challenger) Rescan(outputIndex *big.Int) error {
+	if outputIndex.Sign() < 0 {
+		return errors.New("negative output index")
+	}
+
+	c.mu.Lock()
+	c.rescanFrom = new(big.Int).Set(outputIndex)
+	c.mu.Unlock()
+
+	c.log.Info("rescan requested", "outputIndex", outputIndex)
+	c.notifyUpdate()
+	return nil
+}
+
+// applyEvents rewinds the checkpoint to verify again the outputs of the reorged L1 blocks,
+// and clears the update requirement.
+func (c *Challenger) applyEvents() error {
+	c.mu.Lock()
+	rewindTo, resync, rescanFrom := c.rewindTo, c.resync, c.rescanFrom
+	c.rewindTo, c.resync, c.rescanFrom = nil, false, nil
+	c.updateRequired = false
+	c.mu.Unlock()
+
+	if rescanFrom != nil && c.checkpoint != nil && c.checkpoint.Cmp(rescanFrom) == 1 {
+		c.log.Info("rescanning outputs", "from", rescanFrom, "to", c.checkpoint)
+		if c.rescanUntil == nil || c.rescanUntil.Cmp(c.checkpoint) == -1 {
+			c.rescanUntil = new(big.Int).Set(c.checkpoint)
+		}
+		if rewindTo == nil || rewindTo.Cmp(rescanFrom) == 1 {
+			rewindTo = rescanFrom
+		}
+	}
+
+	if resync {
+		c.checkpoint = nil
+		return nil
+	}
+	if rewindTo != nil && c.checkpoint != nil && c.checkpoint.Cmp(rewindTo) == 1 {
+		c.log.Info("rewinding checkpoint", "from", c.checkpoint, "to", rewindTo)
+		return c.setCheckpoint(rewindTo)
+	}
+
+	return nil
+}
+
+// setDeadline sets the L1 timestamp at which the challenges have to be updated.
+func (c *Challenger) setDeadline(deadline uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.deadline = deadline
 }
 
 func (c *Challenger) IsChallengeInProgress() (bool, error) {
@@ -97,141 +543,750 @@ func (c *Challenger) OutputAtBlockSafe(blockNumber uint64) (*eth.OutputResponse,
 	return output, nil
 }
 
+// OutputsAtBlocksSafe returns the outputs at the given blocks, requested from the rollup node
+// in chunks of at most sources.MaxOutputsAtBlocks blocks.
+func (c *Challenger) OutputsAtBlocksSafe(blockNumbers []uint64) ([]*eth.OutputResponse, error) {
+	outputs := make([]*eth.OutputResponse, 0, len(blockNumbers))
+	for start := 0; start < len(blockNumbers); start += sources.MaxOutputsAtBlocks {
+		end := start + sources.MaxOutputsAtBlocks
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		chunk, err := c.cfg.RollupClient.OutputsAtBlocks(c.ctx, blockNumbers[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) != end-start {
+			return nil, fmt.Errorf("expected %d outputs, got %d", end-start, len(chunk))
+		}
+		outputs = append(outputs, chunk...)
+	}
+
+	for i, blockNumber := range blockNumbers {
+		if blockNumber == 0 {
+			outputs[i].OutputRoot = eth.Bytes32{}
+		}
+	}
+
+	return outputs, nil
+}
+
 type OutputRange struct {
 	OutputIndex *big.Int
+	OutputRoot  common.Hash
 	StartBlock  uint64
 	EndBlock    uint64
 }
 
+// GetInvalidOutputRange verifies the outputs submitted since the checkpoint, and returns the first
+// queued invalid output which is not challenged yet.
 func (c *Challenger) GetInvalidOutputRange() (*OutputRange, error) {
-	nextOutputIndex, err := c.l2ooContract.NextOutputIndex(c.callOpts)
-	if err != nil {
+	if err := c.scanOutputs(); err != nil {
 		return nil, err
 	}
+
+	return c.nextInvalidOutput()
+}
+
+// scanOutputs verifies the outputs from the checkpoint to the latest one, and queues the invalid outputs.
+// The outputs which are already finalized cannot be challenged, so they are skipped.
+func (c *Challenger) scanOutputs() error {
+	head, err := c.cfg.L1Client.HeaderByNumber(c.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get L1 head: %w", err)
+	}
+	// The outputs are read at the same L1 block, so that they are consistent with the next output index.
+	callOpts := &bind.CallOpts{From: c.cfg.From, BlockNumber: head.Number, Context: c.ctx}
+
+	nextOutputIndex, err := c.l2ooContract.NextOutputIndex(callOpts)
+	if err != nil {
+		return err
+	}
 	if nextOutputIndex.Cmp(common.Big0) == 0 {
 		c.log.Info("the output has not been submitted yet.")
-		return nil, nil
+		return nil
 	}
-	latestOutputIndex := new(big.Int).Sub(nextOutputIndex, common.Big1)
 
-	if c.checkpoint == nil {
-		if latestOutputIndex.Cmp(OutputsPerWeek) == -1 {
-			c.checkpoint = new(big.Int)
-		} else {
-			c.checkpoint = new(big.Int).Sub(latestOutputIndex, OutputsPerWeek)
+	firstIndex, err := c.firstUnfinalizedOutputIndex(callOpts, head.Time, nextOutputIndex)
+	if err != nil {
+		return fmt.Errorf("failed to find the first unfinalized output: %w", err)
+	}
+	if c.checkpoint == nil || c.checkpoint.Cmp(firstIndex) == -1 {
+		if c.checkpoint != nil {
+			c.log.Info("skipping finalized outputs", "from", c.checkpoint, "to", firstIndex)
 		}
+		c.checkpoint = firstIndex
+	}
+	// Outputs deleted by a challenge are submitted again, so they have to be verified again.
+	if c.checkpoint.Cmp(nextOutputIndex) == 1 {
+		c.checkpoint = new(big.Int).Set(nextOutputIndex)
 	}
 
-	for i := c.checkpoint; i.Cmp(latestOutputIndex) != 1; i.Add(i, common.Big1) {
-		output, err := c.l2ooContract.GetL2Output(c.callOpts, i)
+	// The checkpoint is stored after each batch, so that a long catch-up is resumed from where it stopped.
+	for from := new(big.Int).Set(c.checkpoint); from.Cmp(nextOutputIndex) == -1; {
+		to := new(big.Int).Add(from, big.NewInt(outputsBatchSize))
+		if to.Cmp(nextOutputIndex) == 1 {
+			to.Set(nextOutputIndex)
+		}
+
+		outputs, err := c.fetchOutputs(callOpts, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to fetch outputs from %d to %d: %w", from, to, err)
+		}
+		knownRoots, err := c.verifyOutputs(from, outputs)
+		if err != nil {
+			return err
+		}
+
+		for i, output := range outputs {
+			if err := c.handleVerifiedOutput(new(big.Int).Add(from, big.NewInt(int64(i))), output, knownRoots[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := c.setCheckpoint(to); err != nil {
+			return err
+		}
+		from = to
+	}
+
+	return c.setCheckpoint(nextOutputIndex)
+}
+
+// firstUnfinalizedOutputIndex returns the index of the first output which is not finalized at the given
+// L1 timestamp, or the next output index if all the outputs are finalized.
+// The outputs are submitted in order, so the index is found with a binary search.
+func (c *Challenger) firstUnfinalizedOutputIndex(callOpts *bind.CallOpts, now uint64, nextOutputIndex *big.Int) (*big.Int, error) {
+	var searchErr error
+	index := sort.Search(int(nextOutputIndex.Int64()), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		output, err := c.l2ooContract.GetL2Output(callOpts, big.NewInt(int64(i)))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return output.Timestamp.Uint64()+c.finalizationPeriod.Uint64() > now
+	})
+	if searchErr != nil {
+		return nil, searchErr
+	}
+
+	return big.NewInt(int64(index)), nil
+}
+
+// fetchOutputs fetches the outputs in the index range [from, to) at the L1 block of the call options.
+// The outputs are fetched in a single batch if the L1 RPC client is given, or one by one otherwise.
+func (c *Challenger) fetchOutputs(callOpts *bind.CallOpts, from, to *big.Int) ([]bindings.TypesCheckpointOutput, error) {
+	if c.cfg.L1RPCClient == nil {
+		var outputs []bindings.TypesCheckpointOutput
+		for i := new(big.Int).Set(from); i.Cmp(to) == -1; i.Add(i, common.Big1) {
+			output, err := c.l2ooContract.GetL2Output(callOpts, i)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
+		}
+		return outputs, nil
+	}
+
+	l2ooAbi, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	var calls []hexutil.Bytes
+	for i := new(big.Int).Set(from); i.Cmp(to) == -1; i.Add(i, common.Big1) {
+		data, err := l2ooAbi.Pack("getL2Output", i)
 		if err != nil {
 			return nil, err
 		}
+		calls = append(calls, data)
+	}
 
-		knownOutput, err := c.OutputAtBlockSafe(output.L2BlockNumber.Uint64())
+	makeRequest := func(data hexutil.Bytes) (*hexutil.Bytes, rpc.BatchElem) {
+		result := new(hexutil.Bytes)
+		return result, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []any{
+				map[string]any{"from": callOpts.From, "to": c.cfg.L2OutputOracleAddr, "data": data},
+				hexutil.EncodeBig(callOpts.BlockNumber),
+			},
+			Result: result,
+		}
+	}
+	batch := sources.NewIterativeBatchCall[hexutil.Bytes, *hexutil.Bytes](calls, makeRequest, c.cfg.L1RPCClient.BatchCallContext, len(calls))
+	for {
+		if err := batch.Fetch(c.ctx); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	results, err := batch.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]bindings.TypesCheckpointOutput, len(results))
+	for i, result := range results {
+		out, err := l2ooAbi.Unpack("getL2Output", *result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack output: %w", err)
+		}
+		outputs[i] = *abi.ConvertType(out[0], new(bindings.TypesCheckpointOutput)).(*bindings.TypesCheckpointOutput)
+	}
+
+	return outputs, nil
+}
+
+// verifyOutputs returns the output roots computed by the rollup node for the outputs from the given index.
+// The outputs are verified concurrently, bounded by the configured concurrency.
+func (c *Challenger) verifyOutputs(from *big.Int, outputs []bindings.TypesCheckpointOutput) ([]eth.Bytes32, error) {
+	knownRoots := make([]eth.Bytes32, len(outputs))
+
+	var g errgroup.Group
+	g.SetLimit(c.verifyConcurrency)
+	for i := range outputs {
+		i := i
+		g.Go(func() error {
+			outputIndex := new(big.Int).Add(from, big.NewInt(int64(i)))
+			knownRoot, err := c.knownOutputRoot(outputIndex, outputs[i])
+			if err != nil {
+				return fmt.Errorf("failed to verify output %d: %w", outputIndex, err)
+			}
+			knownRoots[i] = knownRoot
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return knownRoots, nil
+}
+
+// handleVerifiedOutput stores the verification result of the output, and queues it if it is invalid.
+func (c *Challenger) handleVerifiedOutput(outputIndex *big.Int, output bindings.TypesCheckpointOutput, knownRoot eth.Bytes32) error {
+	start := output.L2BlockNumber.Uint64() - c.submissionInterval.Uint64()
+	end := output.L2BlockNumber.Uint64()
+	isValid := bytes.Equal(knownRoot[:], output.OutputRoot[:])
+	c.metr.RecordOutputVerified(isValid)
+
+	if err := c.store.PutOutput(&store.OutputRecord{
+		OutputIndex:   (*hexutil.Big)(new(big.Int).Set(outputIndex)),
+		L2BlockNumber: hexutil.Uint64(end),
+		OutputRoot:    output.OutputRoot,
+		ExpectedRoot:  common.Hash(knownRoot),
+		Valid:         isValid,
+	}); err != nil {
+		return fmt.Errorf("failed to store output %d: %w", outputIndex, err)
+	}
+
+	if !isValid {
+		c.log.Info(
+			"found invalid output",
+			"blockNumber", output.L2BlockNumber,
+			"outputIndex", outputIndex,
+			"known", knownRoot,
+			"invalid", common.BytesToHash(output.OutputRoot[:]),
+		)
+		c.queueInvalidOutput(&OutputRange{
+			OutputIndex: new(big.Int).Set(outputIndex),
+			OutputRoot:  output.OutputRoot,
+			StartBlock:  start,
+			EndBlock:    end,
+		})
+	} else {
+		c.log.Info("confirmed that the output is valid",
+			"outputIndex", outputIndex,
+			"start", start,
+			"end", end,
+			"outputRoot", common.BytesToHash(output.OutputRoot[:]),
+		)
+	}
+
+	return nil
+}
+
+// queueInvalidOutput adds the invalid output to the queue, ordered by output index.
+// An output which is already queued is replaced.
+func (c *Challenger) queueInvalidOutput(outputRange *OutputRange) {
+	for i, queued := range c.invalidOutputs {
+		switch queued.OutputIndex.Cmp(outputRange.OutputIndex) {
+		case 0:
+			c.invalidOutputs[i] = outputRange
+			return
+		case 1:
+			c.invalidOutputs = append(c.invalidOutputs[:i], append([]*OutputRange{outputRange}, c.invalidOutputs[i:]...)...)
+			return
+		}
+	}
+	c.invalidOutputs = append(c.invalidOutputs, outputRange)
+}
+
+// nextInvalidOutput returns the first queued invalid output which is not challenged yet.
+// Outputs which are challenged, deleted or replaced on the L2OutputOracle are dropped from the queue.
+func (c *Challenger) nextInvalidOutput() (*OutputRange, error) {
+	nextOutputIndex, err := c.l2ooContract.NextOutputIndex(c.callOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(c.invalidOutputs) > 0 {
+		outputRange := c.invalidOutputs[0]
+
+		isChallengeable, err := c.isChallengeable(outputRange, nextOutputIndex)
 		if err != nil {
 			return nil, err
 		}
+		if isChallengeable {
+			return outputRange, nil
+		}
 
-		start := output.L2BlockNumber.Uint64() - c.submissionInterval.Uint64()
-		end := output.L2BlockNumber.Uint64()
+		c.invalidOutputs = c.invalidOutputs[1:]
+	}
 
-		if !bytes.Equal(knownOutput.OutputRoot[:], output.OutputRoot[:]) {
-			c.checkpoint = i
-			c.log.Info(
-				"found invalid output",
-				"blockNumber", output.L2BlockNumber,
-				"outputIndex", i,
-				"known", knownOutput.OutputRoot,
-				"invalid", common.BytesToHash(output.OutputRoot[:]),
+	return nil, nil
+}
+
+// isChallengeable checks that the queued invalid output is still submitted and not challenged yet.
+func (c *Challenger) isChallengeable(outputRange *OutputRange, nextOutputIndex *big.Int) (bool, error) {
+	record, err := c.store.Challenge(outputRange.OutputIndex)
+	if err != nil {
+		return false, fmt.Errorf("unable to load stored challenge: %w", err)
+	}
+	if record != nil && record.ChallengeId != nil && !record.Closed {
+		return false, nil
+	}
+
+	if outputRange.OutputIndex.Cmp(nextOutputIndex) != -1 {
+		c.log.Info("invalid output was deleted", "outputIndex", outputRange.OutputIndex)
+		return false, nil
+	}
+
+	output, err := c.l2ooContract.GetL2Output(c.callOpts, outputRange.OutputIndex)
+	if err != nil {
+		return false, err
+	}
+	if output.OutputRoot != outputRange.OutputRoot {
+		c.log.Info("invalid output was replaced", "outputIndex", outputRange.OutputIndex)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// knownOutputRoot returns the output root computed by the rollup node for the given output.
+// If the output was already verified before with the same output root, the stored result is reused.
+func (c *Challenger) knownOutputRoot(outputIndex *big.Int, output bindings.TypesCheckpointOutput) (eth.Bytes32, error) {
+	record, err := c.store.Output(outputIndex)
+	if err != nil {
+		return eth.Bytes32{}, fmt.Errorf("failed to load stored output %d: %w", outputIndex, err)
+	}
+	isRescan := c.rescanUntil != nil && outputIndex.Cmp(c.rescanUntil) == -1
+	if record != nil && !isRescan && uint64(record.L2BlockNumber) == output.L2BlockNumber.Uint64() && record.OutputRoot == output.OutputRoot {
+		return eth.Bytes32(record.ExpectedRoot), nil
+	}
+
+	knownOutput, err := c.OutputAtBlockSafe(output.L2BlockNumber.Uint64())
+	if err != nil {
+		return eth.Bytes32{}, err
+	}
+
+	return knownOutput.OutputRoot, nil
+}
+
+// setCheckpoint updates the next output index to be verified and persists it.
+func (c *Challenger) setCheckpoint(outputIndex *big.Int) error {
+	c.checkpoint = new(big.Int).Set(outputIndex)
+	if c.rescanUntil != nil && c.checkpoint.Cmp(c.rescanUntil) != -1 {
+		c.rescanUntil = nil
+	}
+	if err := c.store.SetCheckpoint(c.checkpoint); err != nil {
+		return fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DetermineChallengeTx returns the next transaction to be submitted for the challenges related to this
+// validator, or a createChallenge transaction for the first queued invalid output.
+// The Colosseum only allows a single challenge to be in progress, so the detected invalid outputs are
+// queued and challenged one by one, while the previous challenges are still driven to their end.
+func (c *Challenger) DetermineChallengeTx() (*types.Transaction, error) {
+	if err := c.applyEvents(); err != nil {
+		c.RequireUpdate()
+		return nil, err
+	}
+
+	tx, err := c.determineChallengeTx()
+	if err != nil {
+		c.RequireUpdate()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func (c *Challenger) determineChallengeTx() (*types.Transaction, error) {
+	latestChallengeId, err := c.LatestChallengeId()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get latest challenge id: %w", err)
+	}
+
+	if err := c.syncChallenges(latestChallengeId); err != nil {
+		return nil, err
+	}
+
+	records, err := c.store.Challenges()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load stored challenges: %w", err)
+	}
+
+	for _, record := range records {
+		if record.Closed || record.ChallengeId == nil {
+			continue
+		}
+
+		tx, err := c.determineTxForChallenge(record.ChallengeId.ToInt(), latestChallengeId)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine tx for challenge %d: %w", record.ChallengeId.ToInt(), err)
+		}
+		if tx != nil {
+			return tx, nil
+		}
+	}
+
+	if c.cfg.ChallengerDisabled {
+		return nil, nil
+	}
+
+	// Invalid outputs keep being detected while a challenge is in progress, so that they are queued.
+	outputRange, err := c.GetInvalidOutputRange()
+	if err != nil {
+		return nil, fmt.Errorf("unable to find invalid output: %w", err)
+	}
+
+	for outputRange != nil {
+		decision, err := c.decideChallenge(outputRange)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decide challenge: %w", err)
+		}
+		c.metr.RecordChallengeDecision(string(decision.Kind))
+
+		switch decision.Kind {
+		case chal.DecisionChallenge:
+			c.log.Info("decided to challenge invalid output", "outputIndex", outputRange.OutputIndex, "cost", decision.Cost)
+			return c.CreateChallenge(outputRange)
+		case chal.DecisionWait, chal.DecisionDefer:
+			c.log.Info("invalid outputs are queued",
+				"outputIndex", outputRange.OutputIndex,
+				"queued", len(c.invalidOutputs),
+				"reason", decision.Reason,
 			)
-			return &OutputRange{
-				OutputIndex: i,
-				StartBlock:  start,
-				EndBlock:    end,
-			}, nil
-		} else {
-			c.log.Info("confirmed that the output is valid",
-				"outputIndex", i,
-				"start", start,
-				"end", end,
-				"outputRoot", common.BytesToHash(output.OutputRoot[:]),
+			return nil, nil
+		case chal.DecisionTooLate:
+			// The output cannot be deleted by the challenge anymore, so the next one is challenged instead.
+			c.log.Warn("dropped invalid output", "outputIndex", outputRange.OutputIndex, "reason", decision.Reason)
+			c.invalidOutputs = c.invalidOutputs[1:]
+			outputRange, err = c.nextInvalidOutput()
+			if err != nil {
+				return nil, fmt.Errorf("unable to find invalid output: %w", err)
+			}
+		default:
+			// The output stays queued, since the fees or the balance may change.
+			c.log.Warn("decided not to challenge invalid output",
+				"outputIndex", outputRange.OutputIndex,
+				"decision", decision.Kind,
+				"reason", decision.Reason,
 			)
+			return nil, nil
 		}
 	}
 
-	c.checkpoint = new(big.Int).Add(latestOutputIndex, common.Big1)
-
 	return nil, nil
 }
 
-func (c *Challenger) DetermineChallengeTx() (*types.Transaction, error) {
-	// Check for a challenge in progress.
+// decideChallenge decides whether to challenge the invalid output, by the challenge policy.
+func (c *Challenger) decideChallenge(outputRange *OutputRange) (chal.Decision, error) {
 	isInProgress, err := c.IsChallengeInProgress()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get challenge in progress: %w", err)
+		return chal.Decision{}, fmt.Errorf("unable to get challenge in progress: %w", err)
 	}
 
 	if isInProgress {
-		isRelated, err := c.IsRelatedChallenge()
+		state := chal.ChallengeState{InProgress: true}
+		challenge, err := c.GetChallengeInProgress()
 		if err != nil {
-			return nil, fmt.Errorf("unable to check relationship with challenge: %w", err)
+			return chal.Decision{}, fmt.Errorf("unable to get challenge in progress: %w", err)
 		}
-
-		if !isRelated {
-			c.log.Info("another challenge is in progress")
-			return nil, nil
+		if challenge.OutputIndex.Cmp(outputRange.OutputIndex) == 0 {
+			_, state.EngagedChallenger = challengeParties(challenge.Turn, challenge.Current, challenge.Next)
 		}
+		return c.policy.Decide(state), nil
+	}
 
-		status, err := c.GetStatusInProgress()
+	output, err := c.l2ooContract.GetL2Output(c.callOpts, outputRange.OutputIndex)
+	if err != nil {
+		return chal.Decision{}, err
+	}
+
+	head, err := c.cfg.L1Client.HeaderByNumber(c.ctx, nil)
+	if err != nil {
+		return chal.Decision{}, fmt.Errorf("unable to get L1 head: %w", err)
+	}
+	gasPrice, err := c.cfg.L1Client.SuggestGasTipCap(c.ctx)
+	if err != nil {
+		return chal.Decision{}, fmt.Errorf("unable to get gas tip cap: %w", err)
+	}
+	if head.BaseFee != nil {
+		gasPrice.Add(gasPrice, head.BaseFee)
+	}
+	balance, err := c.cfg.L1Client.BalanceAt(c.ctx, c.cfg.From, nil)
+	if err != nil {
+		return chal.Decision{}, fmt.Errorf("unable to get balance: %w", err)
+	}
+
+	return c.policy.Decide(chal.ChallengeState{
+		Now:         head.Time,
+		FinalizedAt: output.Timestamp.Uint64() + c.finalizationPeriod.Uint64(),
+		GasPrice:    gasPrice,
+		Balance:     balance,
+	}), nil
+}
+
+// syncChallenges starts tracking the latest challenge if it is related to this validator,
+// and closes the tracked challenges which were deleted from the Colosseum.
+func (c *Challenger) syncChallenges(latestChallengeId *big.Int) error {
+	if latestChallengeId.Sign() > 0 {
+		challenge, err := c.colosseumContract.Challenges(c.callOpts, latestChallengeId)
 		if err != nil {
-			return nil, fmt.Errorf("unable to get challenge status: %w", err)
+			return fmt.Errorf("unable to get challenge %d: %w", latestChallengeId, err)
 		}
 
-		if !c.cfg.OutputSubmitterDisabled {
-			switch status {
-			case chal.StatusAsserterTurn:
-				return c.Bisect()
-			case chal.StatusChallengerTimeout:
-				challengeId, err := c.GetChallengeInProgress()
-				if err != nil {
-					return nil, err
+		isRelated := challenge.Current == c.cfg.From || challenge.Next == c.cfg.From
+		if challenge.Turn.Sign() > 0 && isRelated {
+			record, err := c.store.Challenge(challenge.OutputIndex)
+			if err != nil {
+				return fmt.Errorf("unable to load stored challenge: %w", err)
+			}
+
+			if record == nil || record.Closed || record.ChallengeId == nil || record.ChallengeId.ToInt().Cmp(latestChallengeId) != 0 {
+				c.log.Info("tracking challenge", "challengeId", latestChallengeId, "outputIndex", challenge.OutputIndex)
+				if err := c.store.SetChallengeId(challenge.OutputIndex, latestChallengeId); err != nil {
+					return fmt.Errorf("unable to store challenge: %w", err)
 				}
+			}
+		}
+	}
 
-				// TODO(pangssu): Is it necessary to submit challengerTimeout transaction?
-				c.log.Info("challenger timed out", "challengeId", challengeId)
-				return nil, nil
+	records, err := c.store.Challenges()
+	if err != nil {
+		return fmt.Errorf("unable to load stored challenges: %w", err)
+	}
+
+	now := c.lastL1Time()
+	var deadline uint64
+	for _, record := range records {
+		if record.Closed || record.ChallengeId == nil {
+			continue
+		}
+
+		challenge, err := c.colosseumContract.Challenges(c.callOpts, record.ChallengeId.ToInt())
+		if err != nil {
+			return fmt.Errorf("unable to get challenge %d: %w", record.ChallengeId.ToInt(), err)
+		}
+
+		// Closed challenges are deleted from the Colosseum.
+		if challenge.Turn.Sign() == 0 {
+			c.log.Info("challenge closed", "challengeId", record.ChallengeId, "outputIndex", record.OutputIndex)
+			c.metr.RecordChallengeClosed(record.ChallengeId.ToInt())
+			if err := c.store.CloseChallenge(record.OutputIndex.ToInt()); err != nil {
+				return fmt.Errorf("unable to close stored challenge: %w", err)
 			}
+			continue
 		}
 
-		if !c.cfg.ChallengerDisabled {
-			switch status {
-			case chal.StatusChallengerTurn:
-				return c.Bisect()
-			case chal.StatusAsserterTimeout:
-				return c.AsserterTimeout()
-			case chal.StatusProveReady:
-				return c.ProveFault()
+		// The turn times out after timeoutAt, and the asserter timeout turns into
+		// the challenger timeout after another challenge timeout.
+		timeoutAt := challenge.TimeoutAt.Uint64()
+		c.metr.RecordChallengeTurnRemaining(record.ChallengeId.ToInt(), int64(timeoutAt)-int64(now))
+		for _, d := range []uint64{timeoutAt + 1, timeoutAt + c.challengeTimeout.Uint64() + 1} {
+			if d > now && (deadline == 0 || d < deadline) {
+				deadline = d
 			}
 		}
+	}
+	c.setDeadline(deadline)
+
+	return nil
+}
+
+// lastL1Time returns the timestamp of the last watched L1 head.
+func (c *Challenger) lastL1Time() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.l1Time
+}
+
+// determineTxForChallenge returns the transaction to be submitted for the given challenge,
+// or nil if it is not the turn of this validator.
+func (c *Challenger) determineTxForChallenge(challengeId, latestChallengeId *big.Int) (*types.Transaction, error) {
+	challenge, err := c.colosseumContract.Challenges(c.callOpts, challengeId)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Turn.Sign() == 0 {
+		return nil, nil
+	}
 
-		c.log.Warn("unknown challenge status", "status", status)
-	} else if !c.cfg.ChallengerDisabled {
-		outputRange, err := c.GetInvalidOutputRange()
+	// A new challenge can only be created once the previous one is timed out by the challenger,
+	// so the challenges other than the latest one remain in the challenger timeout status.
+	status := chal.StatusChallengerTimeout
+	if challengeId.Cmp(latestChallengeId) == 0 {
+		status, err = c.GetStatusInProgress()
 		if err != nil {
-			return nil, fmt.Errorf("unable to find invalid output: %w", err)
+			return nil, fmt.Errorf("unable to get challenge status: %w", err)
 		}
 
-		if outputRange == nil {
+		if err := c.prefetchProof(status); err != nil {
+			c.log.Warn("failed to prefetch proof", "challengeId", challengeId, "err", err)
+		}
+	}
+
+	c.metr.RecordChallengeStatus(challengeId, status)
+
+	// The next party acts on its turn, and the current party closes the challenge on a timeout.
+	switch status {
+	case chal.StatusChallengerTurn, chal.StatusAsserterTurn, chal.StatusProveReady:
+		if challenge.Next != c.cfg.From {
 			return nil, nil
 		}
+	case chal.StatusChallengerTimeout, chal.StatusAsserterTimeout:
+		if challenge.Current != c.cfg.From {
+			return nil, nil
+		}
+	}
 
-		return c.CreateChallenge(outputRange)
+	if !c.cfg.OutputSubmitterDisabled {
+		switch status {
+		case chal.StatusAsserterTurn:
+			return c.Bisect()
+		case chal.StatusChallengerTimeout:
+			return c.closeTimedOutChallenge(challengeId)
+		}
+	}
+
+	if !c.cfg.ChallengerDisabled {
+		switch status {
+		case chal.StatusChallengerTurn:
+			return c.Bisect()
+		case chal.StatusAsserterTimeout:
+			return c.AsserterTimeout()
+		case chal.StatusProveReady:
+			return c.ProveFaultIfReady()
+		case chal.StatusChallengerTimeout:
+			c.log.Info("challenge timed out", "challengeId", challengeId)
+			return nil, nil
+		}
 	}
 
+	c.log.Warn("unknown challenge status", "status", status)
 	return nil, nil
 }
 
+// closeTimedOutChallenge returns the challengerTimeout transaction closing the challenge as the asserter,
+// or nil if it costs more than the policy allows.
+func (c *Challenger) closeTimedOutChallenge(challengeId *big.Int) (*types.Transaction, error) {
+	tx, err := c.ChallengerTimeout(challengeId)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	decision := c.policy.DecideTimeout(cost)
+	c.metr.RecordChallengeDecision(string(decision.Kind))
+	if decision.Kind != chal.DecisionClose {
+		c.log.Info("challenger timed out, leaving the challenge open", "challengeId", challengeId, "reason", decision.Reason)
+		return nil, nil
+	}
+
+	c.log.Info("challenger timed out, closing the challenge", "challengeId", challengeId, "cost", cost)
+	return tx, nil
+}
+
+// challengeParties returns the asserter and the challenger of a challenge. The challenger acts on the odd turns.
+func challengeParties(turn *big.Int, current, next common.Address) (common.Address, common.Address) {
+	if turn.Bit(0) == 1 {
+		return next, current
+	}
+	return current, next
+}
+
+// ChallengesInProgress returns the on-chain state of the tracked challenges which are not closed yet.
+func (c *Challenger) ChallengesInProgress() ([]*chal.ChallengeInfo, error) {
+	latestChallengeId, err := c.LatestChallengeId()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get latest challenge id: %w", err)
+	}
+
+	records, err := c.store.Challenges()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load stored challenges: %w", err)
+	}
+
+	var infos []*chal.ChallengeInfo
+	for _, record := range records {
+		if record.Closed || record.ChallengeId == nil {
+			continue
+		}
+
+		challengeId := record.ChallengeId.ToInt()
+		challenge, err := c.colosseumContract.Challenges(c.callOpts, challengeId)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get challenge %d: %w", challengeId, err)
+		}
+		if challenge.Turn.Sign() == 0 {
+			continue
+		}
+
+		info := &chal.ChallengeInfo{
+			ChallengeId: record.ChallengeId,
+			OutputIndex: (*hexutil.Big)(challenge.OutputIndex),
+			Turn:        hexutil.Uint64(challenge.Turn.Uint64()),
+			Status:      chal.StatusName(chal.StatusChallengerTimeout),
+			TimeoutAt:   hexutil.Uint64(challenge.TimeoutAt.Uint64()),
+			SegStart:    hexutil.Uint64(challenge.SegStart.Uint64()),
+			SegSize:     hexutil.Uint64(challenge.SegSize.Uint64()),
+		}
+		info.Asserter, info.Challenger = challengeParties(challenge.Turn, challenge.Current, challenge.Next)
+
+		// The segments are only returned for the latest challenge, the others are in the challenger timeout status.
+		if challengeId.Cmp(latestChallengeId) == 0 {
+			status, err := c.GetStatusInProgress()
+			if err != nil {
+				return nil, fmt.Errorf("unable to get challenge status: %w", err)
+			}
+			inProgress, err := c.GetChallengeInProgress()
+			if err != nil {
+				return nil, fmt.Errorf("unable to get challenge in progress: %w", err)
+			}
+			info.Status = chal.StatusName(status)
+			for _, segment := range inProgress.Segments {
+				info.Segments = append(info.Segments, segment)
+			}
+		} else if len(record.Segments) > 0 {
+			info.Segments = record.Segments[len(record.Segments)-1].Hashes
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
 func (c *Challenger) IsRelatedChallenge() (bool, error) {
 	return c.colosseumContract.IsChallengeRelated(c.callOpts, c.cfg.From)
 }
@@ -252,17 +1307,11 @@ func (c *Challenger) BuildSegments(turn, segStart, segSize uint64) (*chal.Segmen
 
 	segments := chal.NewEmptySegments(segStart, segSize, sections.Uint64())
 
-	for i, blockNumber := range segments.BlockNumbers() {
-		if blockNumber == 0 {
-			segments.SetHashValue(0, eth.Bytes32{})
-			continue
-		}
-
-		output, err := c.OutputAtBlockSafe(blockNumber)
-		if err != nil {
-			return nil, fmt.Errorf("unable to get output %d: %w", blockNumber, err)
-		}
-
+	outputs, err := c.OutputsAtBlocksSafe(segments.BlockNumbers())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get outputs of segments: %w", err)
+	}
+	for i, output := range outputs {
 		segments.SetHashValue(i, output.OutputRoot)
 	}
 
@@ -270,12 +1319,12 @@ func (c *Challenger) BuildSegments(turn, segStart, segSize uint64) (*chal.Segmen
 }
 
 func (c *Challenger) selectFaultPosition(segments *chal.Segments) (*big.Int, error) {
-	for i, blockNumber := range segments.BlockNumbers() {
-		output, err := c.OutputAtBlockSafe(blockNumber)
-		if err != nil {
-			return nil, err
-		}
+	outputs, err := c.OutputsAtBlocksSafe(segments.BlockNumbers())
+	if err != nil {
+		return nil, err
+	}
 
+	for i, output := range outputs {
 		if !bytes.Equal(segments.Hashes[i][:], output.OutputRoot[:]) {
 			return big.NewInt(int64(i) - 1), nil
 		}
@@ -297,7 +1346,13 @@ func (c *Challenger) CreateChallenge(outputRange *OutputRange) (*types.Transacti
 		return nil, err
 	}
 
-	return c.colosseumContract.CreateChallenge(c.txOpts, outputRange.OutputIndex, segments.Hashes)
+	tx, err := c.colosseumContract.CreateChallenge(c.txOpts, outputRange.OutputIndex, segments.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	c.setPendingSegments(tx, outputRange.OutputIndex, 1, segments)
+
+	return tx, nil
 }
 
 func (c *Challenger) Bisect() (*types.Transaction, error) {
@@ -320,7 +1375,43 @@ func (c *Challenger) Bisect() (*types.Transaction, error) {
 		return nil, err
 	}
 
-	return c.colosseumContract.Bisect(c.txOpts, position, nextSegments.Hashes)
+	tx, err := c.colosseumContract.Bisect(c.txOpts, position, nextSegments.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	c.setPendingSegments(tx, challenge.OutputIndex, nextTurn, nextSegments)
+
+	return tx, nil
+}
+
+func (c *Challenger) setPendingSegments(tx *types.Transaction, outputIndex *big.Int, turn uint64, segments *chal.Segments) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pendingSegments = &pendingSegments{
+		txHash:      tx.Hash(),
+		outputIndex: outputIndex,
+		turn:        turn,
+		segments:    segments,
+	}
+}
+
+// ConfirmTx stores the segments submitted by the given transaction, once it is included successfully.
+// The segments of the transactions which are never sent, e.g. in watch-only mode, are not stored.
+func (c *Challenger) ConfirmTx(tx *types.Transaction) error {
+	c.mu.Lock()
+	pending := c.pendingSegments
+	if pending == nil || pending.txHash != tx.Hash() {
+		c.mu.Unlock()
+		return nil
+	}
+	c.pendingSegments = nil
+	c.mu.Unlock()
+
+	if err := c.store.AddSegments(pending.outputIndex, pending.turn, pending.segments); err != nil {
+		return fmt.Errorf("unable to store segments: %w", err)
+	}
+	return nil
 }
 
 func (c *Challenger) AsserterTimeout() (*types.Transaction, error) {
@@ -333,9 +1424,125 @@ func (c *Challenger) ChallengerTimeout(challengeId *big.Int) (*types.Transaction
 	return c.colosseumContract.ChallengerTimeout(c.txOpts, challengeId)
 }
 
-func (c *Challenger) ProveFault() (*types.Transaction, error) {
+// ProveFaultIfReady creates a proveFault transaction if the proof of the faulty block is generated.
+// Otherwise, it returns no transaction and waits for the proof job, which triggers the update of the
+// challenges when finished.
+func (c *Challenger) ProveFaultIfReady() (*types.Transaction, error) {
+	if c.proofs == nil {
+		return nil, errors.New("prover is not configured")
+	}
+
+	fault, err := c.findFault()
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed proof job is started again.
+	c.proofs.Request(fault.output.BlockRef)
+	proof, err := c.proofs.Result(fault.output.BlockRef)
+	if errors.Is(err, chal.ErrProofPending) {
+		c.log.Info("waiting for proof", "block", fault.output.BlockRef)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.verifyProof(fault, proof); err != nil {
+		return nil, err
+	}
+
 	c.log.Info("crafting proveFault tx")
+	return c.proveFault(fault, proof)
+}
 
+// verifyProof checks the proof and the output root to be submitted, so that no gas is spent on an invalid proveFault.
+// If the proof is rejected by ZKVerifier, it is requested to the prover again.
+func (c *Challenger) verifyProof(fault *fault, proof *chal.ProofAndPair) error {
+	if err := checkOutput(fault); err != nil {
+		return fmt.Errorf("refused to prove fault: %w", err)
+	}
+
+	err := proof.Check()
+	if err == nil {
+		var ok bool
+		ok, err = c.zkVerifier.Verify(c.callOpts, proof.Proof, proof.Pair)
+		if err != nil {
+			return fmt.Errorf("failed to verify proof of block %s: %w", fault.output.BlockRef, err)
+		}
+		if !ok {
+			err = fmt.Errorf("%w: rejected by zk verifier", chal.ErrInvalidProof)
+		}
+	}
+	if err != nil {
+		c.proofs.Reject(fault.output.BlockRef, err)
+		c.proofs.Request(fault.output.BlockRef)
+		return fmt.Errorf("refused to prove fault of block %s: %w", fault.output.BlockRef, err)
+	}
+
+	c.log.Info("verified proof", "block", fault.output.BlockRef)
+	return nil
+}
+
+// checkOutput checks that the output root is computed from the block of the fault,
+// and that it differs from the output root of the segment to be proven.
+func checkOutput(fault *fault) error {
+	output := fault.output
+	if output.BlockRef.Number != fault.blockNumber {
+		return fmt.Errorf("output is of block %d, expected %d", output.BlockRef.Number, fault.blockNumber)
+	}
+
+	outputRoot, err := rollup.ComputeL2OutputRoot(&bindings.TypesOutputRootProof{
+		Version:                  output.Version,
+		StateRoot:                output.StateRoot,
+		MessagePasserStorageRoot: output.WithdrawalStorageRoot,
+		LatestBlockhash:          output.BlockRef.Hash,
+	})
+	if err != nil {
+		return err
+	}
+	if outputRoot != output.OutputRoot {
+		return fmt.Errorf("output root %s does not match block %s, expected %s", output.OutputRoot, output.BlockRef, outputRoot)
+	}
+
+	if common.Hash(output.OutputRoot) == fault.segment {
+		return fmt.Errorf("output root %s matches the segment", output.OutputRoot)
+	}
+
+	return nil
+}
+
+// prefetchProof requests the proof of the faulty block as soon as the segments of the challenge in progress
+// are narrowed to single blocks, so that the proof is being generated before this validator has to prove the fault.
+func (c *Challenger) prefetchProof(status uint8) error {
+	if c.proofs == nil || c.cfg.ChallengerDisabled || status == chal.StatusNone || status == chal.StatusChallengerTimeout {
+		return nil
+	}
+
+	isAbleToBisect, err := c.colosseumContract.IsAbleToBisect(c.callOpts)
+	if err != nil || isAbleToBisect {
+		return err
+	}
+
+	fault, err := c.findFault()
+	if err != nil {
+		return err
+	}
+
+	c.proofs.Request(fault.output.BlockRef)
+	return nil
+}
+
+// fault is the first block of the challenge in progress whose output root differs from the segments.
+type fault struct {
+	position    *big.Int
+	blockNumber uint64
+	// segment is the output root of the block claimed by the asserter.
+	segment common.Hash
+	output  *eth.OutputResponse
+}
+
+func (c *Challenger) findFault() (*fault, error) {
 	challenge, err := c.colosseumContract.GetChallengeInProgress(c.callOpts)
 	if err != nil {
 		return nil, err
@@ -353,19 +1560,20 @@ func (c *Challenger) ProveFault() (*types.Transaction, error) {
 		return nil, err
 	}
 
-	fetchResult, err := c.fetcher.FetchProofAndPair(output.BlockRef)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"%w: blockNumber: %d, blockHash: %s",
-			err, output.BlockRef.Number, output.BlockRef.Hash,
-		)
-	}
+	return &fault{
+		position:    position,
+		blockNumber: blockNumber,
+		segment:     challenge.Segments[position.Uint64()+1],
+		output:      output,
+	}, nil
+}
 
+func (c *Challenger) proveFault(fault *fault, proof *chal.ProofAndPair) (*types.Transaction, error) {
 	return c.colosseumContract.ProveFault(
 		c.txOpts,
-		position,
-		output.OutputRoot,
-		fetchResult.Proof,
-		fetchResult.Pair,
+		fault.position,
+		fault.output.OutputRoot,
+		proof.Proof,
+		proof.Pair,
 	)
 }
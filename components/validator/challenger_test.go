@@ -0,0 +1,292 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/bindings/bindings"
+	"github.com/wemixkanvas/kanvas/components/node/client"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/rollup"
+	"github.com/wemixkanvas/kanvas/components/node/sources"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	chal "github.com/wemixkanvas/kanvas/components/validator/challenge"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	"github.com/wemixkanvas/kanvas/components/validator/store"
+)
+
+type mockVerifier struct {
+	ok    bool
+	err   error
+	calls int
+}
+
+func (v *mockVerifier) Verify(_ *bind.CallOpts, _ []*big.Int, _ []*big.Int) (bool, error) {
+	v.calls++
+	return v.ok, v.err
+}
+
+// countingFetcher returns the given proof and counts the fetches.
+type countingFetcher struct {
+	mu    sync.Mutex
+	proof *chal.ProofAndPair
+	calls int
+}
+
+func (f *countingFetcher) FetchProofAndPair(context.Context, eth.L2BlockRef) (*chal.ProofAndPair, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	return f.proof, nil
+}
+
+func (f *countingFetcher) numCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+func testProof() *chal.ProofAndPair {
+	return &chal.ProofAndPair{
+		Proof: []*big.Int{big.NewInt(1)},
+		Pair:  []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4)},
+	}
+}
+
+// testFault returns a fault of the block 10 whose output is computed from the block.
+func testFault(t *testing.T) *fault {
+	output := &eth.OutputResponse{
+		BlockRef:              eth.L2BlockRef{Hash: common.Hash{0x0a}, Number: 10},
+		WithdrawalStorageRoot: common.Hash{0x01},
+		StateRoot:             common.Hash{0x02},
+	}
+	outputRoot, err := rollup.ComputeL2OutputRoot(&bindings.TypesOutputRootProof{
+		Version:                  output.Version,
+		StateRoot:                output.StateRoot,
+		MessagePasserStorageRoot: output.WithdrawalStorageRoot,
+		LatestBlockhash:          output.BlockRef.Hash,
+	})
+	require.NoError(t, err)
+	output.OutputRoot = outputRoot
+
+	return &fault{
+		position:    big.NewInt(0),
+		blockNumber: 10,
+		segment:     common.Hash{0xff},
+		output:      output,
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *fault)
+		errMsg string
+	}{
+		{
+			name:   "valid",
+			modify: func(f *fault) {},
+		},
+		{
+			name:   "wrong block",
+			modify: func(f *fault) { f.blockNumber = 11 },
+			errMsg: "output is of block 10, expected 11",
+		},
+		{
+			name:   "output root not matching block",
+			modify: func(f *fault) { f.output.StateRoot = common.Hash{0x03} },
+			errMsg: "does not match block",
+		},
+		{
+			name:   "output root matching segment",
+			modify: func(f *fault) { f.segment = common.Hash(f.output.OutputRoot) },
+			errMsg: "matches the segment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := testFault(t)
+			tt.modify(f)
+			err := checkOutput(f)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier *mockVerifier
+		modify   func(f *fault, proof *chal.ProofAndPair)
+		// valid is whether the proof is submitted.
+		valid bool
+		// verified is whether the verifier is called.
+		verified bool
+		// rejected is whether the proof is rejected and fetched again.
+		rejected bool
+		errIs    error
+	}{
+		{
+			name:     "accepted",
+			verifier: &mockVerifier{ok: true},
+			valid:    true,
+			verified: true,
+		},
+		{
+			name:     "rejected by verifier",
+			verifier: &mockVerifier{ok: false},
+			verified: true,
+			rejected: true,
+			errIs:    chal.ErrInvalidProof,
+		},
+		{
+			name:     "malformed proof",
+			verifier: &mockVerifier{ok: true},
+			modify:   func(f *fault, proof *chal.ProofAndPair) { proof.Pair = proof.Pair[:2] },
+			rejected: true,
+			errIs:    chal.ErrInvalidProof,
+		},
+		{
+			name:     "verifier call failed",
+			verifier: &mockVerifier{err: errors.New("connection refused")},
+			verified: true,
+		},
+		{
+			name:     "invalid output",
+			verifier: &mockVerifier{ok: true},
+			modify:   func(f *fault, proof *chal.ProofAndPair) { f.segment = common.Hash(f.output.OutputRoot) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := testlog.Logger(t, log.LvlInfo)
+			fetcher := &countingFetcher{proof: testProof()}
+			proofs := chal.NewProofManager(context.Background(), l, fetcher, metrics.NoopMetrics, nil)
+			t.Cleanup(proofs.Close)
+			c := &Challenger{log: l, callOpts: &bind.CallOpts{}, proofs: proofs, zkVerifier: tt.verifier}
+
+			f := testFault(t)
+			proof, err := proofs.Wait(context.Background(), f.output.BlockRef)
+			require.NoError(t, err)
+			// The fetched proof is not modified, so that a refetched proof is well-formed.
+			proof = &chal.ProofAndPair{Proof: proof.Proof, Pair: proof.Pair}
+			if tt.modify != nil {
+				tt.modify(f, proof)
+			}
+
+			err = c.verifyProof(f, proof)
+			if tt.verified {
+				require.Equal(t, 1, tt.verifier.calls)
+			} else {
+				require.Zero(t, tt.verifier.calls)
+			}
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			if tt.errIs != nil {
+				require.ErrorIs(t, err, tt.errIs)
+			}
+
+			if tt.rejected {
+				require.Eventually(t, func() bool { return fetcher.numCalls() == 2 }, 5*time.Second, 10*time.Millisecond,
+					"rejected proof must be fetched again")
+			} else {
+				_, err := proofs.Result(f.output.BlockRef)
+				require.NoError(t, err, "proof must stay cached")
+				require.Equal(t, 1, fetcher.numCalls())
+			}
+		})
+	}
+}
+
+// TestConfirmTx checks that the segments are stored only once their transaction is confirmed.
+func TestConfirmTx(t *testing.T) {
+	c := &Challenger{log: testlog.Logger(t, log.LvlInfo), store: store.NewMemoryStore()}
+	outputIndex := big.NewInt(3)
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 1})
+	c.setPendingSegments(tx, outputIndex, 1, chal.NewEmptySegments(0, 10, 2))
+
+	// Another transaction does not store the segments.
+	require.NoError(t, c.ConfirmTx(types.NewTx(&types.DynamicFeeTx{Nonce: 2})))
+	record, err := c.store.Challenge(outputIndex)
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	require.NoError(t, c.ConfirmTx(tx))
+	record, err = c.store.Challenge(outputIndex)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	require.Len(t, record.Segments, 1)
+	require.Nil(t, c.pendingSegments)
+}
+
+// testRollupNode serves the outputs at the requested blocks, with the request limit of the rollup node.
+type testRollupNode struct {
+	requests [][]hexutil.Uint64
+}
+
+func (n *testRollupNode) OutputsAtBlocks(numbers []hexutil.Uint64) ([]*eth.OutputResponse, error) {
+	if len(numbers) > sources.MaxOutputsAtBlocks {
+		return nil, fmt.Errorf("too many blocks requested: %d", len(numbers))
+	}
+	n.requests = append(n.requests, numbers)
+	outputs := make([]*eth.OutputResponse, len(numbers))
+	for i, number := range numbers {
+		outputs[i] = &eth.OutputResponse{
+			BlockRef:   eth.L2BlockRef{Number: uint64(number)},
+			OutputRoot: eth.Bytes32{0xff},
+		}
+	}
+	return outputs, nil
+}
+
+func TestOutputsAtBlocksSafeChunked(t *testing.T) {
+	node := &testRollupNode{}
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("kanvas", node))
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	c := &Challenger{
+		ctx: context.Background(),
+		cfg: Config{RollupClient: sources.NewRollupClient(client.NewBaseRPCClient(rpcClient))},
+	}
+
+	blockNumbers := make([]uint64, 2*sources.MaxOutputsAtBlocks+1)
+	for i := range blockNumbers {
+		blockNumbers[i] = uint64(i)
+	}
+	outputs, err := c.OutputsAtBlocksSafe(blockNumbers)
+	require.NoError(t, err)
+	require.Len(t, node.requests, 3)
+	require.Len(t, outputs, len(blockNumbers))
+	for i, output := range outputs {
+		require.Equal(t, blockNumbers[i], output.BlockRef.Number)
+	}
+	require.Equal(t, eth.Bytes32{}, outputs[0].OutputRoot)
+	require.Equal(t, eth.Bytes32{0xff}, outputs[1].OutputRoot)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/urfave/cli"
 
 	validator "github.com/wemixkanvas/kanvas/components/validator"
+	"github.com/wemixkanvas/kanvas/components/validator/cmd/doc"
 	"github.com/wemixkanvas/kanvas/components/validator/flags"
 	klog "github.com/wemixkanvas/kanvas/utils/service/log"
 )
@@ -29,6 +30,13 @@ func main() {
 	app.Description = "Service for generating and submitting L2 Output checkpoints to the L2OutputOracle contract"
 
 	app.Action = curryMain(Version)
+	app.Commands = []cli.Command{
+		{
+			Name:        "doc",
+			Subcommands: doc.Subcommands,
+		},
+	}
+
 	err := app.Run(os.Args)
 	if err != nil {
 		log.Crit("Application failed", "message", err)
@@ -2,22 +2,33 @@ package validator
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/urfave/cli"
 
+	"github.com/wemixkanvas/kanvas/bindings/bindings"
+	"github.com/wemixkanvas/kanvas/components/node/client"
 	"github.com/wemixkanvas/kanvas/components/node/sources"
 	chal "github.com/wemixkanvas/kanvas/components/validator/challenge"
 	"github.com/wemixkanvas/kanvas/components/validator/flags"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	vrpc "github.com/wemixkanvas/kanvas/components/validator/rpc"
+	"github.com/wemixkanvas/kanvas/components/validator/store"
 	"github.com/wemixkanvas/kanvas/utils"
 	kcrypto "github.com/wemixkanvas/kanvas/utils/service/crypto"
 	klog "github.com/wemixkanvas/kanvas/utils/service/log"
 	kmetrics "github.com/wemixkanvas/kanvas/utils/service/metrics"
 	kpprof "github.com/wemixkanvas/kanvas/utils/service/pprof"
-	krpc "github.com/wemixkanvas/kanvas/utils/service/rpc"
+	ktls "github.com/wemixkanvas/kanvas/utils/service/tls"
 	"github.com/wemixkanvas/kanvas/utils/service/txmgr"
 	ksigner "github.com/wemixkanvas/kanvas/utils/signer/client"
 )
@@ -30,11 +41,17 @@ type Config struct {
 	PollInterval            time.Duration
 	TxManagerConfig         txmgr.Config
 	L1Client                *ethclient.Client
+	L1RPCClient             client.RPC
 	RollupClient            *sources.RollupClient
 	AllowNonFinalized       bool
 	OutputSubmitterDisabled bool
+	MaxCatchUpOutputs       uint64
 	ChallengerDisabled      bool
+	ChallengePolicy         chal.PolicyConfig
+	VerifyConcurrency       int
 	ProofFetcher            ProofFetcher
+	WatchOnly               bool
+	Store                   *store.Store
 	From                    common.Address
 	SignerFn                kcrypto.SignerFn
 }
@@ -85,9 +102,9 @@ type CLIConfig struct {
 	// PrivateKey is the private key used for the validator.
 	PrivateKey string
 
-	RPCConfig krpc.CLIConfig
+	RPCConfig vrpc.CLIConfig
 
-	// ProverGrpc is the URL of prover grpc server.
+	// ProverGrpc is the comma separated URLs of prover grpc servers.
 	ProverGrpc string
 
 	/* Optional Params */
@@ -98,10 +115,52 @@ type CLIConfig struct {
 
 	OutputSubmitterDisabled bool
 
+	// MaxCatchUpOutputs is the maximum number of the pending outputs submitted in a single tick.
+	MaxCatchUpOutputs uint64
+
 	ChallengerDisabled bool
 
+	// ChallengerMinBalance is the balance in ETH to be kept after paying for a whole challenge.
+	ChallengerMinBalance float64
+
+	// ChallengerMaxCost is the maximum estimated cost in ETH of a whole challenge.
+	ChallengerMaxCost float64
+
+	// ChallengerProveFaultGas is the estimated gas of a proveFault transaction.
+	ChallengerProveFaultGas uint64
+
+	// ChallengerTimeoutMaxCost is the maximum cost in ETH of closing a challenge timed out by the challenger.
+	ChallengerTimeoutMaxCost float64
+
+	// VerifyConcurrency is the number of outputs verified concurrently.
+	VerifyConcurrency uint
+
 	FetchingProofTimeout time.Duration
 
+	// ProverGrpcVersion is the version of the prover grpc protocol.
+	ProverGrpcVersion uint
+
+	// ProverStrategy is the strategy to request the proofs to multiple provers.
+	ProverStrategy string
+
+	// ProverHealthCheckInterval is the interval to check the health of the provers.
+	ProverHealthCheckInterval time.Duration
+
+	// ProverTLSEnabled can be set to true to connect to the prover with TLS.
+	ProverTLSEnabled bool
+
+	// ProverTLSConfig contains the paths of the certificates to connect to the prover.
+	ProverTLSConfig ktls.CLIConfig
+
+	// WatchOnly can be set to true to simulate the transactions instead of signing and sending them.
+	WatchOnly bool
+
+	// WatchOnlyAddress is the address to simulate the transactions from in watch-only mode.
+	WatchOnlyAddress string
+
+	// DBPath is the path of the database to persist the challenger state.
+	DBPath string
+
 	LogConfig klog.CLIConfig
 
 	MetricsConfig kmetrics.CLIConfig
@@ -128,6 +187,20 @@ func (c CLIConfig) Check() error {
 	if err := c.SignerConfig.Check(); err != nil {
 		return err
 	}
+	if c.ProverGrpcVersion != 1 && c.ProverGrpcVersion != chal.ProverProtocolVersion {
+		return fmt.Errorf("unsupported prover grpc version: %d", c.ProverGrpcVersion)
+	}
+	if c.ChallengerMinBalance < 0 || c.ChallengerMaxCost < 0 || c.ChallengerTimeoutMaxCost < 0 {
+		return errors.New("challenger min balance and max costs must not be negative")
+	}
+	if err := chal.ProverStrategy(c.ProverStrategy).Check(); err != nil {
+		return err
+	}
+	if c.ProverTLSEnabled {
+		if err := c.ProverTLSConfig.Check(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -148,20 +221,34 @@ func NewCLIConfig(ctx *cli.Context) CLIConfig {
 		PrivateKey:                ctx.GlobalString(flags.PrivateKeyFlag.Name),
 		ProverGrpc:                ctx.GlobalString(flags.ProverGrpcFlag.Name),
 		// Optional Flags
-		AllowNonFinalized:       ctx.GlobalBool(flags.AllowNonFinalizedFlag.Name),
-		OutputSubmitterDisabled: ctx.GlobalBool(flags.OutputSubmitterDisabledFlag.Name),
-		ChallengerDisabled:      ctx.GlobalBool(flags.ChallengerDisabledFlag.Name),
-		FetchingProofTimeout:    ctx.GlobalDuration(flags.FetchingProofTimeoutFlag.Name),
-		RPCConfig:               krpc.ReadCLIConfig(ctx),
-		LogConfig:               klog.ReadCLIConfig(ctx),
-		MetricsConfig:           kmetrics.ReadCLIConfig(ctx),
-		PprofConfig:             kpprof.ReadCLIConfig(ctx),
-		SignerConfig:            ksigner.ReadCLIConfig(ctx),
+		AllowNonFinalized:         ctx.GlobalBool(flags.AllowNonFinalizedFlag.Name),
+		OutputSubmitterDisabled:   ctx.GlobalBool(flags.OutputSubmitterDisabledFlag.Name),
+		MaxCatchUpOutputs:         ctx.GlobalUint64(flags.MaxCatchUpOutputsFlag.Name),
+		ChallengerDisabled:        ctx.GlobalBool(flags.ChallengerDisabledFlag.Name),
+		ChallengerMinBalance:      ctx.GlobalFloat64(flags.ChallengerMinBalanceFlag.Name),
+		ChallengerMaxCost:         ctx.GlobalFloat64(flags.ChallengerMaxCostFlag.Name),
+		ChallengerProveFaultGas:   ctx.GlobalUint64(flags.ChallengerProveFaultGasFlag.Name),
+		ChallengerTimeoutMaxCost:  ctx.GlobalFloat64(flags.ChallengerTimeoutMaxCostFlag.Name),
+		VerifyConcurrency:         ctx.GlobalUint(flags.ChallengerVerifyConcurrencyFlag.Name),
+		FetchingProofTimeout:      ctx.GlobalDuration(flags.FetchingProofTimeoutFlag.Name),
+		ProverGrpcVersion:         ctx.GlobalUint(flags.ProverGrpcVersionFlag.Name),
+		ProverStrategy:            ctx.GlobalString(flags.ProverStrategyFlag.Name),
+		ProverHealthCheckInterval: ctx.GlobalDuration(flags.ProverHealthCheckIntervalFlag.Name),
+		ProverTLSEnabled:          ctx.GlobalBool(flags.ProverTLSEnabledFlag.Name),
+		ProverTLSConfig:           ktls.ReadCLIConfigWithPrefix(ctx, flags.ProverTLSFlagPrefix),
+		WatchOnly:                 ctx.GlobalBool(flags.WatchOnlyFlag.Name),
+		WatchOnlyAddress:          ctx.GlobalString(flags.WatchOnlyAddressFlag.Name),
+		DBPath:                    ctx.GlobalString(flags.DBPathFlag.Name),
+		RPCConfig:                 vrpc.ReadCLIConfig(ctx),
+		LogConfig:                 klog.ReadCLIConfig(ctx),
+		MetricsConfig:             kmetrics.ReadCLIConfig(ctx),
+		PprofConfig:               kpprof.ReadCLIConfig(ctx),
+		SignerConfig:              ksigner.ReadCLIConfig(ctx),
 	}
 }
 
 // NewValidatorConfig creates a validator config with given the CLIConfig
-func NewValidatorConfig(cfg CLIConfig, l log.Logger) (*Config, error) {
+func NewValidatorConfig(cfg CLIConfig, l log.Logger, m metrics.Metricer) (*Config, error) {
 	l2ooAddress, err := utils.ParseAddress(cfg.L2OOAddress)
 	if err != nil {
 		return nil, err
@@ -172,14 +259,26 @@ func NewValidatorConfig(cfg CLIConfig, l log.Logger) (*Config, error) {
 		return nil, err
 	}
 
-	signer, fromAddress, err := kcrypto.SignerFactoryFromConfig(l, cfg.PrivateKey, cfg.Mnemonic, cfg.HDPath, cfg.SignerConfig)
-	if err != nil {
-		return nil, err
+	var signer kcrypto.SignerFactory
+	var fromAddress common.Address
+	if cfg.WatchOnly {
+		signer = func(*big.Int) kcrypto.SignerFn { return watchOnlySignerFn }
+		if cfg.WatchOnlyAddress != "" {
+			fromAddress, err = utils.ParseAddress(cfg.WatchOnlyAddress)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		signer, fromAddress, err = kcrypto.SignerFactoryFromConfig(l, cfg.PrivateKey, cfg.Mnemonic, cfg.HDPath, cfg.SignerConfig)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var fetcher ProofFetcher
 	if len(cfg.ProverGrpc) > 0 {
-		fetcher, err = chal.NewFetcher(cfg.ProverGrpc, cfg.FetchingProofTimeout, l)
+		fetcher, err = newProofFetcher(cfg, l, m)
 		if err != nil {
 			return nil, err
 		}
@@ -187,10 +286,11 @@ func NewValidatorConfig(cfg CLIConfig, l log.Logger) (*Config, error) {
 
 	// Connect to L1 and L2 providers. Perform these last since they are the most expensive.
 	ctx := context.Background()
-	l1Client, err := utils.DialEthClientWithTimeout(ctx, cfg.L1EthRpc)
+	l1RPCClient, err := utils.DialRPCClientWithTimeout(ctx, cfg.L1EthRpc)
 	if err != nil {
 		return nil, err
 	}
+	l1Client := ethclient.NewClient(l1RPCClient)
 
 	rollupClient, err := utils.DialRollupClientWithTimeout(ctx, cfg.RollupRpc)
 	if err != nil {
@@ -202,6 +302,20 @@ func NewValidatorConfig(cfg CLIConfig, l log.Logger) (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.WatchOnly && fromAddress == (common.Address{}) {
+		l2ooContract, err := bindings.NewL2OutputOracleCaller(l2ooAddress, l1Client)
+		if err != nil {
+			return nil, err
+		}
+		fromAddress, err = l2ooContract.VALIDATOR(utils.NewSimpleCallOpts(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get validator address: %w", err)
+		}
+	}
+	if cfg.WatchOnly {
+		l.Info("running in watch-only mode, transactions are simulated", "from", fromAddress)
+	}
+
 	txMgrCfg := txmgr.Config{
 		ResubmissionTimeout:       cfg.ResubmissionTimeout,
 		ReceiptQueryInterval:      time.Second,
@@ -211,20 +325,72 @@ func NewValidatorConfig(cfg CLIConfig, l log.Logger) (*Config, error) {
 		Signer:                    signer(chainID),
 	}
 
+	policyCfg := chal.PolicyConfig{
+		MinBalance:     etherToWei(cfg.ChallengerMinBalance),
+		MaxCost:        etherToWei(cfg.ChallengerMaxCost),
+		ProveFaultGas:  cfg.ChallengerProveFaultGas,
+		MaxTimeoutCost: etherToWei(cfg.ChallengerTimeoutMaxCost),
+	}
+
+	// The store is opened last, so that it is not left open if the providers cannot be reached.
+	s, err := store.Open(cfg.DBPath)
+	if err != nil {
+		return nil, err
+	}
+
 	validatorCfg := &Config{
 		L2OutputOracleAddr:      l2ooAddress,
 		ColosseumAddr:           colosseumAddress,
 		PollInterval:            cfg.PollInterval,
 		TxManagerConfig:         txMgrCfg,
 		L1Client:                l1Client,
+		L1RPCClient:             client.NewBaseRPCClient(l1RPCClient),
 		RollupClient:            rollupClient,
 		AllowNonFinalized:       cfg.AllowNonFinalized,
 		OutputSubmitterDisabled: cfg.OutputSubmitterDisabled,
+		MaxCatchUpOutputs:       cfg.MaxCatchUpOutputs,
 		ChallengerDisabled:      cfg.ChallengerDisabled,
+		ChallengePolicy:         policyCfg,
+		VerifyConcurrency:       int(cfg.VerifyConcurrency),
 		ProofFetcher:            fetcher,
+		WatchOnly:               cfg.WatchOnly,
+		Store:                   s,
 		From:                    fromAddress,
 		SignerFn:                signer(chainID),
 	}
 
 	return validatorCfg, nil
 }
+
+// newProofFetcher connects to the provers with the configured protocol version.
+func newProofFetcher(cfg CLIConfig, l log.Logger, m metrics.Metricer) (ProofFetcher, error) {
+	var urls []string
+	for _, url := range strings.Split(cfg.ProverGrpc, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	proverCfg := chal.ProverConfig{
+		Urls:                urls,
+		Version:             cfg.ProverGrpcVersion,
+		Strategy:            chal.ProverStrategy(cfg.ProverStrategy),
+		HealthCheckInterval: cfg.ProverHealthCheckInterval,
+		Timeout:             cfg.FetchingProofTimeout,
+	}
+	if cfg.ProverTLSEnabled {
+		proverCfg.TLSConfig = cfg.ProverTLSConfig
+	}
+
+	return chal.NewMultiFetcher(proverCfg, l, m)
+}
+
+func etherToWei(ether float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(ether), big.NewFloat(params.Ether)).Int(nil)
+	return wei
+}
+
+// watchOnlySignerFn does not sign the transactions, which are only simulated in watch-only mode.
+func watchOnlySignerFn(_ context.Context, _ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	return tx, nil
+}
@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+)
+
+const (
+	// eventWatcherDepth is the number of recently watched blocks kept to detect L1 reorgs.
+	eventWatcherDepth = 64
+	// maxEventRange is the maximum number of blocks filtered in a single query.
+	maxEventRange = 1000
+)
+
+type EventClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// watchedBlock is a watched L1 block, with the logs emitted in it.
+type watchedBlock struct {
+	id   eth.BlockID
+	logs []types.Log
+}
+
+// EventWatcher filters the logs of the given contracts from new L1 blocks.
+// It keeps track of the recently watched blocks, so that the logs of reorged blocks are reported as removed.
+type EventWatcher struct {
+	log    log.Logger
+	client EventClient
+
+	addresses []common.Address
+	topics    []common.Hash
+
+	// blocks are the recently watched blocks which emitted logs, and the last watched head, ordered by number.
+	blocks []watchedBlock
+}
+
+func NewEventWatcher(l log.Logger, client EventClient, addresses []common.Address, topics []common.Hash) *EventWatcher {
+	return &EventWatcher{
+		log:       l,
+		client:    client,
+		addresses: addresses,
+		topics:    topics,
+	}
+}
+
+// Poll returns the logs emitted since the last poll and the logs of the reorged blocks.
+// The first poll only marks the current head, the past logs are not returned.
+// If the reorg is deeper than the watched blocks, or if old blocks are skipped because the watcher fell behind,
+// incomplete is true and the returned logs are incomplete.
+func (w *EventWatcher) Poll(ctx context.Context) (head *types.Header, added []types.Log, removed []types.Log, incomplete bool, err error) {
+	head, err = w.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("failed to get L1 head: %w", err)
+	}
+
+	if len(w.blocks) == 0 {
+		w.blocks = append(w.blocks, watchedBlock{id: eth.BlockID{Hash: head.Hash(), Number: head.Number.Uint64()}})
+		return head, nil, nil, false, nil
+	}
+
+	last := w.blocks[len(w.blocks)-1].id
+	if head.Hash() == last.Hash {
+		return head, nil, nil, false, nil
+	}
+
+	if head.ParentHash != last.Hash {
+		removed, incomplete, err = w.rewind(ctx, head.Number.Uint64())
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	from := head.Number.Uint64()
+	if len(w.blocks) > 0 {
+		from = w.blocks[len(w.blocks)-1].id.Number + 1
+	}
+	if head.Number.Uint64()+1 > from+maxEventRange {
+		w.log.Warn("too many blocks to watch, skipping old blocks", "from", from, "to", head.Number)
+		from = head.Number.Uint64() + 1 - maxEventRange
+		incomplete = true
+	}
+
+	if from <= head.Number.Uint64() {
+		added, err = w.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   head.Number,
+			Addresses: w.addresses,
+			Topics:    [][]common.Hash{w.topics},
+		})
+		if err != nil {
+			return nil, nil, nil, false, fmt.Errorf("failed to filter logs from %d to %d: %w", from, head.Number, err)
+		}
+	}
+
+	w.append(added, head)
+
+	return head, added, removed, incomplete, nil
+}
+
+// rewind drops the watched blocks which are not canonical anymore, and returns their logs.
+func (w *EventWatcher) rewind(ctx context.Context, headNumber uint64) ([]types.Log, bool, error) {
+	var removed []types.Log
+	for len(w.blocks) > 0 {
+		block := w.blocks[len(w.blocks)-1]
+		if block.id.Number <= headNumber {
+			header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(block.id.Number))
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get L1 block %d: %w", block.id.Number, err)
+			}
+			if header.Hash() == block.id.Hash {
+				return removed, false, nil
+			}
+		}
+
+		w.log.Warn("detected L1 reorg", "block", block.id)
+		removed = append(removed, block.logs...)
+		w.blocks = w.blocks[:len(w.blocks)-1]
+	}
+
+	return removed, true, nil
+}
+
+func (w *EventWatcher) append(logs []types.Log, head *types.Header) {
+	for _, l := range logs {
+		if n := len(w.blocks); n > 0 && w.blocks[n-1].id.Hash == l.BlockHash {
+			w.blocks[n-1].logs = append(w.blocks[n-1].logs, l)
+			continue
+		}
+		w.blocks = append(w.blocks, watchedBlock{
+			id:   eth.BlockID{Hash: l.BlockHash, Number: l.BlockNumber},
+			logs: []types.Log{l},
+		})
+	}
+
+	if n := len(w.blocks); n == 0 || w.blocks[n-1].id.Hash != head.Hash() {
+		w.blocks = append(w.blocks, watchedBlock{id: eth.BlockID{Hash: head.Hash(), Number: head.Number.Uint64()}})
+	}
+
+	if len(w.blocks) > eventWatcherDepth {
+		w.blocks = w.blocks[len(w.blocks)-eventWatcherDepth:]
+	}
+}
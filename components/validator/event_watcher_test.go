@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+)
+
+type fakeEventClient struct {
+	headers []*types.Header
+	logs    map[common.Hash][]types.Log
+}
+
+// extend appends a block emitting the given number of logs on top of the given parent number.
+// The chain is truncated above the parent, so that extending an older block reorgs the chain.
+func (f *fakeEventClient) extend(parent uint64, fork byte, numLogs int) *types.Header {
+	f.headers = f.headers[:parent+1]
+	header := &types.Header{
+		ParentHash: f.headers[parent].Hash(),
+		Number:     new(big.Int).SetUint64(parent + 1),
+		Extra:      []byte{fork},
+	}
+	f.headers = append(f.headers, header)
+
+	for i := 0; i < numLogs; i++ {
+		f.logs[header.Hash()] = append(f.logs[header.Hash()], types.Log{
+			BlockNumber: header.Number.Uint64(),
+			BlockHash:   header.Hash(),
+			Index:       uint(i),
+		})
+	}
+
+	return header
+}
+
+func (f *fakeEventClient) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return f.headers[len(f.headers)-1], nil
+	}
+	if number.Uint64() >= uint64(len(f.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return f.headers[number.Uint64()], nil
+}
+
+func (f *fakeEventClient) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for n := q.FromBlock.Uint64(); n <= q.ToBlock.Uint64(); n++ {
+		logs = append(logs, f.logs[f.headers[n].Hash()]...)
+	}
+	return logs, nil
+}
+
+func newFakeEventClient() *fakeEventClient {
+	return &fakeEventClient{
+		headers: []*types.Header{{Number: new(big.Int)}},
+		logs:    make(map[common.Hash][]types.Log),
+	}
+}
+
+func TestEventWatcher(t *testing.T) {
+	client := newFakeEventClient()
+	w := NewEventWatcher(testlog.Logger(t, log.LvlInfo), client, nil, nil)
+	ctx := context.Background()
+
+	client.extend(0, 0, 1)
+	head, added, removed, incomplete, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), head.Number.Uint64())
+	require.Empty(t, added, "past logs must not be returned on the first poll")
+	require.Empty(t, removed)
+	require.False(t, incomplete)
+
+	client.extend(1, 0, 2)
+	client.extend(2, 0, 0)
+	_, added, removed, _, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, added, 2)
+	require.Empty(t, removed)
+
+	_, added, removed, _, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, added)
+	require.Empty(t, removed)
+
+	// Reorg the blocks 2 and 3 out, the new block 2 emits a single log.
+	client.extend(1, 1, 1)
+	client.extend(2, 1, 0)
+	client.extend(3, 1, 0)
+	head, added, removed, incomplete, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), head.Number.Uint64())
+	require.Len(t, removed, 2)
+	require.Equal(t, uint64(2), removed[0].BlockNumber)
+	require.Len(t, added, 1)
+	require.Equal(t, client.headers[2].Hash(), added[0].BlockHash)
+	require.False(t, incomplete)
+}
+
+func TestEventWatcherDeepReorg(t *testing.T) {
+	client := newFakeEventClient()
+	w := NewEventWatcher(testlog.Logger(t, log.LvlInfo), client, nil, nil)
+	ctx := context.Background()
+
+	client.extend(0, 0, 0)
+	_, _, _, _, err := w.Poll(ctx)
+	require.NoError(t, err)
+
+	for i := uint64(1); i <= eventWatcherDepth+1; i++ {
+		client.extend(i, 0, 1)
+		_, _, _, _, err := w.Poll(ctx)
+		require.NoError(t, err)
+	}
+
+	// Replace the whole chain, deeper than the watched blocks.
+	for i := uint64(0); i <= eventWatcherDepth+2; i++ {
+		client.extend(i, 1, 0)
+	}
+	_, _, removed, incomplete, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.True(t, incomplete)
+	require.Len(t, removed, eventWatcherDepth)
+}
+
+func TestEventWatcherSkippedBlocks(t *testing.T) {
+	client := newFakeEventClient()
+	w := NewEventWatcher(testlog.Logger(t, log.LvlInfo), client, nil, nil)
+	ctx := context.Background()
+
+	client.extend(0, 0, 0)
+	_, _, _, _, err := w.Poll(ctx)
+	require.NoError(t, err)
+
+	// The watcher falls behind by more than the maximum range, so the oldest blocks are skipped.
+	client.extend(1, 0, 1)
+	for i := uint64(2); i <= maxEventRange+2; i++ {
+		client.extend(i, 0, 0)
+	}
+	client.extend(maxEventRange+2, 0, 1)
+	head, added, removed, incomplete, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(maxEventRange+3), head.Number.Uint64())
+	require.True(t, incomplete)
+	require.Empty(t, removed)
+	require.Len(t, added, 1)
+	require.Equal(t, head.Hash(), added[0].BlockHash)
+
+	client.extend(maxEventRange+3, 0, 0)
+	_, _, _, incomplete, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.False(t, incomplete)
+}
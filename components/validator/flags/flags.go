@@ -5,16 +5,22 @@ import (
 
 	"github.com/urfave/cli"
 
+	vrpc "github.com/wemixkanvas/kanvas/components/validator/rpc"
+
 	kservice "github.com/wemixkanvas/kanvas/utils/service"
 	klog "github.com/wemixkanvas/kanvas/utils/service/log"
 	kmetrics "github.com/wemixkanvas/kanvas/utils/service/metrics"
 	kpprof "github.com/wemixkanvas/kanvas/utils/service/pprof"
 	krpc "github.com/wemixkanvas/kanvas/utils/service/rpc"
+	ktls "github.com/wemixkanvas/kanvas/utils/service/tls"
 	ksigner "github.com/wemixkanvas/kanvas/utils/signer/client"
 )
 
 const envVarPrefix = "VALIDATOR"
 
+// ProverTLSFlagPrefix is the prefix of the TLS flags to connect to kanvas-prover.
+const ProverTLSFlagPrefix = "prover-grpc"
+
 var (
 	/* Required Flags */
 
@@ -73,7 +79,7 @@ var (
 	}
 	ProverGrpcFlag = cli.StringFlag{
 		Name:     "prover-grpc-url",
-		Usage:    "gRPC URL for kanvas-prover.",
+		Usage:    "Comma separated gRPC URLs for kanvas-prover, in the order of priority.",
 		Required: true,
 		EnvVar:   kservice.PrefixEnvVar(envVarPrefix, "PROVER_GRPC"),
 	}
@@ -106,17 +112,89 @@ var (
 		Usage:  "Disable l2 output submitter",
 		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "OUTPUT_SUBMITTER_DISABLED"),
 	}
+	MaxCatchUpOutputsFlag = cli.Uint64Flag{
+		Name:   "output-submitter.max-catch-up",
+		Usage:  "Maximum number of the pending outputs submitted one after another in a single tick, when the validator is behind. 1 disables the catch-up.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "OUTPUT_SUBMITTER_MAX_CATCH_UP"),
+		Value:  10,
+	}
 	ChallengerDisabledFlag = cli.BoolFlag{
 		Name:   "challenger.disabled",
 		Usage:  "Disable challenger",
 		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "CHALLENGER_DISABLED"),
 	}
+	ChallengerMinBalanceFlag = cli.Float64Flag{
+		Name:   "challenger.min-balance",
+		Usage:  "Balance in ETH to be kept after paying for a whole challenge. An invalid output is not challenged if the balance is not enough.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "CHALLENGER_MIN_BALANCE"),
+	}
+	ChallengerMaxCostFlag = cli.Float64Flag{
+		Name:   "challenger.max-cost",
+		Usage:  "Maximum estimated cost in ETH of a whole challenge at the current L1 fees. 0 means no maximum.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "CHALLENGER_MAX_COST"),
+	}
+	ChallengerProveFaultGasFlag = cli.Uint64Flag{
+		Name:   "challenger.prove-fault-gas",
+		Usage:  "Estimated gas of a proveFault transaction, used to estimate the cost of a challenge",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "CHALLENGER_PROVE_FAULT_GAS"),
+		Value:  3_000_000,
+	}
+	ChallengerTimeoutMaxCostFlag = cli.Float64Flag{
+		Name:   "challenger.timeout-max-cost",
+		Usage:  "Maximum cost in ETH of closing a challenge timed out by the challenger, as the asserter. 0 disables closing the timed out challenges.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "CHALLENGER_TIMEOUT_MAX_COST"),
+		Value:  0.01,
+	}
+	ChallengerVerifyConcurrencyFlag = cli.UintFlag{
+		Name:   "challenger.verify-concurrency",
+		Usage:  "Number of outputs verified concurrently against the rollup node",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "CHALLENGER_VERIFY_CONCURRENCY"),
+		Value:  8,
+	}
 	FetchingProofTimeoutFlag = cli.DurationFlag{
 		Name:   "fetching-proof-timeout",
 		Usage:  "Duration we will wait to fetching proof",
 		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "FETCHING_PROOF_TIMEOUT"),
 		Value:  time.Hour * 2,
 	}
+	ProverGrpcVersionFlag = cli.UintFlag{
+		Name:   "prover-grpc.version",
+		Usage:  "Version of the kanvas-prover gRPC protocol. 1 generates a proof in a single call, 2 submits a proof job and watches its status.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "PROVER_GRPC_VERSION"),
+		Value:  1,
+	}
+	ProverStrategyFlag = cli.StringFlag{
+		Name:   "prover-grpc.strategy",
+		Usage:  "Strategy to request the proofs to multiple kanvas-prover URLs: primary, race or round-robin",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "PROVER_GRPC_STRATEGY"),
+		Value:  "primary",
+	}
+	ProverHealthCheckIntervalFlag = cli.DurationFlag{
+		Name:   "prover-grpc.health-check-interval",
+		Usage:  "Interval to check the health of the kanvas-prover URLs. 0 disables the health check.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "PROVER_GRPC_HEALTH_CHECK_INTERVAL"),
+		Value:  30 * time.Second,
+	}
+	ProverTLSEnabledFlag = cli.BoolFlag{
+		Name:   "prover-grpc.tls.enabled",
+		Usage:  "Connect to kanvas-prover with TLS, authenticated by the prover-grpc.tls.* certificates",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "PROVER_GRPC_TLS_ENABLED"),
+	}
+	WatchOnlyFlag = cli.BoolFlag{
+		Name:   "watch-only",
+		Usage:  "Run as a monitor, simulating the transactions with eth_call instead of signing and sending them. No signer key is required.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "WATCH_ONLY"),
+	}
+	WatchOnlyAddressFlag = cli.StringFlag{
+		Name:   "watch-only.address",
+		Usage:  "Address to simulate the transactions from in watch-only mode. Defaults to the validator of the L2OutputOracle.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "WATCH_ONLY_ADDRESS"),
+	}
+	DBPathFlag = cli.StringFlag{
+		Name:   "db.path",
+		Usage:  "Path of the LevelDB to persist the challenger state. If empty, the state is kept in memory only.",
+		EnvVar: kservice.PrefixEnvVar(envVarPrefix, "DB_PATH"),
+	}
 )
 
 var requiredFlags = []cli.Flag{
@@ -137,17 +215,32 @@ var optionalFlags = []cli.Flag{
 	PrivateKeyFlag,
 	AllowNonFinalizedFlag,
 	OutputSubmitterDisabledFlag,
+	MaxCatchUpOutputsFlag,
 	ChallengerDisabledFlag,
+	ChallengerMinBalanceFlag,
+	ChallengerMaxCostFlag,
+	ChallengerProveFaultGasFlag,
+	ChallengerTimeoutMaxCostFlag,
+	ChallengerVerifyConcurrencyFlag,
 	FetchingProofTimeoutFlag,
+	ProverGrpcVersionFlag,
+	ProverStrategyFlag,
+	ProverHealthCheckIntervalFlag,
+	ProverTLSEnabledFlag,
+	WatchOnlyFlag,
+	WatchOnlyAddressFlag,
+	DBPathFlag,
 }
 
 func init() {
 	requiredFlags = append(requiredFlags, krpc.CLIFlags(envVarPrefix)...)
 
+	optionalFlags = append(optionalFlags, vrpc.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, klog.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, kmetrics.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, kpprof.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, ksigner.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, ktls.CLIFlagsWithFlagPrefix(envVarPrefix+"_PROVER_GRPC", ProverTLSFlagPrefix)...)
 
 	Flags = append(requiredFlags, optionalFlags...)
 }
@@ -3,63 +3,119 @@ package validator
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	_ "net/http/pprof"
 	"sync"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/wemixkanvas/kanvas/bindings/bindings"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
 	"github.com/wemixkanvas/kanvas/utils"
+	"github.com/wemixkanvas/kanvas/utils/service/txmgr"
 )
 
 var supportedL2OutputVersion = eth.Bytes32{}
 
+const (
+	// maxTrackedSubmissions is the maximum number of the submissions tracked until they are finalized on L1.
+	// The oldest submission is dropped first, e.g. if the L1 client does not support the finalized block.
+	maxTrackedSubmissions = 1000
+	// catchUpGasMargin is the percentage added to the estimated gas of the first submission of a catch-up, to be
+	// the gas limit of the following submissions. Their gas can't be estimated before the previous outputs are
+	// submitted, but they store an output in the next slot of the same array, so they use about as much gas as
+	// the first one. The margin covers the differences of the storage costs between the submissions.
+	catchUpGasMargin = 20
+	// replacementFeeBump is the percentage by which the fees of a reorged submission are bumped when it is
+	// replaced, as the transaction pool rejects a replacement with a bump below 10%.
+	replacementFeeBump = 15
+)
+
 // L2OutputSubmitter is responsible for submitting outputs
 type L2OutputSubmitter struct {
 	wg   sync.WaitGroup
 	done chan struct{}
 	log  log.Logger
+	metr metrics.Metricer
 	cfg  Config
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	l2ooContract *bindings.L2OutputOracle
+	l2ooContract       *bindings.L2OutputOracle
+	submissionInterval *big.Int
+
+	// mu protects the submissions tracked until they are finalized on L1.
+	mu sync.Mutex
+	// submitted is the submissions which are not finalized on L1 yet.
+	submitted []*submittedOutput
+	// replaced is the first reorged submission, whose nonce is replaced by the next submission.
+	replaced *submittedOutput
+}
+
+// submittedOutput is an output submitted by this validator, and the L1 block which included it.
+type submittedOutput struct {
+	l2BlockNumber uint64
+	outputRoot    eth.Bytes32
+	txHash        common.Hash
+	nonce         uint64
+	l1Block       eth.BlockID
 }
 
 // NewL2OutputSubmitter creates a new L2 Output Submitter
-func NewL2OutputSubmitter(ctx context.Context, cfg Config, l log.Logger) (*L2OutputSubmitter, error) {
+func NewL2OutputSubmitter(ctx context.Context, cfg Config, l log.Logger, m metrics.Metricer) (*L2OutputSubmitter, error) {
 	l2ooContract, err := bindings.NewL2OutputOracle(cfg.L2OutputOracleAddr, cfg.L1Client)
 	if err != nil {
 		return nil, err
 	}
 
+	submissionInterval, err := l2ooContract.SUBMISSIONINTERVAL(utils.NewSimpleCallOpts(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission interval: %w", err)
+	}
+
 	return &L2OutputSubmitter{
-		done:         make(chan struct{}),
-		log:          l,
-		cfg:          cfg,
-		ctx:          ctx,
-		l2ooContract: l2ooContract,
+		done:               make(chan struct{}),
+		log:                l,
+		metr:               m,
+		cfg:                cfg,
+		ctx:                ctx,
+		l2ooContract:       l2ooContract,
+		submissionInterval: submissionInterval,
 	}, nil
 }
 
 // FetchNextOutputInfo gets the block number of the next output.
 // It returns: the next block number, if the output should be made, error
 func (l *L2OutputSubmitter) FetchNextOutputInfo(ctx context.Context) (*eth.OutputResponse, bool, error) {
+	outputs, err := l.FetchPendingOutputs(ctx, 1)
+	if err != nil || len(outputs) == 0 {
+		return nil, false, err
+	}
+	return outputs[0], true, nil
+}
+
+// FetchPendingOutputs returns up to max consecutive outputs which are ready to be submitted, starting from
+// the next block number of the L2OutputOracle. More than one output is pending if the validator is behind.
+func (l *L2OutputSubmitter) FetchPendingOutputs(ctx context.Context, max uint64) ([]*eth.OutputResponse, error) {
 	callOpts := utils.NewCallOptsWithSender(ctx, l.cfg.From)
 	nextCheckpointBlock, err := l.l2ooContract.NextBlockNumber(callOpts)
 	if err != nil {
 		l.log.Error("validator unable to get next block number", "err", err)
-		return nil, false, err
+		return nil, err
 	}
 	// Fetch the current L2 heads
 	status, err := l.cfg.RollupClient.SyncStatus(ctx)
 	if err != nil {
 		l.log.Error("validator unable to get sync status", "err", err)
-		return nil, false, err
+		return nil, err
 	}
 	// Use either the finalized or safe head depending on the config. Finalized head is default & safer.
 	var currentBlockNumber *big.Int
@@ -68,26 +124,68 @@ func (l *L2OutputSubmitter) FetchNextOutputInfo(ctx context.Context) (*eth.Outpu
 	} else {
 		currentBlockNumber = new(big.Int).SetUint64(status.FinalizedL2.Number)
 	}
+	if err := l.recordLatestOutput(callOpts, currentBlockNumber.Uint64()); err != nil {
+		l.log.Warn("validator unable to record latest output", "err", err)
+	}
 	// Ensure that we do not submit a block in the future
 	if currentBlockNumber.Cmp(nextCheckpointBlock) < 0 {
 		l.log.Info("validator submission interval has not elapsed", "currentBlockNumber", currentBlockNumber, "nextBlockNumber", nextCheckpointBlock)
-		return nil, false, nil
+		l.metr.RecordPendingOutputs(0)
+		return nil, nil
+	}
+
+	if err := l.checkL1Origins(ctx, status); err != nil {
+		l.log.Error("refusing to submit outputs", "err", err)
+		return nil, err
 	}
 
-	output, err := l.cfg.RollupClient.OutputAtBlock(ctx, nextCheckpointBlock.Uint64())
+	pending := new(big.Int).Sub(currentBlockNumber, nextCheckpointBlock)
+	pending.Div(pending, l.submissionInterval).Add(pending, common.Big1)
+	l.metr.RecordPendingOutputs(pending.Uint64())
+	if pending.Cmp(common.Big1) == 1 {
+		l.log.Info("validator is behind, catching up", "pendingOutputs", pending, "nextBlockNumber", nextCheckpointBlock, "currentBlockNumber", currentBlockNumber)
+	}
+	if pending.Uint64() < max {
+		max = pending.Uint64()
+	}
+
+	var outputs []*eth.OutputResponse
+	blockNumber := new(big.Int).Set(nextCheckpointBlock)
+	for i := uint64(0); i < max; i++ {
+		output, ready, err := l.fetchOutput(ctx, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			break
+		}
+		outputs = append(outputs, output)
+		blockNumber.Add(blockNumber, l.submissionInterval)
+	}
+	return outputs, nil
+}
+
+// fetchOutput fetches the output at the given block, and returns whether it is ready to be submitted.
+func (l *L2OutputSubmitter) fetchOutput(ctx context.Context, blockNumber *big.Int) (*eth.OutputResponse, bool, error) {
+	output, err := l.cfg.RollupClient.OutputAtBlock(ctx, blockNumber.Uint64())
 	if err != nil {
-		l.log.Error("failed to fetch output at block %d: %w", nextCheckpointBlock, err)
+		l.log.Error("failed to fetch output", "blockNumber", blockNumber, "err", err)
 		return nil, false, err
 	}
 	if output.Version != supportedL2OutputVersion {
-		l.log.Error("unsupported l2 output version: %s", output.Version)
+		l.log.Error("unsupported l2 output version", "version", output.Version)
 		return nil, false, errors.New("unsupported l2 output version")
 	}
-	if output.BlockRef.Number != nextCheckpointBlock.Uint64() { // sanity check, e.g. in case of bad RPC caching
-		l.log.Error("invalid blockNumber", "next", nextCheckpointBlock, "output", output.BlockRef.Number)
+	if output.BlockRef.Number != blockNumber.Uint64() { // sanity check, e.g. in case of bad RPC caching
+		l.log.Error("invalid blockNumber", "next", blockNumber, "output", output.BlockRef.Number)
 		return nil, false, errors.New("invalid blockNumber")
 	}
 
+	if err := l.checkFaultyOutput(output); err != nil {
+		l.log.Error("refusing to submit output", "err", err)
+		return nil, false, err
+	}
+
 	// Always submit if it's part of the Finalized L2 chain. Or if allowed, if it's part of the safe L2 chain.
 	if !(output.BlockRef.Number <= output.Status.FinalizedL2.Number || (l.cfg.AllowNonFinalized && output.BlockRef.Number <= output.Status.SafeL2.Number)) {
 		l.log.Debug("not submitting yet, L2 block is not ready for submission",
@@ -100,11 +198,268 @@ func (l *L2OutputSubmitter) FetchNextOutputInfo(ctx context.Context) (*eth.Outpu
 	return output, true, nil
 }
 
+// checkL1Origins returns an error if the L1 origin of the safe L2 head, or the current L1 block of the
+// rollup node, is not canonical on L1 anymore. The safe head was derived from a reorged L1 block then,
+// and the outputs may change once the rollup node handles the reorg.
+func (l *L2OutputSubmitter) checkL1Origins(ctx context.Context, status *eth.SyncStatus) error {
+	for _, id := range []eth.BlockID{status.SafeL2.L1Origin, status.CurrentL1.ID()} {
+		header, err := l.cfg.L1Client.HeaderByNumber(ctx, new(big.Int).SetUint64(id.Number))
+		if err != nil {
+			return fmt.Errorf("failed to get L1 block %d: %w", id.Number, err)
+		}
+		if header.Hash() != id.Hash {
+			return fmt.Errorf("L1 block %s of the rollup node was reorged, canonical hash is %s", id, header.Hash())
+		}
+	}
+	return nil
+}
+
+// checkFaultyOutput returns an error if the output root was proven faulty, e.g. when the outputs are submitted
+// again after a challenge deleted them but the rollup node still computes the same output root.
+func (l *L2OutputSubmitter) checkFaultyOutput(output *eth.OutputResponse) error {
+	if l.cfg.Store == nil {
+		return nil
+	}
+
+	faulty, err := l.cfg.Store.IsFaultyOutput(output.BlockRef.Number, common.Hash(output.OutputRoot))
+	if err != nil {
+		return fmt.Errorf("failed to check faulty output: %w", err)
+	}
+	if faulty {
+		return fmt.Errorf("output root %s of block %d was proven faulty", output.OutputRoot, output.BlockRef.Number)
+	}
+	return nil
+}
+
+// recordLatestOutput records the latest output submitted to the L2OutputOracle, and how far it lags
+// behind the L2 block which is ready to be submitted.
+func (l *L2OutputSubmitter) recordLatestOutput(callOpts *bind.CallOpts, currentBlockNumber uint64) error {
+	nextOutputIndex, err := l.l2ooContract.NextOutputIndex(callOpts)
+	if err != nil {
+		return err
+	}
+	if nextOutputIndex.Sign() == 0 {
+		return nil
+	}
+
+	latestBlockNumber, err := l.l2ooContract.LatestBlockNumber(callOpts)
+	if err != nil {
+		return err
+	}
+
+	l.metr.RecordLatestOutput(nextOutputIndex.Uint64()-1, latestBlockNumber.Uint64())
+	if currentBlockNumber > latestBlockNumber.Uint64() {
+		l.metr.RecordSubmissionLag(currentBlockNumber - latestBlockNumber.Uint64())
+	} else {
+		l.metr.RecordSubmissionLag(0)
+	}
+	return nil
+}
+
+// TrackSubmission tracks the submitted output until the L1 block including it is finalized.
+func (l *L2OutputSubmitter) TrackSubmission(output *eth.OutputResponse, tx *types.Transaction, receipt *types.Receipt) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.submitted) >= maxTrackedSubmissions {
+		l.submitted = l.submitted[1:]
+	}
+	l.submitted = append(l.submitted, &submittedOutput{
+		l2BlockNumber: output.BlockRef.Number,
+		outputRoot:    output.OutputRoot,
+		txHash:        receipt.TxHash,
+		nonce:         tx.Nonce(),
+		l1Block:       eth.BlockID{Hash: receipt.BlockHash, Number: receipt.BlockNumber.Uint64()},
+	})
+}
+
+// CheckSubmissions checks that the tracked submissions are still included in the canonical L1 chain.
+// The next submission replaces the nonce of a reorged submission, so that the output is submitted again
+// with a fresh L1 anchor instead of the reorged transaction being included again with a stale one.
+// The submissions are no longer tracked once the L1 blocks including them are finalized.
+func (l *L2OutputSubmitter) CheckSubmissions(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.submitted) == 0 {
+		return nil
+	}
+
+	// Without the finalized block, the submissions are still checked and stay tracked until they are dropped.
+	finalized, err := l.cfg.L1Client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		l.log.Debug("failed to get finalized L1 block", "err", err)
+		finalized = nil
+	}
+
+	var tracked []*submittedOutput
+	for _, submitted := range l.submitted {
+		reorged, err := l.isReorged(ctx, submitted)
+		if err != nil {
+			return err
+		}
+		if reorged {
+			l.log.Warn("submitted output was reorged out of L1",
+				"l2BlockNumber", submitted.l2BlockNumber,
+				"outputRoot", submitted.outputRoot,
+				"txHash", submitted.txHash,
+				"l1Block", submitted.l1Block)
+			l.metr.RecordOutputReorged()
+			if l.replaced == nil || l.replaced.nonce > submitted.nonce {
+				l.replaced = submitted
+			}
+			continue
+		}
+		if finalized == nil || submitted.l1Block.Number > finalized.Number.Uint64() {
+			tracked = append(tracked, submitted)
+		}
+	}
+	l.submitted = tracked
+
+	return nil
+}
+
+// isReorged returns whether the submission is not included in the canonical L1 chain anymore.
+// The L1 block of a submission included again in another block is updated.
+func (l *L2OutputSubmitter) isReorged(ctx context.Context, submitted *submittedOutput) (bool, error) {
+	header, err := l.cfg.L1Client.HeaderByNumber(ctx, new(big.Int).SetUint64(submitted.l1Block.Number))
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return false, fmt.Errorf("failed to get L1 block %d: %w", submitted.l1Block.Number, err)
+	}
+	if header != nil && header.Hash() == submitted.l1Block.Hash {
+		return false, nil
+	}
+
+	receipt, err := l.cfg.L1Client.TransactionReceipt(ctx, submitted.txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get receipt of %s: %w", submitted.txHash, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return true, nil
+	}
+
+	l.log.Info("submitted output was included again", "txHash", submitted.txHash, "from", submitted.l1Block, "to", receipt.BlockNumber)
+	submitted.l1Block = eth.BlockID{Hash: receipt.BlockHash, Number: receipt.BlockNumber.Uint64()}
+	return false, nil
+}
+
+// nextNonce returns the nonce of the next submission, and the first reorged submission it replaces
+// if that is not included yet.
+func (l *L2OutputSubmitter) nextNonce(ctx context.Context) (uint64, *submittedOutput, error) {
+	l.mu.Lock()
+	replaced := l.replaced
+	l.replaced = nil
+	l.mu.Unlock()
+
+	if replaced != nil {
+		nonce, err := l.cfg.L1Client.NonceAt(ctx, l.cfg.From, nil)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get nonce: %w", err)
+		}
+		if replaced.nonce >= nonce {
+			l.log.Info("replacing reorged submission", "nonce", replaced.nonce, "txHash", replaced.txHash)
+			return replaced.nonce, replaced, nil
+		}
+	}
+
+	nonce, err := l.cfg.L1Client.PendingNonceAt(ctx, l.cfg.From)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	return nonce, nil, nil
+}
+
+// bumpFees sets the fees of the transaction replacing the reorged submission above the fees of the reorged
+// transaction, which may be back in the transaction pool, so that the replacement is not rejected as underpriced.
+func (l *L2OutputSubmitter) bumpFees(ctx context.Context, opts *bind.TransactOpts, replaced *submittedOutput) error {
+	tx, _, err := l.cfg.L1Client.TransactionByHash(ctx, replaced.txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reorged transaction %s: %w", replaced.txHash, err)
+	}
+
+	gasTipCap, err := l.cfg.L1Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get gas tip cap: %w", err)
+	}
+	head, err := l.cfg.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get L1 head: %w", err)
+	}
+	if head.BaseFee == nil {
+		return errors.New("L1 head does not have a base fee")
+	}
+
+	if minTipCap := bumpFee(tx.GasTipCap()); gasTipCap.Cmp(minTipCap) < 0 {
+		gasTipCap = minTipCap
+	}
+	gasFeeCap := txmgr.CalcGasFeeCap(head.BaseFee, gasTipCap)
+	if minFeeCap := bumpFee(tx.GasFeeCap()); gasFeeCap.Cmp(minFeeCap) < 0 {
+		gasFeeCap = minFeeCap
+	}
+	opts.GasTipCap = gasTipCap
+	opts.GasFeeCap = gasFeeCap
+	return nil
+}
+
+// bumpFee returns the fee increased by replacementFeeBump percent.
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+replacementFeeBump))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
 // CreateSubmitL2OutputTx transforms an output response into a signed submit l2 output transaction.
 // It does not send the transaction to the transaction pool.
 func (l *L2OutputSubmitter) CreateSubmitL2OutputTx(ctx context.Context, output *eth.OutputResponse) (*types.Transaction, error) {
-	opts := utils.NewSimpleTxOpts(ctx, l.cfg.From, l.cfg.SignerFn)
+	txs, err := l.CreateSubmitL2OutputTxs(ctx, []*eth.OutputResponse{output})
+	if err != nil {
+		return nil, err
+	}
+	return txs[0], nil
+}
+
+// CreateSubmitL2OutputTxs transforms the consecutive outputs into the transactions with consecutive nonces,
+// so that they can be sent back-to-back. The gas of the following transactions cannot be estimated before
+// the previous outputs are submitted, so their gas limit is the estimated gas of the first transaction
+// increased by catchUpGasMargin.
+func (l *L2OutputSubmitter) CreateSubmitL2OutputTxs(ctx context.Context, outputs []*eth.OutputResponse) ([]*types.Transaction, error) {
+	nonce, replaced, err := l.nextNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*types.Transaction
+	var gasLimit uint64
+	for i, output := range outputs {
+		opts := newTxOpts(ctx, l.cfg)
+		opts.Nonce = new(big.Int).SetUint64(nonce + uint64(i))
+		if i == 0 && replaced != nil {
+			if err := l.bumpFees(ctx, opts, replaced); err != nil {
+				return nil, err
+			}
+		}
+		if gasLimit != 0 {
+			opts.GasLimit = gasLimit
+		}
+
+		tx, err := l.createSubmitL2OutputTx(opts, output)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			gasLimit = tx.Gas() * (100 + catchUpGasMargin) / 100
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
 
+func (l *L2OutputSubmitter) createSubmitL2OutputTx(opts *bind.TransactOpts, output *eth.OutputResponse) (*types.Transaction, error) {
 	tx, err := l.l2ooContract.SubmitL2Output(
 		opts,
 		output.OutputRoot,
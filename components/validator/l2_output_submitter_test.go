@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+)
+
+// testSubmitterL1 serves the L1 RPC methods used to track the submissions.
+type testSubmitterL1 struct {
+	headers   []*types.Header
+	finalized *types.Header
+	txs       map[common.Hash]*types.Transaction
+}
+
+func (l *testSubmitterL1) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (*types.Header, error) {
+	switch number {
+	case rpc.FinalizedBlockNumber:
+		if l.finalized == nil {
+			return nil, errors.New("finalized block not supported")
+		}
+		return l.finalized, nil
+	case rpc.LatestBlockNumber:
+		return l.headers[len(l.headers)-1], nil
+	}
+	if int(number) >= len(l.headers) {
+		return nil, nil
+	}
+	return l.headers[number], nil
+}
+
+func (l *testSubmitterL1) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return nil
+}
+
+func (l *testSubmitterL1) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	return l.txs[hash]
+}
+
+func (l *testSubmitterL1) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func newTestSubmitter(t *testing.T) (*L2OutputSubmitter, *testSubmitterL1) {
+	l1 := &testSubmitterL1{txs: make(map[common.Hash]*types.Transaction)}
+	for i := 0; i < 3; i++ {
+		l1.headers = append(l1.headers, &types.Header{Number: big.NewInt(int64(i)), Difficulty: common.Big0, BaseFee: big.NewInt(10)})
+	}
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("eth", l1))
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	return &L2OutputSubmitter{
+		log:  testlog.Logger(t, log.LvlInfo),
+		metr: metrics.NoopMetrics,
+		cfg:  Config{L1Client: ethclient.NewClient(rpcClient)},
+	}, l1
+}
+
+func testSubmission(t *testing.T, nonce uint64, tipCap, feeCap int64) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(900)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(900),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(tipCap),
+		GasFeeCap: big.NewInt(feeCap),
+		Gas:       100_000,
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func trackSubmission(l *L2OutputSubmitter, tx *types.Transaction, l1Block *types.Header) {
+	output := &eth.OutputResponse{BlockRef: eth.L2BlockRef{Number: tx.Nonce()}}
+	l.TrackSubmission(output, tx, &types.Receipt{
+		TxHash:      tx.Hash(),
+		BlockHash:   l1Block.Hash(),
+		BlockNumber: l1Block.Number,
+	})
+}
+
+// TestCheckSubmissionsWithoutFinalized checks that the submissions are still checked for reorgs,
+// and stay tracked, if the L1 client does not support the finalized block.
+func TestCheckSubmissionsWithoutFinalized(t *testing.T) {
+	l, l1 := newTestSubmitter(t)
+
+	included := testSubmission(t, 1, 1, 10)
+	trackSubmission(l, included, l1.headers[1])
+	reorged := testSubmission(t, 2, 1, 10)
+	trackSubmission(l, reorged, &types.Header{Number: big.NewInt(2), Extra: []byte{0xff}})
+
+	require.NoError(t, l.CheckSubmissions(context.Background()))
+	require.Len(t, l.submitted, 1)
+	require.Equal(t, included.Hash(), l.submitted[0].txHash)
+	require.NotNil(t, l.replaced)
+	require.Equal(t, reorged.Hash(), l.replaced.txHash)
+
+	// The submissions are no longer tracked once finalized.
+	l1.finalized = l1.headers[2]
+	require.NoError(t, l.CheckSubmissions(context.Background()))
+	require.Empty(t, l.submitted)
+}
+
+func TestBumpFees(t *testing.T) {
+	l, l1 := newTestSubmitter(t)
+
+	t.Run("reorged tx in the pool", func(t *testing.T) {
+		tx := testSubmission(t, 1, 10, 100)
+		l1.txs[tx.Hash()] = tx
+
+		opts := &bind.TransactOpts{}
+		require.NoError(t, l.bumpFees(context.Background(), opts, &submittedOutput{txHash: tx.Hash()}))
+		require.Equal(t, big.NewInt(11), opts.GasTipCap)
+		require.Equal(t, big.NewInt(115), opts.GasFeeCap)
+	})
+
+	t.Run("suggested fees above the bump", func(t *testing.T) {
+		tx := testSubmission(t, 1, 0, 10)
+		l1.txs[tx.Hash()] = tx
+
+		opts := &bind.TransactOpts{}
+		require.NoError(t, l.bumpFees(context.Background(), opts, &submittedOutput{txHash: tx.Hash()}))
+		require.Equal(t, big.NewInt(1), opts.GasTipCap)
+		require.Equal(t, big.NewInt(21), opts.GasFeeCap)
+	})
+
+	t.Run("reorged tx dropped", func(t *testing.T) {
+		opts := &bind.TransactOpts{}
+		require.NoError(t, l.bumpFees(context.Background(), opts, &submittedOutput{txHash: common.Hash{0x01}}))
+		require.Nil(t, opts.GasTipCap)
+		require.Nil(t, opts.GasFeeCap)
+	})
+}
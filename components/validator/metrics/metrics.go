@@ -0,0 +1,389 @@
+package metrics
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	kmetrics "github.com/wemixkanvas/kanvas/utils/service/metrics"
+)
+
+const Namespace = "kanvas_validator"
+
+type Metricer interface {
+	RecordInfo(version string)
+	RecordUp()
+
+	RecordLatestOutput(outputIndex uint64, blockNumber uint64)
+	RecordSubmissionLag(blocks uint64)
+	RecordPendingOutputs(count uint64)
+	RecordOutputVerified(valid bool)
+	RecordOutputsDeleted(count uint64, provenFaulty bool)
+	RecordOutputReorged()
+	RecordTxSent(method string, receipt *types.Receipt, err error)
+
+	RecordChallengeStatus(challengeId *big.Int, status uint8)
+	RecordChallengeTurnRemaining(challengeId *big.Int, remaining int64)
+	RecordChallengeClosed(challengeId *big.Int)
+
+	ProofMetricer
+
+	RecordProverFetch(endpoint string, dur time.Duration, err error)
+	RecordProverHealth(endpoint string, healthy bool)
+
+	RecordChallengeDecision(decision string)
+
+	RecordSimulatedTx(method string, err error)
+
+	Document() []kmetrics.DocumentedMetric
+}
+
+// ProofMetricer records the metrics of the proof jobs.
+type ProofMetricer interface {
+	RecordProofRequested()
+	RecordProofFetchFailed()
+	RecordProofSucceeded(dur time.Duration)
+	RecordProofFailed()
+	RecordProofRejected()
+	RecordProofJobs(numActive int)
+}
+
+type Metrics struct {
+	ns       string
+	registry *prometheus.Registry
+	factory  kmetrics.Factory
+
+	Info prometheus.GaugeVec
+	Up   prometheus.Gauge
+
+	LatestOutputIndex prometheus.Gauge
+	LatestOutputBlock prometheus.Gauge
+	SubmissionLag     prometheus.Gauge
+	PendingOutputs    prometheus.Gauge
+	OutputsVerified   prometheus.CounterVec
+	OutputsDeleted    prometheus.CounterVec
+	OutputsReorged    prometheus.Counter
+	Txs               prometheus.CounterVec
+
+	ChallengeStatus        prometheus.GaugeVec
+	ChallengeTurnRemaining prometheus.GaugeVec
+
+	// label by requested, fetch_failed, succeeded, failed, rejected
+	ProofEvs        kmetrics.EventVec
+	ProofDuration   prometheus.Histogram
+	ProofJobsActive prometheus.Gauge
+
+	ProverFetchDuration prometheus.HistogramVec
+	ProverFetchErrors   prometheus.CounterVec
+	ProverHealthy       prometheus.GaugeVec
+
+	// label by challenge, wait, defer, too_late, too_expensive, insufficient_balance
+	ChallengeDecisionEvs kmetrics.EventVec
+
+	SimulatedTxs prometheus.CounterVec
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+func NewMetrics(procName string) *Metrics {
+	if procName == "" {
+		procName = "default"
+	}
+	ns := Namespace + "_" + procName
+
+	registry := kmetrics.NewRegistry()
+	factory := kmetrics.With(registry)
+
+	return &Metrics{
+		ns:       ns,
+		registry: registry,
+		factory:  factory,
+
+		Info: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "info",
+			Help:      "Pseudo-metric tracking version and config info",
+		}, []string{
+			"version",
+		}),
+		Up: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "up",
+			Help:      "1 if the kanvas-validator has finished starting up",
+		}),
+
+		LatestOutputIndex: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "latest_output_index",
+			Help:      "Index of the latest output submitted to the L2OutputOracle.",
+		}),
+		LatestOutputBlock: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "latest_output_block",
+			Help:      "L2 block number of the latest output submitted to the L2OutputOracle.",
+		}),
+		SubmissionLag: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "submission_lag_blocks",
+			Help:      "Number of the L2 blocks between the latest output and the finalized (or safe, if non-finalized outputs are allowed) L2 head.",
+		}),
+		PendingOutputs: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "pending_outputs",
+			Help:      "Number of the outputs which are ready to be submitted, but not submitted yet.",
+		}),
+		OutputsVerified: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "outputs_verified_total",
+			Help:      "Count of the verified outputs, by result.",
+		}, []string{
+			"result",
+		}),
+		OutputsDeleted: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "outputs_deleted_total",
+			Help:      "Count of the outputs deleted from the L2OutputOracle by the Colosseum, by reason.",
+		}, []string{
+			"reason",
+		}),
+		OutputsReorged: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "outputs_reorged_total",
+			Help:      "Count of the submitted outputs which were reorged out of L1 before being finalized.",
+		}),
+		Txs: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "txs_total",
+			Help:      "Count of the sent transactions, by method and outcome.",
+		}, []string{
+			"method",
+			"outcome",
+		}),
+
+		ChallengeStatus: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "challenge_status",
+			Help:      "Status of the challenges related to the validator, by challenge id.",
+		}, []string{
+			"challenge_id",
+		}),
+		ChallengeTurnRemaining: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "challenge_turn_remaining_seconds",
+			Help:      "Seconds remaining until the current turn of the challenge times out, by challenge id. Negative if timed out.",
+		}, []string{
+			"challenge_id",
+		}),
+
+		ProofEvs: kmetrics.NewEventVec(factory, ns, "proof", "Proof", []string{"stage"}),
+		ProofDuration: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "proof_duration_seconds",
+			Help:      "Duration of the proof jobs, including the retries.",
+			Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		}),
+		ProofJobsActive: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "proof_jobs_active",
+			Help:      "Number of proof jobs which are queued or running.",
+		}),
+
+		ProverFetchDuration: *factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "prover_fetch_duration_seconds",
+			Help:      "Duration of the successful proof fetches, by prover endpoint.",
+			Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		}, []string{
+			"endpoint",
+		}),
+		ProverFetchErrors: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "prover_fetch_errors_total",
+			Help:      "Count of the failed proof fetches, by prover endpoint.",
+		}, []string{
+			"endpoint",
+		}),
+		ProverHealthy: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "prover_healthy",
+			Help:      "1 if the prover endpoint passed the last health check, 0 otherwise.",
+		}, []string{
+			"endpoint",
+		}),
+
+		ChallengeDecisionEvs: kmetrics.NewEventVec(factory, ns, "challenge_decision", "Challenge decision", []string{"decision"}),
+
+		SimulatedTxs: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "simulated_txs_total",
+			Help:      "Count of the transactions simulated in watch-only mode, by method and result.",
+		}, []string{
+			"method",
+			"result",
+		}),
+	}
+}
+
+func (m *Metrics) Serve(ctx context.Context, host string, port int) error {
+	return kmetrics.ListenAndServe(ctx, m.registry, host, port)
+}
+
+func (m *Metrics) Document() []kmetrics.DocumentedMetric {
+	return m.factory.Document()
+}
+
+func (m *Metrics) StartBalanceMetrics(ctx context.Context,
+	l log.Logger, client *ethclient.Client, account common.Address) {
+	kmetrics.LaunchBalanceMetrics(ctx, l, m.registry, m.ns, client, account)
+}
+
+// RecordInfo sets a pseudo-metric that contains versioning and
+// config info for the kanvas-validator.
+func (m *Metrics) RecordInfo(version string) {
+	m.Info.WithLabelValues(version).Set(1)
+}
+
+// RecordUp sets the up metric to 1.
+func (m *Metrics) RecordUp() {
+	m.Up.Set(1)
+}
+
+func (m *Metrics) RecordLatestOutput(outputIndex uint64, blockNumber uint64) {
+	m.LatestOutputIndex.Set(float64(outputIndex))
+	m.LatestOutputBlock.Set(float64(blockNumber))
+}
+
+func (m *Metrics) RecordSubmissionLag(blocks uint64) {
+	m.SubmissionLag.Set(float64(blocks))
+}
+
+func (m *Metrics) RecordPendingOutputs(count uint64) {
+	m.PendingOutputs.Set(float64(count))
+}
+
+func (m *Metrics) RecordOutputVerified(valid bool) {
+	result := "valid"
+	if !valid {
+		result = "invalid"
+	}
+	m.OutputsVerified.WithLabelValues(result).Inc()
+}
+
+// RecordOutputsDeleted should be called when the outputs are deleted, either by proving the first of them
+// faulty, or by the asserter timing out.
+func (m *Metrics) RecordOutputsDeleted(count uint64, provenFaulty bool) {
+	reason := "asserter_timeout"
+	if provenFaulty {
+		reason = "proven_faulty"
+	}
+	m.OutputsDeleted.WithLabelValues(reason).Add(float64(count))
+}
+
+func (m *Metrics) RecordOutputReorged() {
+	m.OutputsReorged.Inc()
+}
+
+const (
+	TxOutcomeSuccess  = "success"
+	TxOutcomeReverted = "reverted"
+	TxOutcomeFailed   = "failed"
+)
+
+// RecordTxSent should be called with the result of sending a transaction. A transaction which could not
+// be included is failed, and an included transaction is either succeeded or reverted.
+func (m *Metrics) RecordTxSent(method string, receipt *types.Receipt, err error) {
+	outcome := TxOutcomeSuccess
+	if err != nil || receipt == nil {
+		outcome = TxOutcomeFailed
+	} else if receipt.Status != types.ReceiptStatusSuccessful {
+		outcome = TxOutcomeReverted
+	}
+	m.Txs.WithLabelValues(method, outcome).Inc()
+}
+
+func (m *Metrics) RecordChallengeStatus(challengeId *big.Int, status uint8) {
+	m.ChallengeStatus.WithLabelValues(challengeId.String()).Set(float64(status))
+}
+
+func (m *Metrics) RecordChallengeTurnRemaining(challengeId *big.Int, remaining int64) {
+	m.ChallengeTurnRemaining.WithLabelValues(challengeId.String()).Set(float64(remaining))
+}
+
+// RecordChallengeClosed removes the metrics of the closed challenge.
+func (m *Metrics) RecordChallengeClosed(challengeId *big.Int) {
+	m.ChallengeStatus.DeleteLabelValues(challengeId.String())
+	m.ChallengeTurnRemaining.DeleteLabelValues(challengeId.String())
+}
+
+const (
+	ProofStageRequested   = "requested"
+	ProofStageFetchFailed = "fetch_failed"
+	ProofStageSucceeded   = "succeeded"
+	ProofStageFailed      = "failed"
+	ProofStageRejected    = "rejected"
+)
+
+func (m *Metrics) RecordProofRequested() {
+	m.ProofEvs.Record(ProofStageRequested)
+}
+
+// RecordProofFetchFailed should be called when a single attempt to fetch a proof failed.
+func (m *Metrics) RecordProofFetchFailed() {
+	m.ProofEvs.Record(ProofStageFetchFailed)
+}
+
+func (m *Metrics) RecordProofSucceeded(dur time.Duration) {
+	m.ProofEvs.Record(ProofStageSucceeded)
+	m.ProofDuration.Observe(dur.Seconds())
+}
+
+// RecordProofFailed should be called when a proof job failed after all the attempts.
+func (m *Metrics) RecordProofFailed() {
+	m.ProofEvs.Record(ProofStageFailed)
+}
+
+// RecordProofRejected should be called when a generated proof failed the local verification.
+func (m *Metrics) RecordProofRejected() {
+	m.ProofEvs.Record(ProofStageRejected)
+}
+
+func (m *Metrics) RecordProofJobs(numActive int) {
+	m.ProofJobsActive.Set(float64(numActive))
+}
+
+// RecordProverFetch should be called when a single prover endpoint returned a proof or an error.
+func (m *Metrics) RecordProverFetch(endpoint string, dur time.Duration, err error) {
+	if err != nil {
+		m.ProverFetchErrors.WithLabelValues(endpoint).Inc()
+		return
+	}
+	m.ProverFetchDuration.WithLabelValues(endpoint).Observe(dur.Seconds())
+}
+
+func (m *Metrics) RecordProverHealth(endpoint string, healthy bool) {
+	var v float64
+	if healthy {
+		v = 1
+	}
+	m.ProverHealthy.WithLabelValues(endpoint).Set(v)
+}
+
+// RecordChallengeDecision should be called when it is decided whether to challenge an invalid output.
+func (m *Metrics) RecordChallengeDecision(decision string) {
+	m.ChallengeDecisionEvs.Record(decision)
+}
+
+// RecordSimulatedTx should be called when a transaction is simulated instead of being sent in watch-only mode.
+func (m *Metrics) RecordSimulatedTx(method string, err error) {
+	result := "success"
+	if err != nil {
+		result = "reverted"
+	}
+	m.SimulatedTxs.WithLabelValues(method, result).Inc()
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	kmetrics "github.com/wemixkanvas/kanvas/utils/service/metrics"
+)
+
+type noopMetrics struct{}
+
+var NoopMetrics Metricer = new(noopMetrics)
+
+func (*noopMetrics) Document() []kmetrics.DocumentedMetric { return nil }
+
+func (*noopMetrics) RecordInfo(version string) {}
+func (*noopMetrics) RecordUp()                 {}
+
+func (*noopMetrics) RecordLatestOutput(uint64, uint64)            {}
+func (*noopMetrics) RecordSubmissionLag(uint64)                   {}
+func (*noopMetrics) RecordPendingOutputs(uint64)                  {}
+func (*noopMetrics) RecordOutputVerified(bool)                    {}
+func (*noopMetrics) RecordOutputsDeleted(uint64, bool)            {}
+func (*noopMetrics) RecordOutputReorged()                         {}
+func (*noopMetrics) RecordTxSent(string, *types.Receipt, error)   {}
+func (*noopMetrics) RecordChallengeStatus(*big.Int, uint8)        {}
+func (*noopMetrics) RecordChallengeTurnRemaining(*big.Int, int64) {}
+func (*noopMetrics) RecordChallengeClosed(*big.Int)               {}
+
+func (*noopMetrics) RecordProofRequested()              {}
+func (*noopMetrics) RecordProofFetchFailed()            {}
+func (*noopMetrics) RecordProofSucceeded(time.Duration) {}
+func (*noopMetrics) RecordProofFailed()                 {}
+func (*noopMetrics) RecordProofRejected()               {}
+func (*noopMetrics) RecordProofJobs(int)                {}
+
+func (*noopMetrics) RecordProverFetch(string, time.Duration, error) {}
+func (*noopMetrics) RecordProverHealth(string, bool)                {}
+
+func (*noopMetrics) RecordChallengeDecision(string) {}
+
+func (*noopMetrics) RecordSimulatedTx(string, error) {}
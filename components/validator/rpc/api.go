@@ -0,0 +1,152 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/wemixkanvas/kanvas/components/validator/challenge"
+	"github.com/wemixkanvas/kanvas/components/validator/store"
+)
+
+const (
+	NamespaceRPC   = "validator"
+	NamespaceAdmin = "admin"
+)
+
+var errMissingOutputIndex = errors.New("output index is required")
+
+type validatorStore interface {
+	Checkpoint() (*big.Int, error)
+	Output(outputIndex *big.Int) (*store.OutputRecord, error)
+	InvalidOutputs() ([]*store.OutputRecord, error)
+	Challenge(outputIndex *big.Int) (*store.ChallengeRecord, error)
+	Challenges() ([]*store.ChallengeRecord, error)
+	Txs() ([]*store.TxRecord, error)
+}
+
+type challengerClient interface {
+	ProofJobs() []challenge.ProofJob
+	ChallengesInProgress() ([]*challenge.ChallengeInfo, error)
+}
+
+type validatorAPI struct {
+	s          validatorStore
+	challenger challengerClient
+}
+
+func NewValidatorAPI(s validatorStore, challenger challengerClient) *validatorAPI {
+	return &validatorAPI{
+		s:          s,
+		challenger: challenger,
+	}
+}
+
+// Checkpoint returns the next output index to be verified by the challenger.
+func (a *validatorAPI) Checkpoint(_ context.Context) (*hexutil.Big, error) {
+	checkpoint, err := a.s.Checkpoint()
+	if err != nil || checkpoint == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(checkpoint), nil
+}
+
+func (a *validatorAPI) VerifiedOutput(_ context.Context, outputIndex *hexutil.Big) (*store.OutputRecord, error) {
+	if outputIndex == nil {
+		return nil, errMissingOutputIndex
+	}
+	return a.s.Output(outputIndex.ToInt())
+}
+
+// LastVerifiedOutput returns the output verified right before the checkpoint.
+func (a *validatorAPI) LastVerifiedOutput(_ context.Context) (*store.OutputRecord, error) {
+	checkpoint, err := a.s.Checkpoint()
+	if err != nil || checkpoint == nil || checkpoint.Sign() == 0 {
+		return nil, err
+	}
+	return a.s.Output(new(big.Int).Sub(checkpoint, common.Big1))
+}
+
+func (a *validatorAPI) InvalidOutputs(_ context.Context) ([]*store.OutputRecord, error) {
+	return a.s.InvalidOutputs()
+}
+
+func (a *validatorAPI) Challenge(_ context.Context, outputIndex *hexutil.Big) (*store.ChallengeRecord, error) {
+	if outputIndex == nil {
+		return nil, errMissingOutputIndex
+	}
+	return a.s.Challenge(outputIndex.ToInt())
+}
+
+func (a *validatorAPI) Challenges(_ context.Context) ([]*store.ChallengeRecord, error) {
+	return a.s.Challenges()
+}
+
+// ChallengesInProgress returns the on-chain state of the tracked challenges which are not closed yet.
+func (a *validatorAPI) ChallengesInProgress(_ context.Context) ([]*challenge.ChallengeInfo, error) {
+	return a.challenger.ChallengesInProgress()
+}
+
+func (a *validatorAPI) Transactions(_ context.Context) ([]*store.TxRecord, error) {
+	return a.s.Txs()
+}
+
+// ProofJobs returns the state of the proof jobs requested to the prover.
+func (a *validatorAPI) ProofJobs(_ context.Context) ([]challenge.ProofJob, error) {
+	return a.challenger.ProofJobs(), nil
+}
+
+type validatorAdmin interface {
+	PauseOutputSubmitter() error
+	ResumeOutputSubmitter() error
+	PauseChallenger() error
+	ResumeChallenger() error
+	Rescan(outputIndex *big.Int) error
+	ChallengerTimeout(ctx context.Context, challengeId *big.Int) (common.Hash, error)
+}
+
+type adminAPI struct {
+	v validatorAdmin
+}
+
+func NewAdminAPI(v validatorAdmin) *adminAPI {
+	return &adminAPI{
+		v: v,
+	}
+}
+
+func (a *adminAPI) PauseOutputSubmitter(_ context.Context) error {
+	return a.v.PauseOutputSubmitter()
+}
+
+func (a *adminAPI) ResumeOutputSubmitter(_ context.Context) error {
+	return a.v.ResumeOutputSubmitter()
+}
+
+// PauseChallenger stops sending the challenge transactions. The challenges are still tracked while paused.
+func (a *adminAPI) PauseChallenger(_ context.Context) error {
+	return a.v.PauseChallenger()
+}
+
+func (a *adminAPI) ResumeChallenger(_ context.Context) error {
+	return a.v.ResumeChallenger()
+}
+
+// Rescan verifies the outputs again from the given output index, without reusing the stored results.
+func (a *adminAPI) Rescan(_ context.Context, outputIndex *hexutil.Big) error {
+	if outputIndex == nil {
+		return errMissingOutputIndex
+	}
+	return a.v.Rescan(outputIndex.ToInt())
+}
+
+// ChallengerTimeout sends a challengerTimeout transaction for the given challenge, and returns its hash.
+func (a *adminAPI) ChallengerTimeout(ctx context.Context, challengeId *hexutil.Big) (common.Hash, error) {
+	if challengeId == nil {
+		return common.Hash{}, errors.New("challenge id is required")
+	}
+	return a.v.ChallengerTimeout(ctx, challengeId.ToInt())
+}
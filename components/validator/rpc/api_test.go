@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type testAdmin struct {
+	validatorAdmin
+	rescanFrom *big.Int
+}
+
+func (a *testAdmin) Rescan(outputIndex *big.Int) error {
+	a.rescanFrom = outputIndex
+	return nil
+}
+
+func (a *testAdmin) ChallengerTimeout(_ context.Context, challengeId *big.Int) (common.Hash, error) {
+	return common.BigToHash(challengeId), nil
+}
+
+// TestAdminAPIMissingArgs checks that the optional arguments omitted by the caller are rejected.
+func TestAdminAPIMissingArgs(t *testing.T) {
+	admin := &testAdmin{}
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName(NamespaceAdmin, NewAdminAPI(admin)))
+	t.Cleanup(srv.Stop)
+	client := rpc.DialInProc(srv)
+	t.Cleanup(client.Close)
+
+	ctx := context.Background()
+	require.ErrorContains(t, client.CallContext(ctx, nil, "admin_rescan"), "output index is required")
+	require.ErrorContains(t, client.CallContext(ctx, nil, "admin_rescan", nil), "output index is required")
+	var hash common.Hash
+	require.ErrorContains(t, client.CallContext(ctx, &hash, "admin_challengerTimeout"), "challenge id is required")
+
+	require.NoError(t, client.CallContext(ctx, nil, "admin_rescan", "0x2"))
+	require.Equal(t, big.NewInt(2), admin.rescanFrom)
+	require.NoError(t, client.CallContext(ctx, &hash, "admin_challengerTimeout", "0x3"))
+	require.Equal(t, common.BigToHash(big.NewInt(3)), hash)
+}
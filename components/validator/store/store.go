@@ -0,0 +1,355 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+
+	chal "github.com/wemixkanvas/kanvas/components/validator/challenge"
+)
+
+const (
+	dbCache   = 16 // MiB
+	dbHandles = 16
+)
+
+var (
+	checkpointKey   = []byte("checkpoint")
+	outputPrefix    = []byte("output-")
+	challengePrefix = []byte("challenge-")
+	txPrefix        = []byte("tx-")
+	faultyPrefix    = []byte("faulty-")
+)
+
+// OutputRecord is the result of verifying a single output submitted to the L2OutputOracle.
+type OutputRecord struct {
+	OutputIndex   *hexutil.Big   `json:"outputIndex"`
+	L2BlockNumber hexutil.Uint64 `json:"l2BlockNumber"`
+	OutputRoot    common.Hash    `json:"outputRoot"`
+	ExpectedRoot  common.Hash    `json:"expectedRoot"`
+	Valid         bool           `json:"valid"`
+}
+
+// SegmentsRecord holds the segments that were submitted for a single turn of a challenge.
+type SegmentsRecord struct {
+	Turn   hexutil.Uint64 `json:"turn"`
+	Start  hexutil.Uint64 `json:"start"`
+	Size   hexutil.Uint64 `json:"size"`
+	Hashes []common.Hash  `json:"hashes"`
+}
+
+// ChallengeRecord tracks a challenge on the Colosseum contract related to this validator.
+// It is keyed by the index of the challenged output.
+type ChallengeRecord struct {
+	OutputIndex *hexutil.Big      `json:"outputIndex"`
+	ChallengeId *hexutil.Big      `json:"challengeId,omitempty"`
+	Segments    []*SegmentsRecord `json:"segments"`
+	Closed      bool              `json:"closed"`
+}
+
+// TxRecord is a transaction sent by the validator.
+type TxRecord struct {
+	Hash        common.Hash    `json:"hash"`
+	To          common.Address `json:"to"`
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Method      string         `json:"method"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Status      hexutil.Uint64 `json:"status"`
+	Err         string         `json:"err,omitempty"`
+}
+
+// Store persists the state of the validator, so that a restarted validator can resume
+// from where it stopped, and exposes the history of outputs, challenges and transactions.
+type Store struct {
+	mu sync.Mutex
+	db ethdb.KeyValueStore
+}
+
+// Open opens a LevelDB backed store at the given path.
+// If the path is empty, the state is kept in memory only.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return NewMemoryStore(), nil
+	}
+
+	db, err := leveldb.New(path, dbCache, dbHandles, "validator/db/", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open validator db at %s: %w", path, err)
+	}
+
+	return NewStore(db), nil
+}
+
+// NewMemoryStore creates a store which is not persisted to disk.
+func NewMemoryStore() *Store {
+	return NewStore(memorydb.New())
+}
+
+func NewStore(db ethdb.KeyValueStore) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Checkpoint returns the next output index to be verified, or nil if it has not been set yet.
+func (s *Store) Checkpoint() (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.get(checkpointKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+func (s *Store) SetCheckpoint(outputIndex *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Put(checkpointKey, outputIndex.Bytes())
+}
+
+// Output returns the verification result of the given output index, or nil if it is not verified yet.
+func (s *Store) Output(outputIndex *big.Int) (*OutputRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var record *OutputRecord
+	if err := s.getJSON(indexKey(outputPrefix, outputIndex), &record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (s *Store) PutOutput(record *OutputRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.putJSON(indexKey(outputPrefix, record.OutputIndex.ToInt()), record)
+}
+
+// InvalidOutputs returns all the outputs detected as invalid, ordered by output index.
+func (s *Store) InvalidOutputs() ([]*OutputRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []*OutputRecord
+	err := s.iterate(outputPrefix, func(value []byte) error {
+		var record OutputRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		if !record.Valid {
+			records = append(records, &record)
+		}
+		return nil
+	})
+
+	return records, err
+}
+
+// Challenge returns the challenge related to the given output index, or nil if there is none.
+func (s *Store) Challenge(outputIndex *big.Int) (*ChallengeRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.challenge(outputIndex)
+}
+
+func (s *Store) PutChallenge(record *ChallengeRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.putJSON(indexKey(challengePrefix, record.OutputIndex.ToInt()), record)
+}
+
+// Challenges returns all the challenges related to this validator, ordered by output index.
+func (s *Store) Challenges() ([]*ChallengeRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []*ChallengeRecord
+	err := s.iterate(challengePrefix, func(value []byte) error {
+		var record ChallengeRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		records = append(records, &record)
+		return nil
+	})
+
+	return records, err
+}
+
+// SetChallengeId links the challenge id assigned by the Colosseum to the challenge of the given output index.
+// The output can be challenged again after a previous challenge is closed, so the record is reopened.
+func (s *Store) SetChallengeId(outputIndex *big.Int, challengeId *big.Int) error {
+	return s.updateChallenge(outputIndex, func(record *ChallengeRecord) {
+		record.ChallengeId = (*hexutil.Big)(new(big.Int).Set(challengeId))
+		record.Closed = false
+	})
+}
+
+// AddSegments records the segments built for the given turn of the challenge.
+// Segments previously recorded for the same turn are replaced.
+func (s *Store) AddSegments(outputIndex *big.Int, turn uint64, segments *chal.Segments) error {
+	hashes := make([]common.Hash, len(segments.Hashes))
+	for i, hash := range segments.Hashes {
+		hashes[i] = hash
+	}
+	segRecord := &SegmentsRecord{
+		Turn:   hexutil.Uint64(turn),
+		Start:  hexutil.Uint64(segments.Start),
+		Size:   hexutil.Uint64(segments.Size),
+		Hashes: hashes,
+	}
+
+	return s.updateChallenge(outputIndex, func(record *ChallengeRecord) {
+		for i, prev := range record.Segments {
+			if prev.Turn == segRecord.Turn {
+				record.Segments[i] = segRecord
+				return
+			}
+		}
+		record.Segments = append(record.Segments, segRecord)
+	})
+}
+
+// CloseChallenge marks the challenge of the given output index as closed.
+func (s *Store) CloseChallenge(outputIndex *big.Int) error {
+	return s.updateChallenge(outputIndex, func(record *ChallengeRecord) {
+		record.Closed = true
+	})
+}
+
+// PutFaultyOutput records an output root of the given L2 block which was proven faulty on the Colosseum.
+func (s *Store) PutFaultyOutput(l2BlockNumber uint64, outputRoot common.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Put(faultyKey(l2BlockNumber, outputRoot), []byte{1})
+}
+
+// IsFaultyOutput returns whether the output root of the given L2 block was proven faulty.
+func (s *Store) IsFaultyOutput(l2BlockNumber uint64, outputRoot common.Hash) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Has(faultyKey(l2BlockNumber, outputRoot))
+}
+
+// PutTx records a transaction sent by the validator.
+func (s *Store) PutTx(record *TxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := binary.BigEndian.AppendUint64(common.CopyBytes(txPrefix), uint64(record.Nonce))
+	return s.putJSON(append(key, record.Hash.Bytes()...), record)
+}
+
+// Txs returns all the transactions sent by the validator, ordered by nonce.
+func (s *Store) Txs() ([]*TxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []*TxRecord
+	err := s.iterate(txPrefix, func(value []byte) error {
+		var record TxRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		records = append(records, &record)
+		return nil
+	})
+
+	return records, err
+}
+
+func (s *Store) updateChallenge(outputIndex *big.Int, update func(record *ChallengeRecord)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.challenge(outputIndex)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		record = &ChallengeRecord{OutputIndex: (*hexutil.Big)(new(big.Int).Set(outputIndex))}
+	}
+	update(record)
+
+	return s.putJSON(indexKey(challengePrefix, outputIndex), record)
+}
+
+func (s *Store) challenge(outputIndex *big.Int) (*ChallengeRecord, error) {
+	var record *ChallengeRecord
+	if err := s.getJSON(indexKey(challengePrefix, outputIndex), &record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// get returns nil without an error if the key does not exist.
+func (s *Store) get(key []byte) ([]byte, error) {
+	if ok, err := s.db.Has(key); err != nil || !ok {
+		return nil, err
+	}
+
+	return s.db.Get(key)
+}
+
+// getJSON leaves v untouched if the key does not exist.
+func (s *Store) getJSON(key []byte, v any) error {
+	data, err := s.get(key)
+	if err != nil || data == nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (s *Store) putJSON(key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Put(key, data)
+}
+
+func (s *Store) iterate(prefix []byte, fn func(value []byte) error) error {
+	it := s.db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if err := fn(it.Value()); err != nil {
+			return err
+		}
+	}
+
+	return it.Error()
+}
+
+// indexKey encodes the index as a fixed size big endian number, so that records are iterated in order.
+func indexKey(prefix []byte, index *big.Int) []byte {
+	return append(common.CopyBytes(prefix), common.BigToHash(index).Bytes()...)
+}
+
+func faultyKey(l2BlockNumber uint64, outputRoot common.Hash) []byte {
+	key := binary.BigEndian.AppendUint64(common.CopyBytes(faultyPrefix), l2BlockNumber)
+	return append(key, outputRoot.Bytes()...)
+}
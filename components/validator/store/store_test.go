@@ -0,0 +1,127 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	chal "github.com/wemixkanvas/kanvas/components/validator/challenge"
+)
+
+func TestCheckpoint(t *testing.T) {
+	s := NewMemoryStore()
+
+	checkpoint, err := s.Checkpoint()
+	require.NoError(t, err)
+	require.Nil(t, checkpoint)
+
+	require.NoError(t, s.SetCheckpoint(big.NewInt(0)))
+	checkpoint, err = s.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), checkpoint.Int64())
+
+	require.NoError(t, s.SetCheckpoint(big.NewInt(300)))
+	checkpoint, err = s.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, int64(300), checkpoint.Int64())
+}
+
+func TestOutputs(t *testing.T) {
+	s := NewMemoryStore()
+
+	for i := int64(0); i < 5; i++ {
+		require.NoError(t, s.PutOutput(&OutputRecord{
+			OutputIndex:   (*hexutil.Big)(big.NewInt(i)),
+			L2BlockNumber: hexutil.Uint64(i * 10),
+			OutputRoot:    common.Hash{byte(i)},
+			ExpectedRoot:  common.Hash{0xff},
+			Valid:         i%2 == 0,
+		}))
+	}
+	// The checkpoint key must not be iterated as an output or a challenge.
+	require.NoError(t, s.SetCheckpoint(big.NewInt(5)))
+
+	record, err := s.Output(big.NewInt(3))
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(30), record.L2BlockNumber)
+	require.False(t, record.Valid)
+
+	record, err = s.Output(big.NewInt(5))
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	invalid, err := s.InvalidOutputs()
+	require.NoError(t, err)
+	require.Len(t, invalid, 2)
+	require.Equal(t, int64(1), invalid[0].OutputIndex.ToInt().Int64())
+	require.Equal(t, int64(3), invalid[1].OutputIndex.ToInt().Int64())
+}
+
+func TestChallenges(t *testing.T) {
+	s := NewMemoryStore()
+	outputIndex := big.NewInt(7)
+
+	segments := chal.NewSegments(0, 10, []chal.Hash{{1}, {2}, {3}})
+	require.NoError(t, s.AddSegments(outputIndex, 1, segments))
+	require.NoError(t, s.SetChallengeId(outputIndex, big.NewInt(1)))
+
+	next := chal.NewSegments(5, 5, []chal.Hash{{2}, {4}, {3}})
+	require.NoError(t, s.AddSegments(outputIndex, 2, next))
+	// Rebuilding the segments of the same turn replaces them.
+	require.NoError(t, s.AddSegments(outputIndex, 2, next))
+
+	record, err := s.Challenge(outputIndex)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), record.ChallengeId.ToInt().Int64())
+	require.Len(t, record.Segments, 2)
+	require.Equal(t, hexutil.Uint64(5), record.Segments[1].Start)
+	require.Equal(t, common.Hash{4}, record.Segments[1].Hashes[1])
+	require.False(t, record.Closed)
+
+	require.NoError(t, s.CloseChallenge(outputIndex))
+	records, err := s.Challenges()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.True(t, records[0].Closed)
+
+	// A new challenge for the same output reopens the record.
+	require.NoError(t, s.SetChallengeId(outputIndex, big.NewInt(2)))
+	record, err = s.Challenge(outputIndex)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), record.ChallengeId.ToInt().Int64())
+	require.False(t, record.Closed)
+}
+
+func TestTxs(t *testing.T) {
+	s := NewMemoryStore()
+
+	require.NoError(t, s.PutTx(&TxRecord{Hash: common.Hash{1}, Nonce: 2, Method: "bisect"}))
+	require.NoError(t, s.PutTx(&TxRecord{Hash: common.Hash{2}, Nonce: 1, Method: "createChallenge"}))
+
+	txs, err := s.Txs()
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, "createChallenge", txs[0].Method)
+	require.Equal(t, "bisect", txs[1].Method)
+}
+
+func TestFaultyOutputs(t *testing.T) {
+	s := NewMemoryStore()
+
+	require.NoError(t, s.PutFaultyOutput(10, common.Hash{1}))
+
+	faulty, err := s.IsFaultyOutput(10, common.Hash{1})
+	require.NoError(t, err)
+	require.True(t, faulty)
+
+	faulty, err = s.IsFaultyOutput(10, common.Hash{2})
+	require.NoError(t, err)
+	require.False(t, faulty)
+
+	faulty, err = s.IsFaultyOutput(20, common.Hash{1})
+	require.NoError(t, err)
+	require.False(t, faulty)
+}
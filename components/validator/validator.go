@@ -2,21 +2,40 @@ package validator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/wemixkanvas/kanvas/bindings/bindings"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	vrpc "github.com/wemixkanvas/kanvas/components/validator/rpc"
+	"github.com/wemixkanvas/kanvas/components/validator/store"
 	"github.com/wemixkanvas/kanvas/utils"
 	"github.com/wemixkanvas/kanvas/utils/monitoring"
 	klog "github.com/wemixkanvas/kanvas/utils/service/log"
+	kmetrics "github.com/wemixkanvas/kanvas/utils/service/metrics"
 	krpc "github.com/wemixkanvas/kanvas/utils/service/rpc"
 	"github.com/wemixkanvas/kanvas/utils/service/txmgr"
 )
 
+// watchOnlyGasLimit is the gas limit of the transactions which are only simulated.
+const watchOnlyGasLimit = 10_000_000
+
 // Main is the entrypoint into the Validator. This method executes the
 // service and blocks until the service exits.
 func Main(version string, cliCtx *cli.Context) error {
@@ -28,7 +47,8 @@ func Main(version string, cliCtx *cli.Context) error {
 	l := klog.NewLogger(cliCfg.LogConfig)
 	l.Info("initializing Validator")
 
-	validatorCfg, err := NewValidatorConfig(cliCfg, l)
+	m := metrics.NewMetrics("default")
+	validatorCfg, err := NewValidatorConfig(cliCfg, l, m)
 	if err != nil {
 		return err
 	}
@@ -36,18 +56,32 @@ func Main(version string, cliCtx *cli.Context) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	monitoring.MaybeStartPprof(ctx, cliCfg.PprofConfig, l)
-	monitoring.MaybeStartMetrics(ctx, cliCfg.MetricsConfig, l, validatorCfg.L1Client, validatorCfg.From)
-	server, err := monitoring.StartRPC(cliCfg.RPCConfig, version, krpc.WithLogger(l))
+	validator, err := NewValidator(ctx, *validatorCfg, l, m)
 	if err != nil {
 		return err
 	}
-	defer server.Stop()
 
-	validator, err := NewValidator(ctx, *validatorCfg, l)
+	monitoring.MaybeStartPprof(ctx, cliCfg.PprofConfig, l)
+	startMetrics(ctx, cliCfg.MetricsConfig, l, m, validatorCfg.L1Client, validatorCfg.From)
+
+	apis := []rpc.API{{
+		Namespace: vrpc.NamespaceRPC,
+		Service:   vrpc.NewValidatorAPI(validator.store, validator.challenger),
+	}}
+	if cliCfg.RPCConfig.EnableAdmin {
+		apis = append(apis, rpc.API{
+			Namespace: vrpc.NamespaceAdmin,
+			Service:   vrpc.NewAdminAPI(validator),
+		})
+	}
+	server, err := monitoring.StartRPC(cliCfg.RPCConfig.ToServiceCLIConfig(), version, krpc.WithLogger(l), krpc.WithAPIs(apis))
 	if err != nil {
 		return err
 	}
+	defer server.Stop()
+
+	m.RecordInfo(version)
+	m.RecordUp()
 
 	validator.Start()
 	<-utils.WaitInterrupt()
@@ -56,6 +90,22 @@ func Main(version string, cliCtx *cli.Context) error {
 	return nil
 }
 
+// startMetrics serves the validator metrics, including the balance of the validator account, if enabled.
+// It replaces monitoring.MaybeStartMetrics, which only exports the balance.
+func startMetrics(ctx context.Context, cfg kmetrics.CLIConfig, l log.Logger, m *metrics.Metrics, client *ethclient.Client, account common.Address) {
+	if !cfg.Enabled {
+		return
+	}
+
+	l.Info("starting metrics server", "addr", cfg.ListenAddr, "port", cfg.ListenPort)
+	go func() {
+		if err := m.Serve(ctx, cfg.ListenAddr, cfg.ListenPort); err != nil {
+			l.Error("failed to start metrics server", "err", err)
+		}
+	}()
+	m.StartBalanceMetrics(ctx, l, client, account)
+}
+
 type Validator struct {
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -63,21 +113,36 @@ type Validator struct {
 	l          log.Logger
 	l2os       *L2OutputSubmitter
 	challenger *Challenger
+	store      *store.Store
 	txMgr      txmgr.TxManager
+	metr       metrics.Metricer
+
+	// simulated is the hash of the data of the last simulated transaction by method, in watch-only mode.
+	simulated map[string]common.Hash
+
+	// outputSubmitterPaused and challengerPaused are set by the admin API to stop sending the transactions.
+	outputSubmitterPaused atomic.Bool
+	challengerPaused      atomic.Bool
+	// txMu serializes creating and sending the transactions, which are also sent by the admin API.
+	txMu sync.Mutex
 
 	wg sync.WaitGroup
 }
 
-func NewValidator(parentCtx context.Context, cfg Config, l log.Logger) (*Validator, error) {
+func NewValidator(parentCtx context.Context, cfg Config, l log.Logger, m metrics.Metricer) (*Validator, error) {
 	ctx, cancel := context.WithCancel(parentCtx)
 
-	l2OutputSubmitter, err := NewL2OutputSubmitter(ctx, cfg, l)
+	if cfg.Store == nil {
+		cfg.Store = store.NewMemoryStore()
+	}
+
+	l2OutputSubmitter, err := NewL2OutputSubmitter(ctx, cfg, l, m)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
 
-	challenger, err := NewChallenger(ctx, cfg, l)
+	challenger, err := NewChallenger(ctx, cfg, l, m)
 	if err != nil {
 		cancel()
 		return nil, err
@@ -90,24 +155,32 @@ func NewValidator(parentCtx context.Context, cfg Config, l log.Logger) (*Validat
 		l:          l,
 		l2os:       l2OutputSubmitter,
 		challenger: challenger,
+		store:      cfg.Store,
 		txMgr:      txmgr.NewSimpleTxManager("validator", l, cfg.TxManagerConfig, cfg.L1Client),
+		metr:       m,
+		simulated:  make(map[string]common.Hash),
 	}, nil
 }
 
 func (v *Validator) Start() {
 	v.l.Info("starting Validator")
+	v.challenger.Start()
 	v.wg.Add(1)
 	go v.loop()
 }
 
 func (v *Validator) Stop() {
+	v.cancel()
+	v.wg.Wait()
+	v.challenger.Stop()
 	if v.cfg.ProofFetcher != nil {
 		if err := v.cfg.ProofFetcher.Close(); err != nil {
-			v.l.Error("cannot close grpc connection: %w", err)
+			v.l.Error("cannot close grpc connection", "err", err)
 		}
 	}
-	v.cancel()
-	v.wg.Wait()
+	if err := v.store.Close(); err != nil {
+		v.l.Error("cannot close validator db", "err", err)
+	}
 }
 
 func (v *Validator) loop() {
@@ -119,12 +192,31 @@ func (v *Validator) loop() {
 	for {
 		select {
 		case <-ticker.C:
-			if !v.cfg.OutputSubmitterDisabled {
+			if !v.cfg.OutputSubmitterDisabled && !v.outputSubmitterPaused.Load() {
 				if err := v.submitL2Output(); err != nil {
 					v.l.Error("failed to submit l2 output", "err", err)
 				}
 			}
 
+			// Retry if the last update of the challenges failed.
+			if v.challenger.IsUpdateRequired() && !v.challengerPaused.Load() {
+				if err := v.submitChallengeTx(); err != nil {
+					v.l.Error("failed to submit challenge tx", "err", err)
+				}
+			}
+		case <-v.challenger.OutputsDeletedCh():
+			// The deleted outputs are submitted again from the first deleted index.
+			if !v.cfg.OutputSubmitterDisabled && !v.outputSubmitterPaused.Load() {
+				if err := v.submitL2Output(); err != nil {
+					v.l.Error("failed to submit l2 output", "err", err)
+				}
+			}
+		case <-v.challenger.UpdateCh():
+			// The update is retried on the next tick after the challenger is resumed.
+			if v.challengerPaused.Load() {
+				v.challenger.RequireUpdate()
+				continue
+			}
 			if err := v.submitChallengeTx(); err != nil {
 				v.l.Error("failed to submit challenge tx", "err", err)
 			}
@@ -135,29 +227,56 @@ func (v *Validator) loop() {
 }
 
 func (v *Validator) submitL2Output() error {
+	v.txMu.Lock()
+	defer v.txMu.Unlock()
+
 	cCtx, cancel := context.WithTimeout(v.ctx, 3*time.Minute)
 	defer cancel()
 
-	output, shouldSubmit, err := v.l2os.FetchNextOutputInfo(cCtx)
+	// The outputs reorged out of L1 are submitted again below, as they are pending again.
+	if err := v.l2os.CheckSubmissions(cCtx); err != nil {
+		v.l.Warn("failed to check submitted outputs", "err", err)
+	}
+
+	outputs, err := v.l2os.FetchPendingOutputs(cCtx, v.maxCatchUpOutputs())
 	if err != nil {
 		return fmt.Errorf("failed to fetch next output: %w", err)
 	}
-	if !shouldSubmit {
+	if len(outputs) == 0 {
 		return nil
 	}
 
-	tx, err := v.l2os.CreateSubmitL2OutputTx(cCtx, output)
+	txs, err := v.l2os.CreateSubmitL2OutputTxs(cCtx, outputs)
 	if err != nil {
 		return fmt.Errorf("failed to create submit l2 output transaction: %w", err)
 	}
-	if err := v.SendTransaction(cCtx, tx); err != nil {
+	// Each transaction is sent with its own timeout, so that a long catch-up is not canceled.
+	receipts, err := v.sendTransactions(v.ctx, txs)
+	for i, receipt := range receipts {
+		if receipt != nil && receipt.Status == types.ReceiptStatusSuccessful {
+			v.l2os.TrackSubmission(outputs[i], txs[i], receipt)
+		}
+	}
+	if err != nil {
 		return fmt.Errorf("failed to send submit l2 output transaction: %w", err)
 	}
 
 	return nil
 }
 
+// maxCatchUpOutputs returns the maximum number of the outputs submitted at once. The transactions
+// following the first one cannot be simulated in watch-only mode, so a single output is submitted then.
+func (v *Validator) maxCatchUpOutputs() uint64 {
+	if v.cfg.WatchOnly || v.cfg.MaxCatchUpOutputs == 0 {
+		return 1
+	}
+	return v.cfg.MaxCatchUpOutputs
+}
+
 func (v *Validator) submitChallengeTx() error {
+	v.txMu.Lock()
+	defer v.txMu.Unlock()
+
 	tx, err := v.challenger.DetermineChallengeTx()
 	if err != nil {
 		return fmt.Errorf("failed to determine challenge transaction to submit: %w", err)
@@ -167,9 +286,16 @@ func (v *Validator) submitChallengeTx() error {
 		return nil
 	}
 
-	if err := v.SendTransaction(v.ctx, tx); err != nil {
+	receipt, err := v.sendTransaction(v.ctx, tx)
+	if err != nil {
+		v.challenger.RequireUpdate()
 		return fmt.Errorf("failed to send challenge transaction: %w", err)
 	}
+	if receipt != nil && receipt.Status == types.ReceiptStatusSuccessful {
+		if err := v.challenger.ConfirmTx(tx); err != nil {
+			return fmt.Errorf("failed to confirm challenge transaction: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -177,19 +303,193 @@ func (v *Validator) submitChallengeTx() error {
 // SendTransaction sends a transaction through the transaction manager which handles automatic
 // price bumping.
 // It also hardcodes a timeout of 100s.
+// In watch-only mode, the transaction is simulated instead.
 func (v *Validator) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := v.sendTransaction(ctx, tx)
+	return err
+}
+
+// sendTransactions sends the transactions with consecutive nonces, without waiting for the receipt of each one
+// before sending the next, so that they are included back-to-back. The transaction manager still bumps the gas
+// price of each one. The receipts of the included transactions are returned, even on error.
+func (v *Validator) sendTransactions(ctx context.Context, txs []*types.Transaction) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(txs))
+
+	var g errgroup.Group
+	for i, tx := range txs {
+		i, tx := i, tx
+		g.Go(func() error {
+			receipt, err := v.sendTransaction(ctx, tx)
+			receipts[i] = receipt
+			return err
+		})
+	}
+	return receipts, g.Wait()
+}
+
+// sendTransaction sends the transaction and returns its receipt, or nil in watch-only mode.
+func (v *Validator) sendTransaction(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	if v.cfg.WatchOnly {
+		v.simulateTransaction(ctx, tx)
+		return nil, nil
+	}
+
 	// Wait until one of our submitted transactions confirms. If no
 	// receipt is received it's likely our gas price was too low.
 	cCtx, cancel := context.WithTimeout(ctx, 100*time.Second)
 	defer cancel()
 	v.l.Info("validator sending transaction", "tx", tx.Hash())
 	receipt, err := v.txMgr.Send(cCtx, tx)
+	v.recordTx(tx, receipt, err)
 	if err != nil {
 		v.l.Error("validator unable to publish tx", "err", err)
-		return err
+		return nil, err
 	}
 
 	// The transaction was successfully submitted
 	v.l.Info("validator tx successfully published", "tx_hash", receipt.TxHash)
+	return receipt, nil
+}
+
+func (v *Validator) PauseOutputSubmitter() error {
+	if v.outputSubmitterPaused.Swap(true) {
+		return errors.New("output submitter is already paused")
+	}
+	v.l.Info("output submitter paused")
+	return nil
+}
+
+func (v *Validator) ResumeOutputSubmitter() error {
+	if v.cfg.OutputSubmitterDisabled {
+		return errors.New("output submitter is disabled")
+	}
+	if !v.outputSubmitterPaused.Swap(false) {
+		return errors.New("output submitter is not paused")
+	}
+	v.l.Info("output submitter resumed")
 	return nil
 }
+
+// PauseChallenger stops sending the challenge transactions, while the challenges are still tracked.
+func (v *Validator) PauseChallenger() error {
+	if v.challengerPaused.Swap(true) {
+		return errors.New("challenger is already paused")
+	}
+	v.l.Info("challenger paused")
+	return nil
+}
+
+func (v *Validator) ResumeChallenger() error {
+	if !v.challengerPaused.Swap(false) {
+		return errors.New("challenger is not paused")
+	}
+	v.l.Info("challenger resumed")
+	v.challenger.RequireUpdate()
+	return nil
+}
+
+// Rescan verifies the outputs again from the given output index, without reusing the stored results.
+func (v *Validator) Rescan(outputIndex *big.Int) error {
+	return v.challenger.Rescan(outputIndex)
+}
+
+// ChallengerTimeout sends a challengerTimeout transaction for the given challenge, and returns its hash.
+// The hash is empty in watch-only mode.
+func (v *Validator) ChallengerTimeout(ctx context.Context, challengeId *big.Int) (common.Hash, error) {
+	v.txMu.Lock()
+	defer v.txMu.Unlock()
+
+	tx, err := v.challenger.ChallengerTimeout(challengeId)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create challenger timeout transaction: %w", err)
+	}
+
+	receipt, err := v.sendTransaction(ctx, tx)
+	if err != nil || receipt == nil {
+		return common.Hash{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt.TxHash, errors.New("challenger timeout transaction reverted")
+	}
+	return receipt.TxHash, nil
+}
+
+// simulateTransaction simulates the unsigned transaction from the watched address, without sending it.
+// The same transaction is simulated again on every poll until the chain changes, so it is only logged once.
+func (v *Validator) simulateTransaction(ctx context.Context, tx *types.Transaction) {
+	method := txMethodName(tx.Data())
+	dataHash := crypto.Keccak256Hash(tx.Data())
+	if v.simulated[method] == dataHash {
+		return
+	}
+	v.simulated[method] = dataHash
+
+	gas, err := v.cfg.L1Client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  v.cfg.From,
+		To:    tx.To(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	})
+	v.metr.RecordSimulatedTx(method, err)
+	if err != nil {
+		v.l.Warn("simulated transaction would fail", "method", method, "from", v.cfg.From, "err", err)
+		return
+	}
+
+	v.l.Info("simulated transaction", "method", method, "from", v.cfg.From, "gas", gas, "data", hexutil.Encode(tx.Data()))
+}
+
+// newTxOpts returns the options to create the transactions, which are sent by the Validator.
+// In watch-only mode, the gas is not estimated, so that the transactions are created even if they would fail.
+func newTxOpts(ctx context.Context, cfg Config) *bind.TransactOpts {
+	opts := utils.NewSimpleTxOpts(ctx, cfg.From, cfg.SignerFn)
+	if cfg.WatchOnly {
+		opts.GasLimit = watchOnlyGasLimit
+	}
+	return opts
+}
+
+// recordTx stores the result of sending the transaction.
+func (v *Validator) recordTx(tx *types.Transaction, receipt *types.Receipt, sendErr error) {
+	record := &store.TxRecord{
+		Hash:   tx.Hash(),
+		Nonce:  hexutil.Uint64(tx.Nonce()),
+		Method: txMethodName(tx.Data()),
+	}
+	if tx.To() != nil {
+		record.To = *tx.To()
+	}
+	if receipt != nil {
+		// The tx manager may have replaced the transaction to bump the gas price.
+		record.Hash = receipt.TxHash
+		record.BlockNumber = hexutil.Uint64(receipt.BlockNumber.Uint64())
+		record.Status = hexutil.Uint64(receipt.Status)
+	}
+	if sendErr != nil {
+		record.Err = sendErr.Error()
+	}
+	v.metr.RecordTxSent(record.Method, receipt, sendErr)
+
+	if err := v.store.PutTx(record); err != nil {
+		v.l.Error("failed to store transaction", "tx", record.Hash, "err", err)
+	}
+}
+
+// txMethodName returns the name of the L2OutputOracle or Colosseum method called by the tx data.
+func txMethodName(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+
+	for _, metaData := range []*bind.MetaData{bindings.L2OutputOracleMetaData, bindings.ColosseumMetaData} {
+		contractAbi, err := metaData.GetAbi()
+		if err != nil {
+			continue
+		}
+		if method, err := contractAbi.MethodById(data[:4]); err == nil {
+			return method.Name
+		}
+	}
+
+	return ""
+}
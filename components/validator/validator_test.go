@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
+	"github.com/wemixkanvas/kanvas/components/validator/store"
+)
+
+type testSend struct {
+	tx     *types.Transaction
+	result chan error
+}
+
+// testTxMgr hands the sent transactions to the test, which decides their result.
+type testTxMgr struct {
+	sent chan *testSend
+}
+
+func (m *testTxMgr) Send(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	s := &testSend{tx: tx, result: make(chan error, 1)}
+	m.sent <- s
+	select {
+	case err := <-s.result:
+		if err != nil {
+			return nil, err
+		}
+		return &types.Receipt{
+			TxHash:      tx.Hash(),
+			Status:      types.ReceiptStatusSuccessful,
+			BlockHash:   common.Hash{0x01},
+			BlockNumber: big.NewInt(100),
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func nextSend(t *testing.T, txMgr *testTxMgr) *testSend {
+	select {
+	case s := <-txMgr.sent:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a transaction")
+		return nil
+	}
+}
+
+// TestSendTransactionsPipelined checks that the catch-up transactions are all pending at once,
+// instead of each one waiting for the receipt of the previous one.
+func TestSendTransactionsPipelined(t *testing.T) {
+	txMgr := &testTxMgr{sent: make(chan *testSend, 10)}
+	v := &Validator{
+		l:     testlog.Logger(t, log.LvlInfo),
+		store: store.NewMemoryStore(),
+		txMgr: txMgr,
+		metr:  metrics.NoopMetrics,
+	}
+
+	var txs []*types.Transaction
+	for nonce := uint64(5); nonce < 8; nonce++ {
+		txs = append(txs, types.NewTx(&types.DynamicFeeTx{Nonce: nonce}))
+	}
+
+	type result struct {
+		receipts []*types.Receipt
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		receipts, err := v.sendTransactions(context.Background(), txs)
+		done <- result{receipts, err}
+	}()
+
+	// All the transactions are sent before any of them is confirmed.
+	sends := make(map[uint64]*testSend)
+	for range txs {
+		s := nextSend(t, txMgr)
+		sends[s.tx.Nonce()] = s
+	}
+	require.Len(t, sends, len(txs))
+
+	// The receipts of the confirmed transactions are returned, even if a later one failed.
+	sends[5].result <- nil
+	sends[6].result <- nil
+	sends[7].result <- errors.New("failed")
+	res := <-done
+	require.Error(t, res.err)
+	require.Equal(t, txs[0].Hash(), res.receipts[0].TxHash)
+	require.Equal(t, txs[1].Hash(), res.receipts[1].TxHash)
+	require.Nil(t, res.receipts[2])
+}
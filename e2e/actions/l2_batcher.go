@@ -16,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wemixkanvas/kanvas/components/node/da"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/rollup"
 	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
@@ -43,6 +44,9 @@ type BatcherCfg struct {
 	BatcherKey *ecdsa.PrivateKey
 
 	GarbageCfg *GarbageChannelCfg
+
+	// DAClient stores the batch data in the DA server once the external DA fork is active.
+	DAClient *da.Client
 }
 
 // L2Batcher buffers and submits L2 batches to L1.
@@ -189,13 +193,20 @@ func (s *L2Batcher) ActL2BatchSubmit(t Testing, txOpts ...func(tx *types.Dynamic
 	require.NoError(t, err, "need l1 pending header for gas price estimation")
 	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(pendingHeader.BaseFee, big.NewInt(2)))
 
+	txData := data.Bytes()
+	if s.l2BatcherCfg.DAClient != nil && s.rollupCfg.IsExternalDAFork(pendingHeader.Time) {
+		comm, err := s.l2BatcherCfg.DAClient.SetInput(t.Ctx(), txData)
+		require.NoError(t, err, "need to store data in DA server")
+		txData = comm.TxData()
+	}
+
 	rawTx := &types.DynamicFeeTx{
 		ChainID:   s.rollupCfg.L1ChainID,
 		Nonce:     nonce,
 		To:        &s.rollupCfg.BatchInboxAddress,
 		GasTipCap: gasTipCap,
 		GasFeeCap: gasFeeCap,
-		Data:      data.Bytes(),
+		Data:      txData,
 	}
 	for _, opt := range txOpts {
 		opt(rawTx)
@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wemixkanvas/kanvas/components/node/da"
 	"github.com/wemixkanvas/kanvas/components/node/eth"
 	"github.com/wemixkanvas/kanvas/components/node/rollup/derive"
 	"github.com/wemixkanvas/kanvas/components/node/testlog"
@@ -95,6 +96,60 @@ func TestBatcher(gt *testing.T) {
 	require.NotNil(t, vTx)
 }
 
+// TestBatcherExternalDA tests that the batch data stored in the DA server by the batcher
+// is resolved from the DA commitment posted to L1 by the syncer.
+func TestBatcherExternalDA(gt *testing.T) {
+	t := NewDefaultTesting(gt)
+	dp := e2eutils.MakeDeployParams(t, defaultRollupTestParams)
+	sd := e2eutils.Setup(t, dp, defaultAlloc)
+	sd.RollupCfg.ExternalDAForkTime = new(uint64)
+	log := testlog.Logger(t, log.LvlDebug)
+
+	store, err := da.NewFileStore(gt.TempDir())
+	require.NoError(t, err)
+	daServer := da.NewServer(log, "127.0.0.1", 0, store)
+	require.NoError(t, daServer.Start())
+	gt.Cleanup(func() { _ = daServer.Stop() })
+	daClient := da.NewClient("http://" + daServer.Endpoint())
+
+	miner, propEngine, proposer := setupProposerTest(t, sd, log)
+	syncEngine := NewL2Engine(t, log, sd.L2Cfg, sd.RollupCfg.Genesis.L1, e2eutils.WriteDefaultJWT(t))
+	syncer := NewL2Syncer(t, log, miner.L1Client(t, sd.RollupCfg), daClient, syncEngine.EngineClient(t, sd.RollupCfg), sd.RollupCfg)
+
+	batcher := NewL2Batcher(log, sd.RollupCfg, &BatcherCfg{
+		MinL1TxSize: 0,
+		MaxL1TxSize: 128_000,
+		BatcherKey:  dp.Secrets.Batcher,
+		DAClient:    daClient,
+	}, proposer.RollupClient(), miner.EthClient(), propEngine.EthClient())
+
+	proposer.ActL2PipelineFull(t)
+	syncer.ActL2PipelineFull(t)
+
+	// Make L2 blocks up to the L1 head, and submit them through the DA server
+	miner.ActEmptyBlock(t)
+	proposer.ActL1HeadSignal(t)
+	proposer.ActBuildToL1Head(t)
+	batcher.ActSubmitAll(t)
+	miner.ActL1StartBlock(12)(t)
+	miner.ActL1IncludeTx(dp.Addresses.Batcher)(t)
+	miner.ActL1EndBlock(t)
+
+	// Only the commitment of the batch data is posted to L1
+	txs := miner.l1Chain.CurrentBlock().Transactions()
+	require.Len(t, txs, 1)
+	require.True(t, derive.IsDACommitmentData(txs[0].Data()))
+	comm, err := derive.DecodeDACommitment(txs[0].Data()[1:])
+	require.NoError(t, err)
+	_, err = daClient.GetInput(t.Ctx(), comm)
+	require.NoError(t, err)
+
+	// The syncer derives the L2 blocks from the data resolved from the DA server
+	syncer.ActL1HeadSignal(t)
+	syncer.ActL2PipelineFull(t)
+	require.Equal(t, syncer.L2Safe(), proposer.L2Unsafe(), "syncer syncs from proposer via the DA server")
+}
+
 func TestL2Finalization(gt *testing.T) {
 	t := NewDefaultTesting(gt)
 	dp := e2eutils.MakeDeployParams(t, defaultRollupTestParams)
@@ -15,11 +15,15 @@ import (
 	"github.com/wemixkanvas/kanvas/components/node/sources"
 	validator "github.com/wemixkanvas/kanvas/components/validator"
 	chal "github.com/wemixkanvas/kanvas/components/validator/challenge"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
 	"github.com/wemixkanvas/kanvas/e2e/e2eutils"
 	kcrypto "github.com/wemixkanvas/kanvas/utils/service/crypto"
 	"github.com/wemixkanvas/kanvas/utils/service/txmgr"
 )
 
+// proofTimeout is how long ActProveFault waits for the proof of the faulty block.
+const proofTimeout = 30 * time.Second
+
 type L2Challenger struct {
 	log        log.Logger
 	l1         *ethclient.Client
@@ -59,7 +63,7 @@ func NewL2Challenger(t Testing, log log.Logger, cfg *ValidatorCfg, l1 *ethclient
 		ProofFetcher:      e2eutils.NewFetcher(log),
 	}
 
-	challenger, err := validator.NewChallenger(t.Ctx(), validatorCfg, log)
+	challenger, err := validator.NewChallenger(t.Ctx(), validatorCfg, log, metrics.NoopMetrics)
 	require.NoError(t, err)
 
 	return &L2Challenger{
@@ -136,8 +140,13 @@ func (c *L2Challenger) ActProveFault(t Testing) common.Hash {
 	require.NoError(t, err)
 	require.Equal(t, status, chal.StatusProveReady)
 
-	tx, err := c.challenger.ProveFault()
-	require.NoError(t, err, "unable to create proveFault tx")
+	// The proof is generated in the background, as the validator does.
+	var tx *types.Transaction
+	for deadline := time.Now().Add(proofTimeout); tx == nil; time.Sleep(100 * time.Millisecond) {
+		require.True(t, time.Now().Before(deadline), "proof is not generated in time")
+		tx, err = c.challenger.ProveFaultIfReady()
+		require.NoError(t, err, "unable to create proveFault tx")
+	}
 
 	err = c.l1.SendTransaction(t.Ctx(), tx)
 	require.NoError(t, err)
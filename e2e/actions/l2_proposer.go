@@ -40,7 +40,7 @@ type L2Proposer struct {
 }
 
 func NewL2Proposer(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cfg *rollup.Config, propConfDepth uint64) *L2Proposer {
-	syncer := NewL2Syncer(t, log, l1, eng, cfg)
+	syncer := NewL2Syncer(t, log, l1, nil, eng, cfg)
 	attrBuilder := derive.NewFetchingAttributesBuilder(cfg, l1, eng)
 	propConfDepthL1 := driver.NewConfDepth(propConfDepth, syncer.l1State.L1Head, l1)
 	l1OriginSelector := &MockL1OriginSelector{
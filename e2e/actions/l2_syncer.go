@@ -55,9 +55,9 @@ type L2API interface {
 	GetProof(ctx context.Context, address common.Address, storage []common.Hash, blockTag string) (*eth.AccountResult, error)
 }
 
-func NewL2Syncer(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cfg *rollup.Config) *L2Syncer {
+func NewL2Syncer(t Testing, log log.Logger, l1 derive.L1Fetcher, daClient derive.DAClient, eng L2API, cfg *rollup.Config) *L2Syncer {
 	metrics := &testutils.TestDerivationMetrics{}
-	pipeline := derive.NewDerivationPipeline(log, cfg, l1, eng, metrics)
+	pipeline := derive.NewDerivationPipeline(log, cfg, l1, daClient, eng, metrics)
 	pipeline.Reset()
 
 	rollupNode := &L2Syncer{
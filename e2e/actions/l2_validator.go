@@ -15,6 +15,7 @@ import (
 
 	"github.com/wemixkanvas/kanvas/components/node/sources"
 	validator "github.com/wemixkanvas/kanvas/components/validator"
+	"github.com/wemixkanvas/kanvas/components/validator/metrics"
 	kcrypto "github.com/wemixkanvas/kanvas/utils/service/crypto"
 	"github.com/wemixkanvas/kanvas/utils/service/txmgr"
 )
@@ -64,7 +65,7 @@ func NewL2Validator(t Testing, log log.Logger, cfg *ValidatorCfg, l1 *ethclient.
 		SignerFn:          signer(chainID),
 	}
 
-	l2os, err := validator.NewL2OutputSubmitter(t.Ctx(), validatorCfg, log)
+	l2os, err := validator.NewL2OutputSubmitter(t.Ctx(), validatorCfg, log, metrics.NoopMetrics)
 	require.NoError(t, err)
 
 	return &L2Validator{
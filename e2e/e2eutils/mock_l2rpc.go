@@ -32,26 +32,44 @@ func (m *MockL2RPC) Close() {
 }
 
 func (m *MockL2RPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
-	if method == "kanvas_outputAtBlock" {
+	switch method {
+	case "kanvas_outputAtBlock":
 		blockNumber := args[0].(hexutil.Uint64)
 
 		m.rpc.CallContext(ctx, &result, "kanvas_outputAtBlock", blockNumber)
 
 		if m.segStart == nil || uint64(blockNumber) != m.segStart.Uint64() {
-			rng := rand.New(rand.NewSource(int64(blockNumber)))
+			m.fakeOutput(*result.(**eth.OutputResponse), uint64(blockNumber))
+			return nil
+		}
+	case "kanvas_outputsAtBlocks":
+		blockNumbers := args[0].([]hexutil.Uint64)
 
-			s := result.(**eth.OutputResponse)
-			(*s).OutputRoot = eth.Bytes32(testutils.RandomHash(rng))
-			(*s).WithdrawalStorageRoot = testutils.RandomHash(rng)
-			(*s).StateRoot = testutils.RandomHash(rng)
+		if err := m.rpc.CallContext(ctx, result, "kanvas_outputsAtBlocks", blockNumbers); err != nil {
+			return err
+		}
 
-			return nil
+		outputs := *result.(*[]*eth.OutputResponse)
+		for i, blockNumber := range blockNumbers {
+			if m.segStart == nil || uint64(blockNumber) != m.segStart.Uint64() {
+				m.fakeOutput(outputs[i], uint64(blockNumber))
+			}
 		}
+		return nil
 	}
 
 	return m.rpc.CallContext(ctx, result, method, args...)
 }
 
+// fakeOutput replaces the output with a random one, which is the same for the same block.
+func (m *MockL2RPC) fakeOutput(output *eth.OutputResponse, blockNumber uint64) {
+	rng := rand.New(rand.NewSource(int64(blockNumber)))
+
+	output.OutputRoot = eth.Bytes32(testutils.RandomHash(rng))
+	output.WithdrawalStorageRoot = testutils.RandomHash(rng)
+	output.StateRoot = testutils.RandomHash(rng)
+}
+
 func (m *MockL2RPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
 	return m.rpc.BatchCallContext(ctx, b)
 }
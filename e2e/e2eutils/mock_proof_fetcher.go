@@ -32,11 +32,11 @@ func read(path string) ([]byte, error) {
 	return data, nil
 }
 
-func (f *Fetcher) FetchProofAndPair(blockRef eth.L2BlockRef) (*chal.ProofAndPair, error) {
+func (f *Fetcher) FetchProofAndPair(ctx context.Context, blockRef eth.L2BlockRef) (*chal.ProofAndPair, error) {
 	decoded := make([][]*big.Int, 2)
 	files := []string{"verify_circuit_proof.data", "verify_circuit_final_pair.data"}
 
-	g, _ := errgroup.WithContext(context.Background())
+	g, _ := errgroup.WithContext(ctx)
 
 	for i := 0; i < len(files); i++ {
 		filePath := fmt.Sprintf("../testdata/proof/%s", files[i])
@@ -0,0 +1,44 @@
+package e2eutils
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wemixkanvas/kanvas/bindings/bindings"
+	"github.com/wemixkanvas/kanvas/components/node/eth"
+	"github.com/wemixkanvas/kanvas/components/node/testlog"
+	"github.com/wemixkanvas/kanvas/utils/chain-ops/deployer"
+)
+
+// TestMockProofVerified checks that the proof of the mock fetcher is accepted by ZKVerifier,
+// as the challenge tests prove the faults with it.
+func TestMockProofVerified(t *testing.T) {
+	backend := deployer.NewBackend(false)
+	t.Cleanup(func() { _ = backend.Close() })
+	opts, err := bind.NewKeyedTransactorWithChainID(deployer.TestKey, deployer.ChainID)
+	require.NoError(t, err)
+	opts.GasLimit = 15_000_000
+
+	_, _, verifier, err := bindings.DeployZKVerifier(opts, backend)
+	require.NoError(t, err)
+	backend.Commit()
+
+	proof, err := NewFetcher(testlog.Logger(t, log.LvlInfo)).FetchProofAndPair(context.Background(), eth.L2BlockRef{})
+	require.NoError(t, err)
+	require.NoError(t, proof.Check())
+
+	ok, err := verifier.Verify(&bind.CallOpts{}, proof.Proof, proof.Pair)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// A tampered proof is not accepted.
+	proof.Pair[0] = new(big.Int).Add(proof.Pair[0], common.Big1)
+	ok, err = verifier.Verify(&bind.CallOpts{}, proof.Proof, proof.Pair)
+	require.False(t, err == nil && ok, "tampered proof must be rejected")
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/wemixkanvas/kanvas/components/node/sources"
 	"github.com/wemixkanvas/kanvas/components/node/testlog"
 	validator "github.com/wemixkanvas/kanvas/components/validator"
+	validatormetrics "github.com/wemixkanvas/kanvas/components/validator/metrics"
 	"github.com/wemixkanvas/kanvas/e2e/e2eutils"
 	"github.com/wemixkanvas/kanvas/utils/chain-ops/genesis"
 	klog "github.com/wemixkanvas/kanvas/utils/service/log"
@@ -573,17 +574,18 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 			Level:  "info",
 			Format: "text",
 		},
-		PrivateKey: hexPriv(cfg.Secrets.Validator),
-		ProverGrpc: "http://0.0.0.0:0",
+		PrivateKey:     hexPriv(cfg.Secrets.Validator),
+		ProverGrpc:     "http://0.0.0.0:0",
+		ProverStrategy: "primary",
 	}
 
-	validatorCfg, err := validator.NewValidatorConfig(validatorCliCfg, sys.cfg.Loggers["validator"])
+	validatorCfg, err := validator.NewValidatorConfig(validatorCliCfg, sys.cfg.Loggers["validator"], validatormetrics.NoopMetrics)
 	if err != nil {
 		return nil, fmt.Errorf("unable to init validator config: %w", err)
 	}
 	// replace to mock fetcher
 	validatorCfg.ProofFetcher = e2eutils.NewFetcher(sys.cfg.Loggers["validator"])
-	sys.Validator, err = validator.NewValidator(context.Background(), *validatorCfg, sys.cfg.Loggers["validator"])
+	sys.Validator, err = validator.NewValidator(context.Background(), *validatorCfg, sys.cfg.Loggers["validator"], validatormetrics.NoopMetrics)
 	if err != nil {
 		return nil, fmt.Errorf("unable to setup validator: %w", err)
 	}
@@ -605,6 +607,8 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		NumConfirmations:          1,
 		ResubmissionTimeout:       5 * time.Second,
 		SafeAbortNonceTooLowCount: 3,
+		MaxPendingTxs:             4,
+		CompressionAlgo:           "zlib",
 		LogConfig: klog.CLIConfig{
 			Level:  "info",
 			Format: "text",
@@ -621,7 +625,9 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		return nil, fmt.Errorf("failed to setup batcher: %w", err)
 	}
 
-	sys.Batcher.Start()
+	if err := sys.Batcher.Start(); err != nil {
+		return nil, fmt.Errorf("unable to start batcher: %w", err)
+	}
 
 	return sys, nil
 }
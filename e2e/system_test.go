@@ -1459,7 +1459,8 @@ func TestStopStartBatcher(t *testing.T) {
 	require.Greater(t, newSeqStatus.SafeL2.Number, propStatus.SafeL2.Number, "Safe chain did not advance")
 
 	// stop the batch submission
-	sys.Batcher.Stop()
+	err = sys.Batcher.Stop()
+	require.Nil(t, err)
 
 	// wait for any old safe blocks being submitted / derived
 	time.Sleep(safeBlockInclusionDuration)
@@ -1478,7 +1479,8 @@ func TestStopStartBatcher(t *testing.T) {
 	require.Equal(t, newSeqStatus.SafeL2.Number, propStatus.SafeL2.Number, "Safe chain advanced while batcher was stopped")
 
 	// start the batch submission
-	sys.Batcher.Start()
+	err = sys.Batcher.Start()
+	require.Nil(t, err)
 	time.Sleep(safeBlockInclusionDuration)
 
 	// send a third tx
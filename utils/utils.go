@@ -34,6 +34,16 @@ func DialEthClientWithTimeout(ctx context.Context, url string) (*ethclient.Clien
 	return ethclient.DialContext(ctx, url)
 }
 
+// DialRPCClientWithTimeout attempts to dial the RPC provider using the provided
+// URL. If the dial doesn't complete within defaultDialTimeout seconds, this
+// method will return an error.
+func DialRPCClientWithTimeout(ctx context.Context, url string) (*rpc.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultDialTimeout)
+	defer cancel()
+
+	return rpc.DialContext(ctx, url)
+}
+
 // DialRollupClientWithTimeout attempts to dial the RPC provider using the provided
 // URL. If the dial doesn't complete within defaultDialTimeout seconds, this
 // method will return an error.